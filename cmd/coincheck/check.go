@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
+)
+
+// chainSummary is the per chain outcome of a coincheck run
+type chainSummary struct {
+	Chain      common.Chain `json:"chain"`
+	Checked    int          `json:"checked"`
+	Matched    int          `json:"matched"`
+	Mismatched int          `json:"mismatched"`
+	Unresolved int          `json:"unresolved"` // neither the token registry nor the discovery service knows the coin
+	Unpriced   int          `json:"unpriced"`   // coins stored without CMC id
+	Fixed      int          `json:"fixed"`
+}
+
+// resolvedToken is the reference metadata of a (chain, contract, ticker) tuple, cached as many vaults hold the same tokens
+type resolvedToken struct {
+	coin models.CoinBase
+	err  error
+}
+
+type coinChecker struct {
+	registry          *tokens.Registry
+	discoveryServices map[common.Chain]tokens.AutoDiscoveryService
+	resolved          map[string]resolvedToken
+	summaries         map[common.Chain]*chainSummary
+	fixes             []models.CoinDBModel
+}
+
+func newCoinChecker(registry *tokens.Registry, discoveryServices map[common.Chain]tokens.AutoDiscoveryService) *coinChecker {
+	return &coinChecker{
+		registry:          registry,
+		discoveryServices: discoveryServices,
+		resolved:          make(map[string]resolvedToken),
+		summaries:         make(map[common.Chain]*chainSummary),
+	}
+}
+
+// check compares the decimals, CMC id and logo of coin with the token registry, falling back to the discovery
+// service of its chain, and records the correction of a mismatched coin
+func (c *coinChecker) check(coin models.CoinDBModel) {
+	summary := c.summary(coin.Chain)
+	summary.Checked++
+	if coin.CMCId == 0 {
+		summary.Unpriced++
+	}
+	expected, err := c.resolve(coin)
+	if err != nil {
+		summary.Unresolved++
+		logrus.Warnf("Unresolved coin - CMCId: %d, Decimals: %d, Chain: %s, Address: %s: %v",
+			coin.CMCId, coin.Decimals, coin.Chain, coin.ContractAddress, err)
+		return
+	}
+	logoMissing := coin.Logo == "" && expected.Logo != ""
+	if expected.CMCId == coin.CMCId && expected.Decimals == coin.Decimals && !logoMissing {
+		summary.Matched++
+		logrus.Debugf("Coin data matches - CMCId: %d, Decimals: %d for %s on %s",
+			coin.CMCId, coin.Decimals, coin.ContractAddress, coin.Chain)
+		return
+	}
+	summary.Mismatched++
+	logrus.Warnf("Coin data mismatch - Stored(CMCId: %d, Decimals: %d) vs Expected(CMCId: %d, Decimals: %d) for contract address: %s on %s",
+		coin.CMCId, coin.Decimals, expected.CMCId, expected.Decimals, coin.ContractAddress, coin.Chain)
+	// never overwrite the stored metadata with an incomplete reference
+	if !tokens.IsCompleteToken(expected) {
+		return
+	}
+	fixed := coin
+	fixed.Decimals = expected.Decimals
+	fixed.CMCId = expected.CMCId
+	if expected.Logo != "" {
+		fixed.Logo = expected.Logo
+	}
+	c.fixes = append(c.fixes, fixed)
+}
+
+func (c *coinChecker) resolve(coin models.CoinDBModel) (models.CoinBase, error) {
+	// coins without contract are told apart by ticker
+	key := fmt.Sprintf("%s:%s:%s", coin.Chain, coin.ContractAddress, strings.ToLower(coin.Ticker))
+	if resolved, ok := c.resolved[key]; ok {
+		return resolved.coin, resolved.err
+	}
+	coinBase := models.CoinBase{
+		Chain:           coin.Chain,
+		Ticker:          coin.Ticker,
+		Address:         coin.Address,
+		ContractAddress: coin.ContractAddress,
+	}
+	expected, err := c.registry.Search(coinBase)
+	if err != nil {
+		discoveryService, exists := c.discoveryServices[coin.Chain]
+		if !exists {
+			err = fmt.Errorf("no discovery service found for chain: %s", coin.Chain)
+		} else {
+			expected, err = discoveryService.Search(coinBase)
+		}
+	}
+	c.resolved[key] = resolvedToken{coin: expected, err: err}
+	return expected, err
+}
+
+// coinStore is the part of the storage coincheck reads coins from and writes the corrections to
+type coinStore interface {
+	GetCoinsWithPage(startId, limit uint64) ([]models.CoinDBModel, error)
+	FixCoinsMetadata(coins []models.CoinDBModel) error
+}
+
+// run checks every stored coin in batches and, with fix, corrects the mismatched coins in a single transaction
+func (c *coinChecker) run(store coinStore, fix bool) error {
+	const batchSize = 1000
+	var currentID uint64
+	for {
+		coins, err := store.GetCoinsWithPage(currentID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch coins: %w", err)
+		}
+		if len(coins) == 0 {
+			logrus.Infof("No more coins to process")
+			break
+		}
+		currentID = uint64(coins[len(coins)-1].ID)
+		for _, coin := range coins {
+			c.check(coin)
+		}
+	}
+
+	if !fix {
+		if len(c.fixes) > 0 {
+			logrus.Infof("%d coins can be fixed, run with --fix to correct them", len(c.fixes))
+		}
+		return nil
+	}
+	if err := store.FixCoinsMetadata(c.fixes); err != nil {
+		return fmt.Errorf("failed to fix coins: %w", err)
+	}
+	c.markFixed()
+	logrus.Infof("Fixed %d coins", len(c.fixes))
+	return nil
+}
+
+// markFixed counts the recorded corrections as applied
+func (c *coinChecker) markFixed() {
+	for _, coin := range c.fixes {
+		c.summary(coin.Chain).Fixed++
+	}
+}
+
+func (c *coinChecker) summary(chain common.Chain) *chainSummary {
+	summary, ok := c.summaries[chain]
+	if !ok {
+		summary = &chainSummary{Chain: chain}
+		c.summaries[chain] = summary
+	}
+	return summary
+}
+
+// chainSummaries returns the summaries sorted by chain
+func (c *coinChecker) chainSummaries() []chainSummary {
+	res := make([]chainSummary, 0, len(c.summaries))
+	for _, summary := range c.summaries {
+		res = append(res, *summary)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Chain.String() < res[j].Chain.String()
+	})
+	return res
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
+)
+
+type mockCoinStore struct {
+	coins []models.CoinDBModel
+	fixed [][]models.CoinDBModel
+}
+
+func (m *mockCoinStore) GetCoinsWithPage(startId, limit uint64) ([]models.CoinDBModel, error) {
+	res := make([]models.CoinDBModel, 0)
+	for _, coin := range m.coins {
+		if uint64(coin.ID) > startId && uint64(len(res)) < limit {
+			res = append(res, coin)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockCoinStore) FixCoinsMetadata(coins []models.CoinDBModel) error {
+	m.fixed = append(m.fixed, coins)
+	return nil
+}
+
+func newTestCoin(id uint, ticker string, cmcID, decimals int) models.CoinDBModel {
+	return models.CoinDBModel{
+		Model: gorm.Model{ID: id},
+		CoinBase: models.CoinBase{
+			Chain:    common.THORChain,
+			Ticker:   ticker,
+			Decimals: decimals,
+			CMCId:    cmcID,
+			IsNative: true,
+		},
+	}
+}
+
+func newTestChecker() *coinChecker {
+	registry := tokens.NewRegistry([]models.Token{
+		{Chain: common.THORChain, Ticker: "RUNE", Decimals: 8, CMCId: 4157, Type: models.TokenTypeFungible},
+		{Chain: common.THORChain, Ticker: "TCY", Decimals: 8, CMCId: 33770, Type: models.TokenTypeFungible},
+	})
+	return newCoinChecker(registry, nil)
+}
+
+func TestCoinCheckerRun(t *testing.T) {
+	store := &mockCoinStore{coins: []models.CoinDBModel{
+		newTestCoin(1, "RUNE", 4157, 8),
+		// coins without contract of the same chain are resolved by ticker
+		newTestCoin(2, "TCY", 0, 6),
+		newTestCoin(3, "RUNE", 4157, 6),
+		newTestCoin(4, "RUJI", 0, 8),
+	}}
+
+	checker := newTestChecker()
+	assert.NoError(t, checker.run(store, false))
+	assert.Empty(t, store.fixed)
+	assert.Len(t, checker.fixes, 2)
+	summaries := checker.chainSummaries()
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, chainSummary{Chain: common.THORChain, Checked: 4, Matched: 1, Mismatched: 2, Unresolved: 1, Unpriced: 2}, summaries[0])
+
+	checker = newTestChecker()
+	assert.NoError(t, checker.run(store, true))
+	assert.Len(t, store.fixed, 1)
+	fixed := store.fixed[0]
+	assert.Len(t, fixed, 2)
+	assert.Equal(t, uint(2), fixed[0].ID)
+	assert.Equal(t, 33770, fixed[0].CMCId)
+	assert.Equal(t, 8, fixed[0].Decimals)
+	assert.Equal(t, uint(3), fixed[1].ID)
+	assert.Equal(t, 4157, fixed[1].CMCId)
+	assert.Equal(t, 8, fixed[1].Decimals)
+	assert.Equal(t, 2, checker.chainSummaries()[0].Fixed)
+}
@@ -2,22 +2,29 @@ package main
 
 import (
 	_ "embed"
+	"flag"
+	"os"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/vultisig/airdrop-registry/config"
-	"github.com/vultisig/airdrop-registry/internal/common"
-	"github.com/vultisig/airdrop-registry/internal/models"
 	"github.com/vultisig/airdrop-registry/internal/services"
 	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 func main() {
+	fix := flag.Bool("fix", false, "correct the decimals, CMC id and logo of mismatched coins in a single transaction")
+	reportFormat := flag.String("report", "", "write a per chain summary to stdout, json or csv")
+	flag.Parse()
+	if *reportFormat != "" && *reportFormat != reportJSON && *reportFormat != reportCSV {
+		logrus.Fatalf("Unsupported report format: %s", *reportFormat)
+	}
+
 	logrus.SetFormatter(&logrus.TextFormatter{
-		ForceColors:      true,
-		FullTimestamp:    true,
-		DisableColors:    false,
-		DisableTimestamp: false,
+		ForceColors:            true,
+		FullTimestamp:          true,
+		DisableColors:          false,
+		DisableTimestamp:       false,
 		DisableLevelTruncation: true,
 	})
 
@@ -45,76 +52,23 @@ func main() {
 		logrus.WithError(err).Fatalf("Failed to initialize OneInch service")
 	}
 
-	discoveryServices := map[common.Chain]tokens.AutoDiscoveryService{
-		common.Tron:   tokens.NewTRC20DiscoveryService(common.Tron, cmcService),
-		common.Solana: tokens.NewSPLDiscoveryService(cmcService),
-	}
-	for _, chain := range common.EVMChains {
-		if !oneInchService.IsChainSupported(chain) { 
-			continue
-		}
-		err := oneInchService.LoadOneInchTokens(chain)
-		if err != nil {
-			logrus.WithError(err).Fatalf("Failed to load oneInch service")
-		}
-		discoveryServices[chain] = tokens.NewERC20DiscoveryService(oneInchService, cmcService)
+	discoveryServices, err := tokens.NewDiscoveryServices(cmcService, oneInchService)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Failed to load oneInch service")
 	}
 
-	predefinedService := tokens.NewPredefinedTokenService()
-	const batchSize = 1000
-	var currentID uint64
-
-	for {
-		coins, err := storage.GetCoinsWithPage(currentID, batchSize)
-		if err != nil {
-			logrus.WithError(err).Errorf("Failed to fetch coins")
-		}
-		if len(coins) == 0 {
-			logrus.Infof("No more coins to process")
-			break
-		}
-		currentID = uint64(coins[len(coins)-1].ID)
-
-		for _, coin := range coins {
-			if coin.CMCId == 0 || coin.Decimals == 0 {
-				logrus.Warnf("Invalid coin data - CMCId: %d, Decimals: %d, Chain: %s, Address: %s",
-					coin.CMCId, coin.Decimals, coin.Chain, coin.ContractAddress)
-				continue
-			}
-
-			coinBase := models.CoinBase{
-				Chain:           coin.Chain,
-				Address:         coin.Address,
-				ContractAddress: coin.ContractAddress,
-			}
+	tokenRegistry := tokens.NewRegistry(nil)
+	if err := storage.LoadTokenRegistry(tokenRegistry); err != nil {
+		logrus.WithError(err).Fatalf("Failed to load token registry")
+	}
+	checker := newCoinChecker(tokenRegistry, discoveryServices)
+	if err := checker.run(storage, *fix); err != nil {
+		logrus.WithError(err).Fatalf("Failed to check coins")
+	}
 
-			predefinedCoin, err := predefinedService.Search(coinBase)
-			if err == nil {
-				if predefinedCoin.CMCId != coin.CMCId || predefinedCoin.Decimals != coin.Decimals {
-					logrus.Warnf("Coin data mismatch - System(CMCId: %d, Decimals: %d) vs User(CMCId: %d, Decimals: %d) for contract address: %s on %s",
-						coin.CMCId, coin.Decimals, predefinedCoin.CMCId, predefinedCoin.Decimals, coin.ContractAddress, coin.Chain)
-				} else {
-					logrus.Infof("Coin data matches in predefined tokens - CMCId: %d, Decimals: %d for %s on %s",
-						coin.CMCId, coin.Decimals, coin.ContractAddress, coin.Chain)
-				}
-			} else {
-				discoveryService, exists := discoveryServices[coin.Chain]
-				if !exists {
-					logrus.Warnf("No discovery service found for chain: %s", coin.Chain)
-					continue
-				}
-				coinData, err := discoveryService.Search(coinBase)
-				if err != nil {
-					logrus.Errorf("Error searching contract address %s on chain %s: %v", coin.ContractAddress, coin.Chain, err)
-				}
-				if coinData.CMCId == coin.CMCId && coinData.Decimals == coin.Decimals {
-					logrus.Infof("Coin data matches - CMCId: %d, Decimals: %d for %s on %s",
-						coin.CMCId, coin.Decimals, coin.ContractAddress, coin.Chain)
-				} else {
-					logrus.Warnf("Coin data mismatch - System(CMCId: %d, Decimals: %d) vs User(CMCId: %d, Decimals: %d) for contract address: %s on %s",
-						coin.CMCId, coin.Decimals, coinData.CMCId, coinData.Decimals, coin.ContractAddress, coin.Chain)
-				}
-			}
+	if *reportFormat != "" {
+		if err := writeReport(os.Stdout, *reportFormat, checker.chainSummaries()); err != nil {
+			logrus.WithError(err).Fatalf("Failed to write report")
 		}
 	}
 }
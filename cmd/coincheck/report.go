@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Supported report formats
+const (
+	reportJSON = "json"
+	reportCSV  = "csv"
+)
+
+// writeReport writes the per chain summaries in the given format
+func writeReport(w io.Writer, format string, summaries []chainSummary) error {
+	switch format {
+	case reportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summaries)
+	case reportCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"chain", "checked", "matched", "mismatched", "unresolved", "unpriced", "fixed"}); err != nil {
+			return err
+		}
+		for _, s := range summaries {
+			if err := writer.Write([]string{
+				s.Chain.String(),
+				strconv.Itoa(s.Checked),
+				strconv.Itoa(s.Matched),
+				strconv.Itoa(s.Mismatched),
+				strconv.Itoa(s.Unresolved),
+				strconv.Itoa(s.Unpriced),
+				strconv.Itoa(s.Fixed),
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
+	"github.com/vultisig/airdrop-registry/internal/services"
+)
+
+const usage = `usage: season close --season=N [--force] [--batch=1000]
+
+close   freeze the final ranks of season N, commit the stats of its vaults and move them to the next season`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "close" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	closeCmd := flag.NewFlagSet("close", flag.ExitOnError)
+	seasonID := closeCmd.Uint("season", 0, "id of the season to close")
+	force := closeCmd.Bool("force", false, "close the season before its configured end")
+	batchSize := closeCmd.Int("batch", 1000, "number of vaults committed per transaction")
+	if err := closeCmd.Parse(os.Args[2:]); err != nil {
+		logrus.WithError(err).Fatalf("Failed to parse flags")
+	}
+	seasonSet := false
+	closeCmd.Visit(func(f *flag.Flag) {
+		seasonSet = seasonSet || f.Name == "season"
+	})
+	if !seasonSet {
+		logrus.Fatalf("--season is required")
+	}
+	if *batchSize <= 0 {
+		logrus.Fatalf("--batch must be positive")
+	}
+
+	logrus.SetFormatter(&logrus.TextFormatter{
+		ForceColors:            true,
+		FullTimestamp:          true,
+		DisableColors:          false,
+		DisableTimestamp:       false,
+		DisableLevelTruncation: true,
+	})
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logrus.WithError(err).Fatalf("Failed to load config")
+	}
+
+	storage, err := services.NewStorage(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Failed to initialize storage")
+	}
+	defer func() {
+		if err := storage.Close(); err != nil {
+			logrus.WithError(err).Errorf("Failed to close storage")
+		}
+	}()
+
+	seasonRegistry := seasons.NewRegistry(nil)
+	if err := storage.LoadSeasonRegistry(seasonRegistry, cfg.Seasons); err != nil {
+		logrus.WithError(err).Fatalf("Failed to load seasons")
+	}
+	season, ok := seasonRegistry.Get(*seasonID)
+	if !ok {
+		logrus.Fatalf("Season %d is not configured", *seasonID)
+	}
+	if time.Now().Before(season.End) && !*force {
+		logrus.Fatalf("Season %d ends at %s, use --force to close it earlier", season.ID, season.End.Format(time.RFC3339))
+	}
+	next, ok := seasonRegistry.Next(season.ID)
+	if !ok {
+		logrus.Fatalf("No season is configured after season %d", season.ID)
+	}
+
+	logrus.Infof("Closing season %d into season %d", season.ID, next.ID)
+	closure, err := storage.CloseSeason(season.ID, next.ID, *batchSize)
+	if err != nil {
+		// the closure is resumable, running the command again continues with the remaining vaults
+		logrus.WithError(err).Fatalf("Failed to close season %d", season.ID)
+	}
+	logrus.Infof("Season %d closed: %d vaults, %f points committed", closure.SeasonID, closure.VaultCount, closure.TotalPoints)
+	if time.Now().Before(next.Start) {
+		logrus.Infof("The worker doesn't score until season %d starts at %s", next.ID, next.Start.Format(time.RFC3339))
+	}
+}
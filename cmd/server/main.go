@@ -2,12 +2,17 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/vultisig/airdrop-registry/config"
 	"github.com/vultisig/airdrop-registry/internal/handlers"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
 	"github.com/vultisig/airdrop-registry/internal/services"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
+const discoveryRetryDelay = time.Minute
+
 func main() {
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -23,11 +28,52 @@ func main() {
 			log.Printf("failed to close database: %v", err)
 		}
 	}()
-	api, err := handlers.NewApi(cfg, storage)
+	tokenRegistry := tokens.NewRegistry(nil)
+	if err := storage.LoadTokenRegistry(tokenRegistry); err != nil {
+		panic(err)
+	}
+	seasonRegistry := seasons.NewRegistry(nil)
+	if err := storage.LoadSeasonRegistry(seasonRegistry, cfg.Seasons); err != nil {
+		panic(err)
+	}
+	if err := storage.SeedQuests(cfg); err != nil {
+		panic(err)
+	}
+	api, err := handlers.NewApi(cfg, storage, tokenRegistry, seasonRegistry)
 	if err != nil {
 		panic(err)
 	}
+	// token lists of the discovery services are downloaded from third parties, so they are loaded in the
+	// background and the discovery endpoints return 503 until they are ready
+	go func() {
+		for {
+			discovery, err := newVaultDiscoveryService(tokenRegistry)
+			if err == nil {
+				api.SetDiscovery(discovery)
+				log.Println("token discovery is ready")
+				return
+			}
+			log.Printf("failed to create token discovery, retrying in %s: %v", discoveryRetryDelay, err)
+			time.Sleep(discoveryRetryDelay)
+		}
+	}()
 	if err := api.Start(); err != nil {
 		panic(err)
 	}
 }
+
+func newVaultDiscoveryService(tokenRegistry *tokens.Registry) (*tokens.VaultDiscoveryService, error) {
+	cmcService, err := tokens.NewCMCService()
+	if err != nil {
+		return nil, err
+	}
+	oneInchService, err := tokens.NewOneInchService()
+	if err != nil {
+		return nil, err
+	}
+	discoveryServices, err := tokens.NewDiscoveryServices(cmcService, oneInchService)
+	if err != nil {
+		return nil, err
+	}
+	return tokens.NewVaultDiscoveryService(discoveryServices, tokenRegistry), nil
+}
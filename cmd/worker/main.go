@@ -9,7 +9,9 @@ import (
 
 	"github.com/vultisig/airdrop-registry/config"
 	"github.com/vultisig/airdrop-registry/internal/balance"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
 	"github.com/vultisig/airdrop-registry/internal/services"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 	"github.com/vultisig/airdrop-registry/internal/volume"
 )
 
@@ -27,20 +29,48 @@ func main() {
 			fmt.Println("fail to close storage db: ", err)
 		}
 	}()
-	referralResolver := services.NewReferralResolverService(cfg.ReferralBot.BaseAddress, cfg.ReferralBot.APIKey)
+	// referrals are native to the registry, the referral bot only imports its referrals when configured
+	var referralResolver *services.ReferralResolverService
+	if cfg.ReferralBot.BaseAddress != "" {
+		referralResolver = services.NewReferralResolverService(cfg.ReferralBot.BaseAddress, cfg.ReferralBot.APIKey)
+	}
 	priceResolver, err := services.NewPriceResolver(cfg)
 	if err != nil {
 		panic(err)
 	}
-	balanceResolver, err := balance.NewBalanceResolver()
+	tokenRegistry := tokens.NewRegistry(nil)
+	if err := storage.LoadTokenRegistry(tokenRegistry); err != nil {
+		panic(err)
+	}
+	seasonRegistry := seasons.NewRegistry(nil)
+	if err := storage.LoadSeasonRegistry(seasonRegistry, cfg.Seasons); err != nil {
+		panic(err)
+	}
+	balanceResolver, err := balance.NewBalanceResolver(tokenRegistry)
 	if err != nil {
 		panic(err)
 	}
-	volumeTracker, err := volume.NewVolumeResolver(cfg)
+	volumeTracker, err := volume.NewVolumeResolver(cfg, storage)
 	if err != nil {
 		panic(err)
 	}
-	pointWorker, err := services.NewPointWorker(cfg, storage, priceResolver, balanceResolver, volumeTracker, referralResolver)
+	var tokenDiscovery *tokens.VaultDiscoveryService
+	if cfg.TokenDiscovery.Enabled {
+		cmcService, err := tokens.NewCMCService()
+		if err != nil {
+			panic(err)
+		}
+		oneInchService, err := tokens.NewOneInchService()
+		if err != nil {
+			panic(err)
+		}
+		discoveryServices, err := tokens.NewDiscoveryServices(cmcService, oneInchService)
+		if err != nil {
+			panic(err)
+		}
+		tokenDiscovery = tokens.NewVaultDiscoveryService(discoveryServices, tokenRegistry)
+	}
+	pointWorker, err := services.NewPointWorker(cfg, storage, priceResolver, balanceResolver, volumeTracker, referralResolver, tokenRegistry, seasonRegistry, tokenDiscovery)
 	if err != nil {
 		panic(err)
 	}
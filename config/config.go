@@ -1,9 +1,11 @@
 package config
 
 import (
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,8 @@ type Config struct {
 	Server struct {
 		Host string `mapstructure:"host"`
 		Port int    `mapstructure:"port"`
+		// proxies allowed to set the client ip through X-Forwarded-For, empty trusts none and uses the remote address
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
 	}
 	MySQL struct {
 		Database string `mapstructure:"database"`
@@ -36,15 +40,138 @@ type Config struct {
 		APIKey      string `mapstructure:"api_key"`
 		BaseAddress string `mapstructure:"base_address"`
 	}
-	Seasons           []AirdropSeason `mapstructure:"seasons"`
+	Seasons           []AirdropSeason `mapstructure:"seasons"` // seed of the seasons table, used only while the table is empty
 	VolumeTrackingAPI struct {
 		AffiliateAddress   []string `mapstructure:"affiliate_address"`
 		EtherscanAPIKey    string   `mapstructure:"etherscan_api_key"`
-		EthplorerAPIKey    string   `mapstructure:"ethplorer_api_key"`
 		TCMidgardBaseURL   string   `mapstructure:"tcmidgard_base_url"`
 		TCMidgardXClientID string   `mapstructure:"tcmidgard_xclient_id"`
 		MayaMidgardBaseURL string   `mapstructure:"mayamidgard_base_url"`
+		SolanaRPCURL       string   `mapstructure:"solana_rpc_url"`
 	}
+	Pricing        []PricingRule  `mapstructure:"pricing"`
+	Valuation      Valuation      `mapstructure:"valuation"`
+	TokenDiscovery TokenDiscovery `mapstructure:"token_discovery"`
+	Admin          struct {
+		APIKey string `mapstructure:"api_key"` // required by the admin endpoints through the x-admin-api-key header, empty disables them
+	}
+	// interval at which running processes check the seasons table for changes made through the admin endpoints
+	SeasonReloadSeconds int64 `mapstructure:"season_reload_seconds"`
+	// days after its registration a vault can still set the code of the vault which referred it, 0 is unlimited
+	ReferralWindowDays int64 `mapstructure:"referral_window_days"`
+	// partners allowed to verify quests, a quest can only be verified by its partner
+	QuestPartners []QuestPartner `mapstructure:"quest_partners"`
+	// seed of the quests table, used only while the table is empty
+	Quests []Quest `mapstructure:"quests"`
+}
+
+// QuestPartner authenticates the quest verifications of a partner with an api key, an ip allowlist or both.
+// A partner without api key and allowlist is open, like the coinmarketcap quest has always been.
+type QuestPartner struct {
+	Name       string   `mapstructure:"name"`
+	APIKey     string   `mapstructure:"api_key"`     // expected in the x-partner-api-key header
+	AllowedIPs []string `mapstructure:"allowed_ips"` // client ips allowed to verify
+}
+
+// IsOpen returns true if the partner checks neither an api key nor the client ip, anyone can verify its quests
+func (p QuestPartner) IsOpen() bool {
+	return p.APIKey == "" && len(p.AllowedIPs) == 0
+}
+
+// Allows returns true if a request with the given api key from the given ip passes the partner checks
+func (p QuestPartner) Allows(apiKey, ip string) bool {
+	if p.APIKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(p.APIKey)) != 1 {
+		return false
+	}
+	if len(p.AllowedIPs) > 0 && !slices.Contains(p.AllowedIPs, ip) {
+		return false
+	}
+	return true
+}
+
+// Quest is a partner quest seeding the quests table, see models.Quest for the criteria
+type Quest struct {
+	ID       string  `mapstructure:"id"`
+	Partner  string  `mapstructure:"partner"`
+	Criteria string  `mapstructure:"criteria"`
+	Chain    string  `mapstructure:"chain"`   // chain of the chain_address criteria
+	Minimum  float64 `mapstructure:"minimum"` // minimum usd value of the min_balance and swap_volume criteria
+	Points   float64 `mapstructure:"points"`  // points added to the vault the first time it completes the quest
+	Enabled  bool    `mapstructure:"enabled"`
+}
+
+// defaultQuestPartners and defaultQuests keep the coinmarketcap quest working when no quests are configured
+var defaultQuestPartners = []QuestPartner{{Name: "coinmarketcap"}}
+
+var defaultQuests = []Quest{{ID: "cmc", Partner: "coinmarketcap", Criteria: "holds_vault", Enabled: true}}
+
+// GetQuestPartner returns the partner with the given name
+func (cfg *Config) GetQuestPartner(name string) (QuestPartner, bool) {
+	for _, partner := range cfg.QuestPartners {
+		if partner.Name == name {
+			return partner, true
+		}
+	}
+	return QuestPartner{}, false
+}
+
+// TokenDiscovery configures the worker phase which adds the tokens a vault holds but never added as coins
+type TokenDiscovery struct {
+	Enabled        bool  `mapstructure:"enabled"`
+	RequestDelayMs int64 `mapstructure:"request_delay_ms"` // delay between discovery requests, to avoid hitting rate limits
+	CacheTTLHours  int64 `mapstructure:"cache_ttl_hours"`  // an address is not discovered again within this period
+}
+
+// Valuation holds the liquidity thresholds used to cap the creditable USD value of a token holding
+type Valuation struct {
+	Enabled        bool    `mapstructure:"enabled"`          // when disabled holdings are credited their full value
+	MinVolume24h   float64 `mapstructure:"min_volume_24h"`   // tokens with less 24h volume (USD) are excluded, 0 disables the check
+	MinMarketCap   float64 `mapstructure:"min_market_cap"`   // tokens with a smaller market cap (USD) are excluded, 0 disables the check
+	MaxVolumeShare float64 `mapstructure:"max_volume_share"` // a holding is credited at most this share of the token's 24h volume, 0 disables the cap
+}
+
+// Supported price sources for pricing rules
+const (
+	PriceSourceCoinGecko   = "coingecko"
+	PriceSourceLiFi        = "lifi"
+	PriceSourceMidgard     = "midgard"
+	PriceSourceMayaMidgard = "maya_midgard"
+	PriceSourceCMC         = "cmc"
+	PriceSourceFixed       = "fixed"
+)
+
+// PricingRule maps a coin (chain + ticker or contract address) to the source its price is fetched from
+type PricingRule struct {
+	Chain           string    `mapstructure:"chain" json:"chain"`
+	Ticker          string    `mapstructure:"ticker" json:"ticker"`
+	ContractAddress string    `mapstructure:"contract_address" json:"contract_address"` // takes precedence over ticker when set
+	Source          string    `mapstructure:"source" json:"source"`
+	SourceID        string    `mapstructure:"source_id" json:"source_id"`       // coingecko id, lifi token, midgard asset or cmc id
+	SourceChain     string    `mapstructure:"source_chain" json:"source_chain"` // chain key used by lifi
+	FixedPrice      float64   `mapstructure:"fixed_price" json:"fixed_price"`
+	ValidFrom       time.Time `mapstructure:"valid_from" json:"valid_from"` // zero value means no lower bound
+	ValidTo         time.Time `mapstructure:"valid_to" json:"valid_to"`     // zero value means no upper bound
+}
+
+// IsActive returns true if the rule is valid at the given time
+func (r PricingRule) IsActive(t time.Time) bool {
+	if !r.ValidFrom.IsZero() && t.Before(r.ValidFrom) {
+		return false
+	}
+	if !r.ValidTo.IsZero() && !t.Before(r.ValidTo) {
+		return false
+	}
+	return true
+}
+
+// defaultPricingRules are the special price sources used when no pricing section is configured
+var defaultPricingRules = []PricingRule{
+	{Chain: "MayaChain", Ticker: "CACAO", Source: PriceSourceMayaMidgard},
+	{Chain: "Solana", Ticker: "KWEEN", Source: PriceSourceCoinGecko, SourceID: "kween"},
+	{Chain: "Ethereum", Ticker: "vTHOR", Source: PriceSourceLiFi, SourceChain: "eth", SourceID: "0x815C23eCA83261b6Ec689b60Cc4a58b54BC24D8D"},
+	{Chain: "MayaChain", Ticker: "MAYA", Source: PriceSourceFixed, FixedPrice: 40},
+	{Chain: "THORChain", Ticker: "TCY", Source: PriceSourceMidgard, SourceID: "THOR.TCY"},
+	{Chain: "THORChain", Ticker: "RUJIRA", Source: PriceSourceCoinGecko, SourceID: "rujira"},
 }
 
 type NFT struct {
@@ -60,13 +187,35 @@ type Token struct {
 }
 
 type AirdropSeason struct {
-	ID         uint        `mapstructure:"id" json:"id"`
-	Start      time.Time   `mapstructure:"start" json:"start"`
-	End        time.Time   `mapstructure:"end" json:"end"`
-	Milestones []Milestone `mapstructure:"milestones" json:"milestones"` // list of vulti milestones
-	NFTs       []NFT       `mapstructure:"nfts" json:"nfts"`             // list of boosting NFTs
-	Tokens     []Token     `mapstructure:"tokens" json:"tokens"`         // list of boosting tokens
+	ID         uint           `mapstructure:"id" json:"id"`
+	Start      time.Time      `mapstructure:"start" json:"start"`
+	End        time.Time      `mapstructure:"end" json:"end"`
+	Milestones []Milestone    `mapstructure:"milestones" json:"milestones"` // list of vulti milestones
+	NFTs       []NFT          `mapstructure:"nfts" json:"nfts"`             // list of boosting NFTs
+	Tokens     []Token        `mapstructure:"tokens" json:"tokens"`         // list of boosting tokens
+	Referral   ReferralScheme `mapstructure:"referral" json:"referral"`     // referral rewards, disabled when both shares are 0
+}
+
+// ReferralScheme rewards referrers with a share of the points their valid referees earn in every job
+type ReferralScheme struct {
+	DirectShare      float64 `mapstructure:"direct_share" json:"direct_share"`             // share of the points of the direct referees
+	SecondLevelShare float64 `mapstructure:"second_level_share" json:"second_level_share"` // share of the points of the referees of the referees
+	MaxPointsPerJob  float64 `mapstructure:"max_points_per_job" json:"max_points_per_job"` // cap of the reward of a referrer per job, 0 is uncapped
+}
+
+func (r ReferralScheme) IsEnabled() bool {
+	return r.DirectShare > 0 || r.SecondLevelShare > 0
 }
+
+// Reward returns the referral points of a referrer whose direct and second level referees earned the given points
+func (r ReferralScheme) Reward(directPoints, secondLevelPoints float64) float64 {
+	reward := directPoints*r.DirectShare + secondLevelPoints*r.SecondLevelShare
+	if r.MaxPointsPerJob > 0 && reward > r.MaxPointsPerJob {
+		return r.MaxPointsPerJob
+	}
+	return reward
+}
+
 type Milestone struct {
 	Minimum int `mapstructure:"minimum" json:"minimum"` // minimum amount of vulti to reach this milestone
 	Prize   int `mapstructure:"prize" json:"prize"`     // prize for this milestone
@@ -96,6 +245,22 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("season.milestones", []int{5000, 10000, 50000, 100000})
 	viper.SetDefault("season.nfts", []NFT{})
 	viper.SetDefault("season.tokens", []Token{})
+	viper.SetDefault("volumetrackingapi.affiliate_address", []string{})
+	viper.SetDefault("volumetrackingapi.solana_rpc_url", "https://api.vultisig.com/solana/")
+	viper.SetDefault("pricing", defaultPricingRules)
+	viper.SetDefault("valuation.enabled", false)
+	viper.SetDefault("valuation.min_volume_24h", 10000)
+	viper.SetDefault("valuation.min_market_cap", 0)
+	viper.SetDefault("valuation.max_volume_share", 0.1)
+	viper.SetDefault("token_discovery.enabled", false)
+	viper.SetDefault("token_discovery.request_delay_ms", 500)
+	viper.SetDefault("token_discovery.cache_ttl_hours", 20)
+	viper.SetDefault("admin.api_key", "")
+	viper.SetDefault("season_reload_seconds", 60)
+	viper.SetDefault("referral_window_days", 30)
+	viper.SetDefault("server.trusted_proxies", []string{})
+	viper.SetDefault("quest_partners", defaultQuestPartners)
+	viper.SetDefault("quests", defaultQuests)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -105,7 +270,12 @@ func LoadConfig() (*Config, error) {
 	}
 
 	err := viper.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
-		dc.DecodeHook = mapstructure.StringToTimeHookFunc(time.RFC3339)
+		// keep the default hooks of viper, they split comma separated env overrides into lists
+		dc.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+		)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode into struct, %w", err)
@@ -113,13 +283,13 @@ func LoadConfig() (*Config, error) {
 	return &cfg, nil
 }
 
-func (cfg *Config) GetCurrentSeason() AirdropSeason {
-	var currentSeason AirdropSeason
-	for _, season := range cfg.Seasons {
-		if time.Now().After(season.Start) && time.Now().Before(season.End) {
-			currentSeason = season
-
+// GetActivePricingRules returns the pricing rules which are valid at the given time
+func (cfg *Config) GetActivePricingRules(t time.Time) []PricingRule {
+	rules := make([]PricingRule, 0, len(cfg.Pricing))
+	for _, rule := range cfg.Pricing {
+		if rule.IsActive(t) {
+			rules = append(rules, rule)
 		}
 	}
-	return currentSeason
+	return rules
 }
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingRuleIsActive(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rule := PricingRule{Chain: "MayaChain", Ticker: "MAYA", Source: PriceSourceFixed, FixedPrice: 40}
+	assert.True(t, rule.IsActive(now))
+
+	rule.ValidFrom = now.Add(time.Hour)
+	assert.False(t, rule.IsActive(now))
+
+	rule.ValidFrom = now.Add(-time.Hour)
+	rule.ValidTo = now
+	assert.False(t, rule.IsActive(now))
+
+	rule.ValidTo = now.Add(time.Hour)
+	assert.True(t, rule.IsActive(now))
+
+	cfg := Config{Pricing: []PricingRule{rule, {Chain: "Solana", Ticker: "KWEEN", ValidTo: now}}}
+	assert.Len(t, cfg.GetActivePricingRules(now), 1)
+}
+
+func TestReferralSchemeReward(t *testing.T) {
+	scheme := ReferralScheme{}
+	assert.False(t, scheme.IsEnabled())
+	assert.Equal(t, 0.0, scheme.Reward(100, 100))
+
+	scheme = ReferralScheme{DirectShare: 0.1, SecondLevelShare: 0.05}
+	assert.True(t, scheme.IsEnabled())
+	assert.InDelta(t, 15.0, scheme.Reward(100, 100), 1e-9)
+
+	scheme.MaxPointsPerJob = 12
+	assert.Equal(t, 12.0, scheme.Reward(100, 100))
+	assert.InDelta(t, 1.5, scheme.Reward(10, 10), 1e-9)
+}
+
+func TestQuestPartnerAllows(t *testing.T) {
+	open := QuestPartner{Name: "coinmarketcap"}
+	assert.True(t, open.Allows("", "1.2.3.4"))
+
+	keyed := QuestPartner{Name: "partner", APIKey: "secret"}
+	assert.True(t, keyed.Allows("secret", "1.2.3.4"))
+	assert.False(t, keyed.Allows("", "1.2.3.4"))
+
+	both := QuestPartner{Name: "partner", APIKey: "secret", AllowedIPs: []string{"10.0.0.1"}}
+	assert.True(t, both.Allows("secret", "10.0.0.1"))
+	assert.False(t, both.Allows("secret", "1.2.3.4"))
+
+	assert.True(t, open.IsOpen())
+	assert.False(t, both.IsOpen())
+
+	cfg := Config{QuestPartners: []QuestPartner{open, both}}
+	partner, ok := cfg.GetQuestPartner("partner")
+	assert.True(t, ok)
+	assert.Equal(t, both.AllowedIPs, partner.AllowedIPs)
+	_, ok = cfg.GetQuestPartner("unknown")
+	assert.False(t, ok)
+}
+
+func TestLoadConfigSplitsEnvLists(t *testing.T) {
+	t.Setenv("VOLUMETRACKINGAPI_AFFILIATE_ADDRESS", "0xabc,0xdef")
+	cfg, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xabc", "0xdef"}, cfg.VolumeTrackingAPI.AffiliateAddress)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/vultisig/airdrop-registry/internal/common"
 	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 const (
@@ -25,44 +26,42 @@ type BalanceResolver struct {
 	thorchainRuneProviders   *sync.Map
 	thornodeBaseAddress      string
 	tonBalanceBaseAddress    string
+	tonApiBaseAddress        string
+	suiRpcBaseAddress        string
 	tronBalanceBaseAddress   string
 	xrpBalanceBaseAddress    string
 	kujiraBalanceBaseAddress string
-	vultisigApiProxy         string
-	whitelistNFTCollection   []models.NFTCollection
-	whiteListSPLToken        map[string]string
-	whiteListTRC20Token      map[string]int
+	// bank balance endpoints used for non native denoms
+	cosmosBalanceBaseAddresses map[common.Chain]string
+	vultisigApiProxy           string
+	tokenRegistry              *tokens.Registry
 }
 
-func NewBalanceResolver() (*BalanceResolver, error) {
+func NewBalanceResolver(tokenRegistry *tokens.Registry) (*BalanceResolver, error) {
+	if nil == tokenRegistry {
+		return nil, fmt.Errorf("tokenRegistry is nil")
+	}
 	return &BalanceResolver{
 		logger:                   logrus.WithField("module", "balance_resolver").Logger,
 		thorchainBondProviders:   &sync.Map{},
 		thorchainRuneProviders:   &sync.Map{},
 		thornodeBaseAddress:      "https://thornode.ninerealms.com",
 		tonBalanceBaseAddress:    "https://api.vultisig.com/ton/v3/addressInformation",
+		tonApiBaseAddress:        "https://api.vultisig.com/ton/v3",
+		suiRpcBaseAddress:        "https://sui-rpc.publicnode.com",
 		tronBalanceBaseAddress:   "https://api.trongrid.io",
 		xrpBalanceBaseAddress:    "https://xrplcluster.com",
 		kujiraBalanceBaseAddress: "https://kujira-rest.publicnode.com/cosmos/bank/v1beta1/balances",
-		vultisigApiProxy:         "https://api.vultisig.com",
-		whitelistNFTCollection: []models.NFTCollection{
-			{
-				Chain:             common.Ethereum,
-				CollectionAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291",
-				CollectionSlug:    "thorguards",
-			},
-		},
-		whiteListSPLToken: map[string]string{
-			"DEf93bSt8dx58gDFCcz4CwbjYZzjwaRBYAciJYLfdCA9": "KWEEN",
-			"rndrizKT3MK1iimdxRdWabcF7Zg7AR5T4nud4EkHBof":  "RENDER",
-			"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": "USDC",
-			"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": "USDT",
-			"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN":  "JUP",
-			"FgWto1nfArQTpg3o74sYkti753caPfHNXHG8CkedDpMg": "DORITO",
-		},
-		whiteListTRC20Token: map[string]int{
-			"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t": 6, // USDT
+		cosmosBalanceBaseAddresses: map[common.Chain]string{
+			common.GaiaChain: "https://cosmos-rest.publicnode.com/cosmos/bank/v1beta1/balances",
+			common.Osmosis:   "https://osmosis-rest.publicnode.com/cosmos/bank/v1beta1/balances",
+			common.Dydx:      "https://dydx-rest.publicnode.com/cosmos/bank/v1beta1/balances",
+			common.Terra:     "https://terra-lcd.publicnode.com/cosmos/bank/v1beta1/spendable_balances",
+			common.Noble:     "https://noble-api.polkachu.com/cosmos/bank/v1beta1/balances",
+			common.Akash:     "https://akash-rest.publicnode.com/cosmos/bank/v1beta1/balances",
 		},
+		vultisigApiProxy: "https://api.vultisig.com",
+		tokenRegistry:    tokenRegistry,
 	}, nil
 }
 
@@ -89,16 +88,18 @@ func (b *BalanceResolver) GetBalanceWithRetry(coin models.CoinDBModel) (float64,
 }
 
 func (b *BalanceResolver) GetBalance(coin models.CoinDBModel) (float64, error) {
+	token, registered := b.tokenRegistry.Find(coin.Chain, coin.ContractAddress, coin.Ticker)
+	if registered && !token.ScoringEnabled {
+		return 0, nil
+	}
 	switch coin.Chain {
 	case common.Bitcoin, common.BitcoinCash, common.Litecoin, common.Dogecoin, common.Dash, common.Zcash:
 		balance, _, err := b.FetchUtxoBalanceOfAddress(coin.Address, coin.Chain)
 		return balance, err
 	case common.Arbitrum, common.Ethereum, common.Zksync, common.Optimism, common.Polygon, common.BscChain, common.Avalanche, common.Base, common.Blast, common.CronosChain:
 		if coin.ContractAddress != "" {
-			for _, nft := range b.whitelistNFTCollection {
-				if strings.EqualFold(coin.ContractAddress, nft.CollectionAddress) {
-					return b.fetchERC721TokenBalance(coin.Chain, coin.ContractAddress, coin.Address)
-				}
+			if registered && token.IsNFT() {
+				return b.fetchERC721TokenBalance(coin.Chain, coin.ContractAddress, coin.Address)
 			}
 			return b.fetchERC20TokenBalance(coin.Chain, coin.ContractAddress, coin.Address, int64(coin.Decimals))
 		} else {
@@ -113,14 +114,26 @@ func (b *BalanceResolver) GetBalance(coin models.CoinDBModel) (float64, error) {
 			return b.FetchMayachainCacoBalanceOfAddress(coin.Address)
 		}
 	case common.GaiaChain:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchCosmosBalanceOfAddress(coin.Address)
 	case common.Dydx:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchDydxBalanceOfAddress(coin.Address)
 	case common.Terra:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchTerraBalanceOfAddress(coin.Address)
 	case common.TerraClassic:
 		return b.FetchTerraClassicBalanceOfAddress(coin.Address)
 	case common.Noble:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		if strings.EqualFold(coin.Ticker, "USDC") { //  We only support USDC on Noble for now
 			return b.FetchNobleBalanceOfAddress(coin.Address)
 		}
@@ -131,40 +144,47 @@ func (b *BalanceResolver) GetBalance(coin models.CoinDBModel) (float64, error) {
 			return b.FetchKujiraBalanceOfAddress(coin.Address, coin.ContractAddress, coin.Decimals)
 		}
 	case common.Osmosis:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchOsmosisBalanceOfAddress(coin.Address)
 	case common.Akash:
+		if coin.ContractAddress != "" {
+			return b.FetchCosmosDenomBalanceOfAddress(coin.Chain, coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchAkashBalanceOfAddress(coin.Address)
 	case common.Solana:
-		//ignore none native coins (spl tokens)
+		//ignore spl tokens which are not in the token registry
 		if coin.ContractAddress == "" {
 			return b.FetchSolanaBalanceOfAddress(coin.Address)
-		} else {
-			for addr, _ := range b.whiteListSPLToken {
-				if strings.EqualFold(coin.ContractAddress, addr) {
-					return b.FetchSPLBalanceOfAddress(coin.Address, coin.ContractAddress)
-				}
-			}
-			return 0, nil
+		} else if registered {
+			return b.FetchSPLBalanceOfAddress(coin.Address, coin.ContractAddress)
 		}
+		return 0, nil
 	case common.Polkadot:
 		return b.FetchPolkadotBalanceOfAddress(coin.Address)
 	case common.Sui:
+		if coin.ContractAddress != "" {
+			return b.FetchSuiCoinBalanceOfAddress(coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchSuiBalanceOfAddress(coin.Address)
 	case common.Ton:
+		if coin.ContractAddress != "" {
+			return b.FetchJettonBalanceOfAddress(coin.Address, coin.ContractAddress, coin.Decimals)
+		}
 		return b.FetchTonBalanceOfAddress(coin.Address)
 	case common.XRP:
+		if coin.ContractAddress != "" {
+			return b.FetchXRPTokenBalanceOfAddress(coin.Address, coin.ContractAddress)
+		}
 		return b.FetchXRPBalanceOfAddress(coin.Address)
 	case common.Tron:
 		if coin.ContractAddress == "" { // TRX token
 			return b.FetchTronBalanceOfAddress(coin.Address, "", 6)
-		} else {
-			for addr, decimal := range b.whiteListTRC20Token {
-				if coin.ContractAddress == addr {
-					return b.FetchTronBalanceOfAddress(coin.Address, coin.ContractAddress, decimal)
-				}
-			}
-			return 0, nil
+		} else if registered {
+			return b.FetchTronBalanceOfAddress(coin.Address, coin.ContractAddress, token.Decimals)
 		}
+		return 0, nil
 	default:
 		return 0, fmt.Errorf("chain: %s doesn't support", coin.Chain)
 	}
@@ -11,11 +11,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 func TestGetUtxoBalances(t *testing.T) {
 	t.Skip()
-	b, err := NewBalanceResolver()
+	b, err := NewBalanceResolver(tokens.NewRegistry(nil))
 	assert.Nil(t, err)
 	result, err := b.FetchSolanaBalanceOfAddress("H7FmBYGBi5EmbJaKA88yBgmyGm7eSFdkzCtigwkeaXxb")
 	assert.Nil(t, err)
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
 )
 
 func (b *BalanceResolver) FetchThorchainBalanceOfAddress(address string) (float64, error) {
@@ -191,6 +193,16 @@ func (b *BalanceResolver) FetchAkashBalanceOfAddress(address string) (float64, e
 	return b.fetchSpecificCosmosBalance(url, "uakt", 6)
 }
 
+// FetchCosmosDenomBalanceOfAddress returns the balance of a non native denom (IBC or token factory) held by address
+func (b *BalanceResolver) FetchCosmosDenomBalanceOfAddress(chain common.Chain, address, denom string, decimals int) (float64, error) {
+	baseAddress, ok := b.cosmosBalanceBaseAddresses[chain]
+	if !ok {
+		return 0, fmt.Errorf("chain: %s doesn't support denom balances", chain)
+	}
+	url := fmt.Sprintf("%s/%s", baseAddress, address)
+	return b.fetchSpecificCosmosBalance(url, denom, decimals)
+}
+
 type CosmosData struct {
 	Balances []struct {
 		Denom  string `json:"denom"`
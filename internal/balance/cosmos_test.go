@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/vultisig/airdrop-registry/internal/common"
 	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 func TestFetchThorchainBalanceOfAddress(t *testing.T) {
@@ -75,7 +76,7 @@ func TestFetchKujiraBalanceOfAddress(t *testing.T) {
 
 	defer mockServer.Close()
 
-	balanceResolver, err := NewBalanceResolver()
+	balanceResolver, err := NewBalanceResolver(tokens.NewRegistry(nil))
 	assert.NoError(t, err, "Failed to create balance resolver")
 	balanceResolver.kujiraBalanceBaseAddress = mockServer.URL
 	balance, err := balanceResolver.GetBalance(models.CoinDBModel{
@@ -209,3 +210,31 @@ func TestFetchAkashBalanceOfAddress(t *testing.T) {
 	assert.NoErrorf(t, err, "Failed to get akash address balance: %v", err)
 	assert.Equal(t, float64(540733), balance)
 }
+
+func TestFetchCosmosDenomBalanceOfAddress(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/osmo1address", r.URL.Path)
+		response := map[string]interface{}{
+			"balances": []map[string]interface{}{
+				{"denom": "uosmo", "amount": "5000000"},
+				{"denom": "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", "amount": "2500000"},
+			},
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	balanceResolver := &BalanceResolver{
+		logger: logrus.WithField("module", "balance_resolver_test").Logger,
+		cosmosBalanceBaseAddresses: map[common.Chain]string{
+			common.Osmosis: mockServer.URL,
+		},
+	}
+	b, err := balanceResolver.FetchCosmosDenomBalanceOfAddress(common.Osmosis, "osmo1address", "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, b)
+
+	_, err = balanceResolver.FetchCosmosDenomBalanceOfAddress(common.Kujira, "kujira1address", "ukuji", 6)
+	assert.Error(t, err)
+}
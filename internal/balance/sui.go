@@ -4,15 +4,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 )
 
 func (b *BalanceResolver) FetchSuiBalanceOfAddress(address string) (float64, error) {
-	rpcUrl := "https://sui-rpc.publicnode.com"
+	return b.FetchSuiCoinBalanceOfAddress(address, "0x2::sui::SUI", 9)
+}
+
+// FetchSuiCoinBalanceOfAddress returns the balance of the given coin type held by address
+func (b *BalanceResolver) FetchSuiCoinBalanceOfAddress(address, coinType string, decimals int) (float64, error) {
+	rpcUrl := b.suiRpcBaseAddress
 	// Create parameters array
 	params := []interface{}{
 		address,
+		coinType,
 	}
 
 	// Create RPC request
@@ -59,7 +66,7 @@ func (b *BalanceResolver) FetchSuiBalanceOfAddress(address string) (float64, err
 		return 0, fmt.Errorf("error converting balance to float: %w", err)
 	}
 
-	balance = balance / 1e9
+	balance = balance / math.Pow10(decimals)
 
 	return balance, nil
 }
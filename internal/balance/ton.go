@@ -3,7 +3,10 @@ package balance
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 type tonBalanceResult struct {
@@ -26,3 +29,43 @@ func (b *BalanceResolver) FetchTonBalanceOfAddress(address string) (float64, err
 	}
 	return float64(result.Balance) * 1e-9, nil
 }
+
+type tonJettonWalletsResult struct {
+	JettonWallets []struct {
+		Balance string `json:"balance"`
+	} `json:"jetton_wallets"`
+}
+
+// FetchJettonBalanceOfAddress returns the balance of the jetton (identified by its master address) held by address
+func (b *BalanceResolver) FetchJettonBalanceOfAddress(address, jettonMaster string, decimals int) (float64, error) {
+	params := url.Values{}
+	params.Set("owner_address", address)
+	params.Set("jetton_address", jettonMaster)
+	params.Set("limit", "1")
+	reqUrl := fmt.Sprintf("%s/jetton/wallets?%s", b.tonApiBaseAddress, params.Encode())
+	resp, err := http.Get(reqUrl)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching jetton balance of address %s on TON: %w", address, err)
+	}
+	defer b.closer(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// rate limited, need to backoff and then retry
+		return 0, ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching jetton balance of address %s on TON: %s", address, resp.Status)
+	}
+	var result tonJettonWalletsResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("error decoding response: %w", err)
+	}
+	var balance float64
+	for _, wallet := range result.JettonWallets {
+		amount, err := strconv.ParseFloat(wallet.Balance, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error converting balance to float: %w", err)
+		}
+		balance += amount
+	}
+	return balance / math.Pow10(decimals), nil
+}
@@ -36,3 +36,26 @@ func TestFetchTonBalanceOfAddress(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, float64(10), b)
 }
+
+func TestFetchJettonBalanceOfAddress(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/jetton/wallets", r.URL.Path)
+		assert.Equal(t, "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs", r.URL.Query().Get("jetton_address"))
+		response := map[string]interface{}{
+			"jetton_wallets": []map[string]interface{}{
+				{"address": "0:AAA", "balance": "1500000", "jetton": "0:B113A994B5024A16719F69139328EB759596C38A25F59028B146FECDC3621DFE"},
+			},
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	balanceResolver := &BalanceResolver{
+		logger:            logrus.WithField("module", "balance_resolver_test").Logger,
+		tonApiBaseAddress: mockServer.URL,
+	}
+	b, err := balanceResolver.FetchJettonBalanceOfAddress("UQBM2SHV1AuhDNMB4E69SMtzqstKG2J_ZXwqpdgmAuulrUom", "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, b)
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (b *BalanceResolver) FetchXRPBalanceOfAddress(address string) (float64, error) {
@@ -59,3 +62,66 @@ func (b *BalanceResolver) FetchXRPBalanceOfAddress(address string) (float64, err
 
 	return float64(rpcResp.Result.AccountData.Balance) / 1e6, nil
 }
+
+// FetchXRPTokenBalanceOfAddress returns the balance of an issued currency held by address,
+// contract is the currency code and the issuer joined by a dot
+func (b *BalanceResolver) FetchXRPTokenBalanceOfAddress(address, contract string) (float64, error) {
+	currency, issuer, ok := strings.Cut(contract, ".")
+	if !ok {
+		return 0, fmt.Errorf("invalid XRPL token: %s", contract)
+	}
+	params := []interface{}{
+		map[string]interface{}{
+			"account":      address,
+			"peer":         issuer,
+			"ledger_index": "validated",
+		},
+	}
+	rpcReq := RpcRequest{
+		Jsonrpc: "2.0",
+		Method:  "account_lines",
+		Params:  params,
+		Id:      1,
+	}
+	reqBody, err := json.Marshal(rpcReq)
+	if err != nil {
+		return 0, fmt.Errorf("error marshalling RPC request: %w", err)
+	}
+	resp, err := http.Post(b.xrpBalanceBaseAddress, "application/json", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("error fetching token balance of address %s on XRP: %w", address, err)
+	}
+	defer b.closer(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// rate limited, need to backoff and then retry
+		return 0, ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching token balance of address %s on XRP: %s", address, resp.Status)
+	}
+	type RpcXRPLinesResp struct {
+		Result struct {
+			Lines []struct {
+				Account  string `json:"account"`
+				Balance  string `json:"balance"`
+				Currency string `json:"currency"`
+			} `json:"lines"`
+		} `json:"result"`
+	}
+	var rpcResp RpcXRPLinesResp
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return 0, fmt.Errorf("error decoding response: %w", err)
+	}
+	for _, line := range rpcResp.Result.Lines {
+		if line.Currency != currency || line.Account != issuer {
+			continue
+		}
+		balance, err := strconv.ParseFloat(line.Balance, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error converting balance to float: %w", err)
+		}
+		// a negative balance is owed by the address as issuer, it doesn't hold the token
+		return math.Max(balance, 0), nil
+	}
+	return 0, nil
+}
@@ -65,3 +65,31 @@ func TestFetchXRPBalanceOfAddress(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, float64(10), b)
 }
+
+func TestFetchXRPTokenBalanceOfAddress(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{
+			"result": map[string]interface{}{
+				"account": "rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX",
+				"lines": []map[string]interface{}{
+					{"account": "rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz", "balance": "120.5", "currency": "534F4C4F00000000000000000000000000000000"},
+				},
+				"status": "success",
+			},
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	balanceResolver := &BalanceResolver{
+		logger:                logrus.WithField("module", "balance_resolver_test").Logger,
+		xrpBalanceBaseAddress: mockServer.URL,
+	}
+	b, err := balanceResolver.FetchXRPTokenBalanceOfAddress("rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX", "534F4C4F00000000000000000000000000000000.rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz")
+	assert.NoError(t, err)
+	assert.Equal(t, 120.5, b)
+
+	_, err = balanceResolver.FetchXRPTokenBalanceOfAddress("rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX", "SOLO")
+	assert.Error(t, err)
+}
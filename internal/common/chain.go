@@ -102,6 +102,26 @@ var EVMChains = []Chain{
 	Zksync,
 }
 
+// evmChainIDs are the EIP-155 chain ids of the EVM chains
+var evmChainIDs = map[Chain]int64{
+	Ethereum:    1,
+	Avalanche:   43114,
+	BscChain:    56,
+	Base:        8453,
+	Arbitrum:    42161,
+	Optimism:    10,
+	Polygon:     137,
+	Blast:       81457,
+	CronosChain: 25,
+	Zksync:      324,
+}
+
+// GetEVMChainID returns the EIP-155 chain id of an EVM chain
+func GetEVMChainID(chain Chain) (int64, bool) {
+	id, ok := evmChainIDs[chain]
+	return id, ok
+}
+
 var chainDerivePath = map[Chain]string{
 	Bitcoin:      "m/84'/0'/0'/0/0",
 	Ethereum:     "m/44'/60'/0'/0/0",
@@ -143,6 +163,17 @@ func (c Chain) String() string {
 	}
 	return "UNKNOWN"
 }
+
+// ChainFromString returns the chain matching the given name
+func ChainFromString(name string) (Chain, error) {
+	for key, value := range chainToString {
+		if value == name {
+			return key, nil
+		}
+	}
+	return Undefined, fmt.Errorf("unknown chain: %s", name)
+}
+
 func (c Chain) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.String())
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -16,46 +17,81 @@ import (
 
 	"github.com/vultisig/airdrop-registry/config"
 	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
 	"github.com/vultisig/airdrop-registry/internal/services"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 // Api is the main handler for the API
 type Api struct {
-	logger       *logrus.Logger
-	cfg          *config.Config
-	s            *services.Storage
-	router       *gin.Engine
-	cachedData   *cache.Cache
-	questService *QuestService
+	logger        *logrus.Logger
+	cfg           *config.Config
+	s             *services.Storage
+	router        *gin.Engine
+	cachedData    *cache.Cache
+	questService  *QuestService
+	tokenRegistry *tokens.Registry
+	// seasonRegistry is refreshed by the season watcher, and right away after a change through the admin endpoints
+	seasonRegistry *seasons.Registry
+	// discovery is built in the background once the server starts, nil until SetDiscovery is called
+	discovery atomic.Pointer[tokens.VaultDiscoveryService]
 }
 
 // NewApi creates a new Api instance
-func NewApi(cfg *config.Config, s *services.Storage) (*Api, error) {
+func NewApi(cfg *config.Config, s *services.Storage, tokenRegistry *tokens.Registry, seasonRegistry *seasons.Registry) (*Api, error) {
 	if nil == cfg {
 		return nil, fmt.Errorf("config is nil")
 	}
 	if nil == s {
 		return nil, fmt.Errorf("storage is nil")
 	}
+	if nil == tokenRegistry {
+		return nil, fmt.Errorf("token registry is nil")
+	}
+	if nil == seasonRegistry {
+		return nil, fmt.Errorf("season registry is nil")
+	}
 	questService, err := NewQuestService(s)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create quest service: %w", err)
 	}
+	router, err := newRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
 	return &Api{
-		cfg:          cfg,
-		s:            s,
-		router:       gin.Default(),
-		logger:       logrus.WithField("module", "api").Logger,
-		cachedData:   cache.New(5*time.Minute, 10*time.Minute),
-		questService: questService,
+		cfg:            cfg,
+		s:              s,
+		router:         router,
+		logger:         logrus.WithField("module", "api").Logger,
+		cachedData:     cache.New(5*time.Minute, 10*time.Minute),
+		questService:   questService,
+		tokenRegistry:  tokenRegistry,
+		seasonRegistry: seasonRegistry,
 	}, nil
 }
 
+// newRouter returns the gin engine of the api, the client ip is only taken from X-Forwarded-For when the request
+// comes from one of the configured trusted proxies
+func newRouter(cfg *config.Config) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	return router, nil
+}
+
+// SetDiscovery makes the discovery service available to the token discovery and coin endpoints,
+// they return 503 until it's set
+func (a *Api) SetDiscovery(discovery *tokens.VaultDiscoveryService) {
+	a.discovery.Store(discovery)
+}
+
 func (a *Api) setupRouting() {
 	a.router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Replace with your allowed origins
-		AllowMethods:     []string{"GET", "POST", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "x-hex-chain-code"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "x-hex-chain-code", "x-admin-api-key", "x-partner-api-key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -79,6 +115,10 @@ func (a *Api) setupRouting() {
 	rg.GET("/vault/:ecdsaPublicKey/:eddsaPublicKey", a.getVaultHandler)
 	rg.POST("/vault/:ecdsaPublicKey/:eddsaPublicKey/alias", a.updateAliasHandler)
 	rg.POST("/vault/:ecdsaPublicKey/:eddsaPublicKey/referral", a.updateReferralHandler)
+	rg.POST("/vault/:ecdsaPublicKey/:eddsaPublicKey/discover", a.discoverTokensHandler)
+	rg.GET("/vault/:ecdsaPublicKey/:eddsaPublicKey/ranks", a.getVaultRanksHandler)
+	rg.GET("/vault/:ecdsaPublicKey/:eddsaPublicKey/milestones", a.getVaultMilestonesHandler)
+	rg.GET("/vault/:ecdsaPublicKey/:eddsaPublicKey/referrals", a.getVaultReferralsHandler)
 	rg.GET("/vault/shared/:uid", a.getVaultByUIDHandler)
 	rg.POST("/vault/join-airdrop", a.joinAirdrop)
 	rg.POST("/vault/exit-airdrop", a.exitAirdrop)
@@ -96,7 +136,9 @@ func (a *Api) setupRouting() {
 	// leader board
 	//TODO: Rename the endpoint to /leaderboard/rank/vaults
 	rg.GET("/leaderboard/vaults", a.getVaultsByRankHandler)
+	rg.GET("/leaderboard/vaults/around/:uid", a.getLeaderboardAroundHandler)
 	rg.GET("/leaderboard/swap/vaults", a.getVaultsByVolumeHandler)
+	rg.GET("/leaderboard/swap/assets", a.getSwapVolumeByAssetHandler)
 
 	// NFT-related endpoints
 	rg.GET("/nft/price/:collectionID", a.getCollectionMinPriceHandler)
@@ -106,13 +148,42 @@ func (a *Api) setupRouting() {
 	// new endpoint for fetching total points of a season
 	rg.GET("/seasons/points/:seasonID", a.getTotalPointsBySeasonHandler)
 
-	// coinmarketcap quest
+	// price history of past jobs
+	rg.GET("/prices/:cmcId", a.getPriceHistoryHandler)
+	rg.GET("/prices", a.getAssetPriceHistoryHandler)
+
+	// latest job and volume source status
+	rg.GET("/job/status", a.getJobStatusHandler)
+
+	// partner quests, the coinmarketcap endpoint predates the generic one
+	rg.GET("/quests/:questId/verify", a.verifyQuestHandler)
 	rg.GET("/cmc/quest/verify", a.verifyCoinMarketCapQuest)
 
+	// token registry administration
+	admin := rg.Group("/admin", a.adminAuth)
+	admin.GET("/tokens", a.getTokensHandler)
+	admin.POST("/tokens", a.createTokenHandler)
+	admin.PUT("/tokens/:id", a.updateTokenHandler)
+	admin.DELETE("/tokens/:id", a.deleteTokenHandler)
+	// seasons administration
+	admin.GET("/seasons", a.getSeasonsHandler)
+	admin.POST("/seasons", a.createSeasonHandler)
+	admin.PUT("/seasons/:id", a.updateSeasonHandler)
+	admin.DELETE("/seasons/:id", a.deleteSeasonHandler)
+	// referral analytics
+	admin.GET("/referrals/analytics", a.getReferralAnalyticsHandler)
+	admin.GET("/referrals/tree/:uid", a.getReferralTreeHandler)
+	// quests administration
+	admin.GET("/quests", a.getQuestsHandler)
+	admin.POST("/quests", a.createQuestHandler)
+	admin.PUT("/quests/:id", a.updateQuestHandler)
+	admin.DELETE("/quests/:id", a.deleteQuestHandler)
+
 }
 
 func (a *Api) Start() error {
 	a.setupRouting()
+	go a.s.WatchSeasonRegistry(a.seasonRegistry, time.Duration(a.cfg.SeasonReloadSeconds)*time.Second, nil)
 	return a.router.Run(fmt.Sprintf("%s:%d", a.cfg.Server.Host, a.cfg.Server.Port))
 }
 
@@ -1,44 +1,72 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/vultisig/airdrop-registry/internal/models"
-	"github.com/vultisig/airdrop-registry/internal/services"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
-func addCoin(store *services.Storage, coin models.CoinBase, ecdsaPublicKey, eddsaPublicKey, hexChainCode string) (uint, error) {
-	coin.Balance = ""
-	coin.USDValue = ""
-	coin.PriceUSD = ""
+// coinError is the error of one coin of an addCoins request
+type coinError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// getAuthorizedVault returns the vault of the public keys if hexChainCode matches it
+func (a *Api) getAuthorizedVault(ecdsaPublicKey, eddsaPublicKey, hexChainCode string) (*models.Vault, error) {
 	if hexChainCode == "" {
-		return 0, errForbiddenAccess
+		return nil, errForbiddenAccess
 	}
 	// Ensure the relevant vault exist
-	vault, err := store.GetVault(ecdsaPublicKey, eddsaPublicKey)
+	vault, err := a.s.GetVault(ecdsaPublicKey, eddsaPublicKey)
 	if err != nil {
-		return 0, errVaultNotFound
+		return nil, errVaultNotFound
 	}
 	if vault.HexChainCode != hexChainCode {
-		return 0, errForbiddenAccess
+		return nil, errForbiddenAccess
 	}
+	return vault, nil
+}
+
+// coinVerifier returns the vault discovery service, or one verifying coins with the token registry only while the
+// discovery services are being built
+func (a *Api) coinVerifier() *tokens.VaultDiscoveryService {
+	if discovery := a.discovery.Load(); discovery != nil {
+		return discovery
+	}
+	return tokens.NewRegistryVaultDiscoveryService(a.tokenRegistry)
+}
+
+// newCoin checks the coin address belongs to the vault and replaces the client supplied metadata
+// with the token registry or discovery one, balance and prices are never taken from the client
+func (a *Api) newCoin(discovery *tokens.VaultDiscoveryService, vault *models.Vault, coin models.CoinBase) (models.CoinDBModel, error) {
+	coin.Balance = ""
+	coin.USDValue = ""
+	coin.PriceUSD = ""
 	addr, err := vault.GetAddress(coin.Chain)
 	if err != nil {
-		return 0, errFailedToGetAddress
+		return models.CoinDBModel{}, errFailedToGetAddress
 	}
 	if coin.Address != addr {
-		return 0, errAddressNotMatch
+		return models.CoinDBModel{}, errAddressNotMatch
 	}
-	coinDB := models.CoinDBModel{
-		CoinBase: coin,
-		VaultID:  vault.ID,
+	verified, err := discovery.VerifyCoin(coin)
+	if errors.Is(err, tokens.ErrDiscoveryNotReady) {
+		return models.CoinDBModel{}, errDiscoveryNotReady
 	}
-	if err := store.AddCoin(&coinDB); err != nil {
-		return 0, errFailedToAddCoin
+	if err != nil {
+		a.logger.Warnf("failed to verify coin: %v", err)
+		return models.CoinDBModel{}, errUnknownToken
 	}
-	return coinDB.ID, nil
+	return models.CoinDBModel{
+		CoinBase: verified,
+		VaultID:  vault.ID,
+	}, nil
 }
 
 func (a *Api) addCoin(c *gin.Context) {
@@ -48,21 +76,30 @@ func (a *Api) addCoin(c *gin.Context) {
 		_ = c.Error(errInvalidRequest)
 		return
 	}
-	coin.Balance = ""
-	coin.USDValue = ""
-	coin.PriceUSD = ""
+	discovery := a.coinVerifier()
 	ecdsaPublicKey := c.Param("ecdsaPublicKey")
 	eddsaPublicKey := c.Param("eddsaPublicKey")
 	hexChainCode := c.GetHeader("x-hex-chain-code")
-	id, err := addCoin(a.s, coin, ecdsaPublicKey, eddsaPublicKey, hexChainCode)
+	vault, err := a.getAuthorizedVault(ecdsaPublicKey, eddsaPublicKey, hexChainCode)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	coinDB, err := a.newCoin(discovery, vault, coin)
 	if err != nil {
 		a.logger.Errorf("failed to add coin: %v", err)
 		_ = c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"coin_id": id})
+	if err := a.s.AddCoin(&coinDB); err != nil {
+		a.logger.Errorf("failed to add coin: %v", err)
+		_ = c.Error(errFailedToAddCoin)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"coin_id": coinDB.ID})
 }
 
+// addCoins adds all coins or none, when a coin is invalid the error of every invalid coin is returned by its index
 func (a *Api) addCoins(c *gin.Context) {
 	var coins []models.CoinBase
 	if err := c.ShouldBindJSON(&coins); err != nil {
@@ -70,22 +107,61 @@ func (a *Api) addCoins(c *gin.Context) {
 		_ = c.Error(errInvalidRequest)
 		return
 	}
+	discovery := a.coinVerifier()
 	ecdsaPublicKey := c.Param("ecdsaPublicKey")
 	eddsaPublicKey := c.Param("eddsaPublicKey")
 	hexChainCode := c.GetHeader("x-hex-chain-code")
-	ids := make([]uint, 0)
+	vault, err := a.getAuthorizedVault(ecdsaPublicKey, eddsaPublicKey, hexChainCode)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	existing, err := a.s.GetCoins(vault.ID)
+	if err != nil {
+		a.logger.Errorf("failed to get coins: %v", err)
+		_ = c.Error(errFailedToGetCoin)
+		return
+	}
+	coinDBs := make([]models.CoinDBModel, 0, len(coins))
+	coinErrors := make([]coinError, 0)
 	for i := range coins {
-		id, err := addCoin(a.s, coins[i], ecdsaPublicKey, eddsaPublicKey, hexChainCode)
-		if err != nil {
-			a.logger.Errorf("failed to add coin: %v", err)
+		coinDB, err := a.newCoin(discovery, vault, coins[i])
+		if errors.Is(err, errDiscoveryNotReady) {
 			_ = c.Error(err)
 			return
 		}
-		ids = append(ids, id)
+		if err == nil && (hasSameCoin(existing, coinDB) || hasSameCoin(coinDBs, coinDB)) {
+			err = errCoinAlreadyAdded
+		}
+		if err != nil {
+			coinErrors = append(coinErrors, coinError{Index: i, Error: err.Error()})
+			continue
+		}
+		coinDBs = append(coinDBs, coinDB)
+	}
+	if len(coinErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidCoins.Error(), "coin_errors": coinErrors})
+		return
+	}
+	ids, err := a.s.AddCoins(coinDBs)
+	if err != nil {
+		a.logger.Errorf("failed to add coins: %v", err)
+		_ = c.Error(errFailedToAddCoin)
+		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"coin_ids": ids})
 }
 
+// hasSameCoin reports whether coins has a coin with the chain, ticker and address of coin, the unique key of the coins table
+func hasSameCoin(coins []models.CoinDBModel, coin models.CoinDBModel) bool {
+	for i := range coins {
+		if coins[i].Chain == coin.Chain && strings.EqualFold(coins[i].Ticker, coin.Ticker) && coins[i].Address == coin.Address {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *Api) deleteCoin(c *gin.Context) {
 	ecdsaPublicKey := c.Param("ecdsaPublicKey")
 	eddsaPublicKey := c.Param("eddsaPublicKey")
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// discoverTokensHandler discovers the tokens the vault holds on all supported chains,
+// with ?add=true the discovered tokens the vault doesn't have yet are added as coins
+func (a *Api) discoverTokensHandler(c *gin.Context) {
+	ecdsaPublicKey := c.Param("ecdsaPublicKey")
+	eddsaPublicKey := c.Param("eddsaPublicKey")
+	hexChainCode := c.GetHeader("x-hex-chain-code")
+	add := false
+	if strAdd := c.Query("add"); strAdd != "" {
+		var err error
+		if add, err = strconv.ParseBool(strAdd); err != nil {
+			_ = c.Error(errInvalidRequest)
+			return
+		}
+	}
+	if hexChainCode == "" {
+		_ = c.Error(errForbiddenAccess)
+		return
+	}
+	discovery := a.discovery.Load()
+	if discovery == nil {
+		_ = c.Error(errDiscoveryNotReady)
+		return
+	}
+	vault, err := a.s.GetVault(ecdsaPublicKey, eddsaPublicKey)
+	if err != nil {
+		a.logger.Errorf("failed to get vault: %v", err)
+		_ = c.Error(errVaultNotFound)
+		return
+	}
+	if vault.HexChainCode != hexChainCode {
+		_ = c.Error(errForbiddenAccess)
+		return
+	}
+	discovered := discovery.Discover(vault.GetAddress)
+	ids := make([]uint, 0)
+	if add {
+		if ids, err = a.s.AddDiscoveredCoins(vault, discovered); err != nil {
+			a.logger.Errorf("failed to add discovered coins: %v", err)
+			if ids == nil {
+				_ = c.Error(errFailedToAddCoin)
+				return
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"coins": discovered, "coin_ids": ids})
+}
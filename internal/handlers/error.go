@@ -29,6 +29,34 @@ var (
 	errFailedToSetTheme        = errors.New("FAIL_TO_SET_THEME")
 	errLogoTooLarge            = errors.New("LOGO_TOO_LARGE")
 	errFailedToGetCollection   = errors.New("FAIL_TO_GET_COLLECTION")
+	errFailedToGetPriceHistory = errors.New("FAIL_TO_GET_PRICE_HISTORY")
+	errJobNotFound             = errors.New("JOB_NOT_FOUND")
+	errFailedToGetJobStatus    = errors.New("FAIL_TO_GET_JOB_STATUS")
+	errFailedToGetSwapVolume   = errors.New("FAIL_TO_GET_SWAP_VOLUME")
+	errUnknownToken            = errors.New("UNKNOWN_TOKEN")
+	errCoinAlreadyAdded        = errors.New("COIN_ALREADY_ADDED")
+	errInvalidCoins            = errors.New("INVALID_COINS")
+	errFailedToGetRankHistory  = errors.New("FAIL_TO_GET_RANK_HISTORY")
+	errTokenNotFound           = errors.New("TOKEN_NOT_FOUND")
+	errTokenAlreadyRegistered  = errors.New("TOKEN_ALREADY_REGISTERED")
+	errFailedToGetTokens       = errors.New("FAIL_TO_GET_TOKENS")
+	errFailedToSaveToken       = errors.New("FAIL_TO_SAVE_TOKEN")
+	errSeasonNotFound          = errors.New("SEASON_NOT_FOUND")
+	errSeasonAlreadyExists     = errors.New("SEASON_ALREADY_EXISTS")
+	errSeasonOverlaps          = errors.New("SEASON_OVERLAPS")
+	errSeasonStarted           = errors.New("SEASON_STARTED")
+	errFailedToGetSeasons      = errors.New("FAIL_TO_GET_SEASONS")
+	errFailedToSaveSeason      = errors.New("FAIL_TO_SAVE_SEASON")
+	errFailedToGetMilestones   = errors.New("FAIL_TO_GET_MILESTONES")
+	errInvalidReferralCode     = errors.New("INVALID_REFERRAL_CODE")
+	errAlreadyReferred         = errors.New("ALREADY_REFERRED")
+	errReferralWindowClosed    = errors.New("REFERRAL_WINDOW_CLOSED")
+	errFailedToGetReferrals    = errors.New("FAIL_TO_GET_REFERRALS")
+	errQuestNotFound           = errors.New("QUEST_NOT_FOUND")
+	errQuestAlreadyExists      = errors.New("QUEST_ALREADY_EXISTS")
+	errFailedToGetQuests       = errors.New("FAIL_TO_GET_QUESTS")
+	errFailedToSaveQuest       = errors.New("FAIL_TO_SAVE_QUEST")
+	errDiscoveryNotReady       = errors.New("DISCOVERY_NOT_READY")
 )
 
 func ErrorHandler() gin.HandlerFunc {
@@ -42,14 +70,30 @@ func ErrorHandler() gin.HandlerFunc {
 			switch {
 			case errors.Is(err, errInvalidRequest),
 				errors.Is(err, errVaultAlreadyRegist),
-				errors.Is(err, errLogoTooLarge):
+				errors.Is(err, errLogoTooLarge),
+				errors.Is(err, errTokenAlreadyRegistered),
+				errors.Is(err, errUnknownToken),
+				errors.Is(err, errCoinAlreadyAdded),
+				errors.Is(err, errSeasonAlreadyExists),
+				errors.Is(err, errSeasonOverlaps),
+				errors.Is(err, errSeasonStarted),
+				errors.Is(err, errInvalidReferralCode),
+				errors.Is(err, errAlreadyReferred),
+				errors.Is(err, errReferralWindowClosed),
+				errors.Is(err, errQuestAlreadyExists):
 				statusCode = http.StatusBadRequest
 			case errors.Is(err, errAddressNotMatch):
 				statusCode = http.StatusBadRequest
-			case errors.Is(err, errVaultNotFound):
+			case errors.Is(err, errVaultNotFound),
+				errors.Is(err, errJobNotFound),
+				errors.Is(err, errTokenNotFound),
+				errors.Is(err, errSeasonNotFound),
+				errors.Is(err, errQuestNotFound):
 				statusCode = http.StatusNotFound
 			case errors.Is(err, errForbiddenAccess):
 				statusCode = http.StatusForbidden
+			case errors.Is(err, errDiscoveryNotReady):
+				statusCode = http.StatusServiceUnavailable
 			case errors.Is(err, errFailedToRegisterVault),
 				errors.Is(err, errFailedToGetVault),
 				errors.Is(err, errFailedToDeleteVault),
@@ -63,7 +107,19 @@ func ErrorHandler() gin.HandlerFunc {
 				errors.Is(err, errFailedToDerivePublicKey),
 				errors.Is(err, errFailedToSetTheme),
 				errors.Is(err, errFailedToGetTheme),
-				errors.Is(err, errFailedToGetCollection):
+				errors.Is(err, errFailedToGetCollection),
+				errors.Is(err, errFailedToGetPriceHistory),
+				errors.Is(err, errFailedToGetJobStatus),
+				errors.Is(err, errFailedToGetSwapVolume),
+				errors.Is(err, errFailedToGetTokens),
+				errors.Is(err, errFailedToGetRankHistory),
+				errors.Is(err, errFailedToSaveToken),
+				errors.Is(err, errFailedToGetSeasons),
+				errors.Is(err, errFailedToSaveSeason),
+				errors.Is(err, errFailedToGetMilestones),
+				errors.Is(err, errFailedToGetReferrals),
+				errors.Is(err, errFailedToGetQuests),
+				errors.Is(err, errFailedToSaveQuest):
 				statusCode = http.StatusInternalServerError
 			default:
 				statusCode = http.StatusInternalServerError
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// getJobStatusHandler returns the latest job and which volume sources are lagging behind it
+func (a *Api) getJobStatusHandler(c *gin.Context) {
+	job, err := a.s.GetLastJob()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errJobNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get last job: %v", err)
+		_ = c.Error(errFailedToGetJobStatus)
+		return
+	}
+	checkpoints, err := a.s.GetVolumeCheckpoints()
+	if err != nil {
+		a.logger.Errorf("failed to get volume checkpoints: %v", err)
+		_ = c.Error(errFailedToGetJobStatus)
+		return
+	}
+	jobDate := time.Unix(models.GetDate(job.JobDate), 0)
+	status := models.JobStatus{
+		JobDate:         job.Date(),
+		IsSuccess:       job.IsSuccess,
+		IsVolumeFetched: job.IsVolumeFetched,
+		Sources:         make([]models.VolumeSourceStatus, 0, len(checkpoints)),
+	}
+	for _, checkpoint := range checkpoints {
+		status.Sources = append(status.Sources, models.VolumeSourceStatus{
+			Tracker:     checkpoint.Tracker,
+			Affiliate:   checkpoint.Affiliate,
+			LastFetched: checkpoint.LastFetched,
+			LastAttempt: checkpoint.LastAttempt,
+			HasError:    checkpoint.LastError != "",
+			IsLagging:   checkpoint.LastError != "" || checkpoint.LastFetched.Before(jobDate),
+		})
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// MaxLeaderboardAround is the maximum number of entries returned above and below a vault
+const MaxLeaderboardAround = 50
+
+const leaderboardCachePrefix = "leaderboard_snapshot_"
+
+// dropLeaderboardCache drops the cached leaderboard pages, a vault changing its name or leaving is shown without waiting
+// for the cache to expire
+func (a *Api) dropLeaderboardCache() {
+	for key := range a.cachedData.Items() {
+		if strings.HasPrefix(key, leaderboardCachePrefix) {
+			a.cachedData.Delete(key)
+		}
+	}
+}
+
+// getLeaderboardSnapshot returns a page of the current season leaderboard from the snapshot the worker writes after each job,
+// unfiltered pages are cached as the ranks only change once per job. It returns gorm.ErrRecordNotFound if no snapshot was written yet.
+func (a *Api) getLeaderboardSnapshot(from int64, limit int, filter models.LeaderboardFilter) (*models.VaultsResponse, error) {
+	cacheKey := fmt.Sprintf("%s%d_%d", leaderboardCachePrefix, from, limit)
+	if filter.IsEmpty() {
+		if cached, ok := a.cachedData.Get(cacheKey); ok {
+			if resp, ok := cached.(*models.VaultsResponse); ok {
+				return resp, nil
+			}
+		}
+	}
+	totals, err := a.s.GetLeaderboardSnapshotTotals()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := a.s.GetLeaderboardSnapshots(from, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := newLeaderboardResponse(totals, snapshots)
+	if len(snapshots) == limit && limit > 0 {
+		resp.NextCursor = encodeLeaderboardCursor(snapshots[len(snapshots)-1].Rank)
+	}
+	if filter.IsEmpty() {
+		a.cachedData.Set(cacheKey, resp, cache.DefaultExpiration)
+	}
+	return resp, nil
+}
+
+// getLeaderboardAroundHandler returns the leaderboard entries ranked up to n places above and below the vault of the given uid
+func (a *Api) getLeaderboardAroundHandler(c *gin.Context) {
+	uid := c.Param("uid")
+	n, err := strconv.ParseInt(c.DefaultQuery("n", "5"), 10, 64)
+	if err != nil || n < 0 || uid == "" {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if n > MaxLeaderboardAround {
+		n = MaxLeaderboardAround
+	}
+	totals, err := a.s.GetLeaderboardSnapshotTotals()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get leaderboard snapshot totals: %v", err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	snapshots, err := a.s.GetLeaderboardSnapshotsAround(uid, n)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get leaderboard snapshots around %s: %v", uid, err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	c.JSON(http.StatusOK, newLeaderboardResponse(totals, snapshots))
+}
+
+// parseLeaderboardFilter reads the q, min_points and chain query parameters
+func parseLeaderboardFilter(c *gin.Context) (models.LeaderboardFilter, error) {
+	filter := models.LeaderboardFilter{
+		AliasPrefix: strings.TrimSpace(c.Query("q")),
+	}
+	if minPoints := c.Query("min_points"); minPoints != "" {
+		points, err := strconv.ParseFloat(minPoints, 64)
+		if err != nil || points < 0 {
+			return filter, errInvalidRequest
+		}
+		filter.MinPoints = points
+	}
+	if chainName := c.Query("chain"); chainName != "" {
+		chain, err := common.ChainFromString(chainName)
+		if err != nil {
+			return filter, errInvalidRequest
+		}
+		filter.Chain = &chain
+	}
+	return filter, nil
+}
+
+// encodeLeaderboardCursor returns the opaque cursor of the page after the given rank
+func encodeLeaderboardCursor(rank int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(rank, 10)))
+}
+
+// decodeLeaderboardCursor returns the rank the page of the cursor starts after
+func decodeLeaderboardCursor(cursor string) (int64, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	rank, err := strconv.ParseInt(string(buf), 10, 64)
+	if err != nil || rank < 0 {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	return rank, nil
+}
+
+func newLeaderboardResponse(totals *models.LeaderboardSnapshot, snapshots []models.LeaderboardSnapshot) *models.VaultsResponse {
+	resp := &models.VaultsResponse{
+		Vaults:          make([]models.VaultResponse, 0, len(snapshots)),
+		TotalVaultCount: totals.VaultCount,
+		TotalBalance:    totals.Balance,
+		TotalLP:         totals.LPValue,
+		TotalNFT:        totals.NFTValue,
+	}
+	for _, snapshot := range snapshots {
+		resp.Vaults = append(resp.Vaults, newLeaderboardVaultResponse(snapshot))
+	}
+	return resp
+}
+
+func newLeaderboardVaultResponse(snapshot models.LeaderboardSnapshot) models.VaultResponse {
+	vaultName := models.LeaderboardName(snapshot.Alias, snapshot.Uid, snapshot.ShowNameInLeaderboard)
+	return models.VaultResponse{
+		Name:         vaultName,
+		Alias:        vaultName,
+		TotalPoints:  snapshot.TotalPoints,
+		Rank:         snapshot.Rank,
+		RankDelta:    snapshot.RankDelta(),
+		Balance:      snapshot.Balance,
+		LPValue:      snapshot.LPValue,
+		NFTValue:     snapshot.NFTValue,
+		RegisteredAt: snapshot.RegisteredAt,
+		AvatarURL:    snapshot.AvatarURL,
+	}
+}
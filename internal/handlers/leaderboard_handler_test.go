@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestLeaderboardCursor(t *testing.T) {
+	for _, rank := range []int64{0, 1, 250, 1 << 40} {
+		decoded, err := decodeLeaderboardCursor(encodeLeaderboardCursor(rank))
+		assert.NoError(t, err)
+		assert.Equal(t, rank, decoded)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!"},
+		{name: "not a number", cursor: "YWJj"},  // abc
+		{name: "negative rank", cursor: "LTE"},  // -1
+		{name: "padded base64", cursor: "MTA="}, // 10, cursors are unpadded
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeLeaderboardCursor(tt.cursor)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseLeaderboardFilter(t *testing.T) {
+	ethereum := common.Ethereum
+	tests := []struct {
+		name     string
+		query    string
+		expected models.LeaderboardFilter
+		hasError bool
+	}{
+		{name: "no filter", query: "", expected: models.LeaderboardFilter{}},
+		{name: "alias prefix is trimmed", query: "q=%20alice%20", expected: models.LeaderboardFilter{AliasPrefix: "alice"}},
+		{name: "min points", query: "min_points=1500.5", expected: models.LeaderboardFilter{MinPoints: 1500.5}},
+		{name: "chain", query: "chain=Ethereum", expected: models.LeaderboardFilter{Chain: &ethereum}},
+		{name: "all filters", query: "q=bob&min_points=10&chain=Ethereum", expected: models.LeaderboardFilter{AliasPrefix: "bob", MinPoints: 10, Chain: &ethereum}},
+		{name: "invalid min points", query: "min_points=abc", hasError: true},
+		{name: "negative min points", query: "min_points=-1", hasError: true},
+		{name: "unknown chain", query: "chain=Unknown", hasError: true},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/vaults?"+tt.query, nil)
+			filter, err := parseLeaderboardFilter(c)
+			if tt.hasError {
+				assert.ErrorIs(t, err, errInvalidRequest)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+const MaxPriceHistorySize = 1000
+
+// getPriceHistoryHandler returns the prices recorded by past jobs for a CMC id, used to audit point disputes
+func (a *Api) getPriceHistoryHandler(c *gin.Context) {
+	cmcID, err := strconv.Atoi(c.Param("cmcId"))
+	if err != nil || cmcID <= 0 {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	from, to, ok := parsePriceHistoryRange(c)
+	if !ok {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	prices, err := a.s.GetPriceHistory(cmcID, from, to, MaxPriceHistorySize)
+	if err != nil {
+		a.logger.Errorf("failed to get price history: %v", err)
+		_ = c.Error(errFailedToGetPriceHistory)
+		return
+	}
+	c.JSON(http.StatusOK, prices)
+}
+
+// getAssetPriceHistoryHandler returns the prices recorded by the pricing rules of past jobs for an asset, it audits
+// the assets priced without a CMC id
+func (a *Api) getAssetPriceHistoryHandler(c *gin.Context) {
+	chain, err := common.ChainFromString(c.Query("chain"))
+	if err != nil {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	ticker := c.Query("ticker")
+	contractAddress := c.Query("contract_address")
+	if ticker == "" && contractAddress == "" {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	from, to, ok := parsePriceHistoryRange(c)
+	if !ok {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	prices, err := a.s.GetAssetPriceHistory(chain, ticker, contractAddress, from, to, MaxPriceHistorySize)
+	if err != nil {
+		a.logger.Errorf("failed to get asset price history: %v", err)
+		_ = c.Error(errFailedToGetPriceHistory)
+		return
+	}
+	c.JSON(http.StatusOK, prices)
+}
+
+// parsePriceHistoryRange returns the from and to unix timestamps of the request, the last 30 days by default
+func parsePriceHistoryRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	fromStr := c.DefaultQuery("from", strconv.FormatInt(now.AddDate(0, 0, -30).Unix(), 10))
+	toStr := c.DefaultQuery("to", strconv.FormatInt(now.Unix(), 10))
+	from, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := strconv.ParseInt(toStr, 10, 64)
+	if err != nil || to < from {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.Unix(from, 0), time.Unix(to, 0), true
+}
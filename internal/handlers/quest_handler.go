@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// cmcQuestID is the quest verified by the legacy coinmarketcap endpoint
+const cmcQuestID = "cmc"
+
+func (a *Api) verifyQuestHandler(c *gin.Context) {
+	a.verifyQuest(c, c.Param("questId"))
+}
+
+// verifyCoinMarketCapQuest keeps the endpoint coinmarketcap was given before quests were generic
+func (a *Api) verifyCoinMarketCapQuest(c *gin.Context) {
+	a.verifyQuest(c, cmcQuestID)
+}
+
+// verifyQuest tells the quest partner if the vault owning the eth address completed the quest, the first successful
+// verification is recorded and rewards the vault with the quest points
+func (a *Api) verifyQuest(c *gin.Context, questID string) {
+	address := c.Query("address")
+	if address == "" {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	quest, err := a.s.GetQuest(questID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errQuestNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get quest: %v", err)
+		_ = c.Error(errFailedToGetQuests)
+		return
+	}
+	if !quest.Enabled {
+		_ = c.Error(errQuestNotFound)
+		return
+	}
+	if !a.isPartnerRequest(c, quest.Partner) {
+		_ = c.Error(errForbiddenAccess)
+		return
+	}
+	// the partner may have been opened after the quest was stored, anyone can verify its quests then
+	if partner, _ := a.cfg.GetQuestPartner(quest.Partner); partner.IsOpen() && quest.Points > 0 {
+		a.logger.Warnf("quest %s of open partner %s rewards points, the completion is recorded without points", quest.QuestID, quest.Partner)
+		quest.Points = 0
+	}
+
+	var result models.QuestVerifyResponse
+	vaultID, ok := a.questService.VaultID(address)
+	if !ok {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	vault, err := a.s.GetVaultByID(vaultID)
+	if err != nil {
+		a.logger.Errorf("failed to get vault: %v", err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	hasChainAddress := false
+	if quest.Criteria == models.QuestCriteriaChainAddress {
+		chain, err := common.ChainFromString(quest.Chain)
+		if err != nil {
+			a.logger.Errorf("quest %s has an invalid chain %s", quest.QuestID, quest.Chain)
+			_ = c.Error(errFailedToGetQuests)
+			return
+		}
+		if hasChainAddress, err = a.s.VaultHasChainAddress(vault.ID, chain); err != nil {
+			a.logger.Errorf("failed to check vault chain address: %v", err)
+			_ = c.Error(errFailedToGetCoin)
+			return
+		}
+	}
+	if quest.IsCompletedBy(vault, hasChainAddress) {
+		if _, err := a.s.CompleteQuest(quest, vault.ID, a.seasonRegistry.Current().ID, address); err != nil {
+			a.logger.Errorf("failed to complete quest: %v", err)
+			_ = c.Error(errFailedToSaveQuest)
+			return
+		}
+		result.Result.IsValid = true
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// isPartnerRequest returns true if the request passes the checks of the quest partner, the client ip is the remote
+// address unless the request comes from a trusted proxy
+func (a *Api) isPartnerRequest(c *gin.Context, partnerName string) bool {
+	partner, ok := a.cfg.GetQuestPartner(partnerName)
+	return ok && partner.Allows(c.GetHeader("x-partner-api-key"), c.ClientIP())
+}
+
+func (a *Api) getQuestsHandler(c *gin.Context) {
+	quests, err := a.s.GetQuests()
+	if err != nil {
+		a.logger.Errorf("failed to get quests: %v", err)
+		_ = c.Error(errFailedToGetQuests)
+		return
+	}
+	c.JSON(http.StatusOK, quests)
+}
+
+func (a *Api) createQuestHandler(c *gin.Context) {
+	var quest models.Quest
+	if err := c.ShouldBindJSON(&quest); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	quest.Model = gorm.Model{}
+	if !a.validateQuest(&quest) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if _, err := a.s.GetQuest(quest.QuestID); err == nil {
+		_ = c.Error(errQuestAlreadyExists)
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Errorf("failed to get quest: %v", err)
+		_ = c.Error(errFailedToGetQuests)
+		return
+	}
+	if err := a.s.CreateQuest(&quest); err != nil {
+		a.logger.Errorf("failed to create quest: %v", err)
+		_ = c.Error(errFailedToSaveQuest)
+		return
+	}
+	c.JSON(http.StatusOK, quest)
+}
+
+func (a *Api) updateQuestHandler(c *gin.Context) {
+	existing, err := a.getQuestByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	var quest models.Quest
+	if err := c.ShouldBindJSON(&quest); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	// the quest id is referenced by the completions and the partner, it can't be changed
+	quest.Model = existing.Model
+	quest.QuestID = existing.QuestID
+	if !a.validateQuest(&quest) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if err := a.s.UpdateQuest(&quest); err != nil {
+		a.logger.Errorf("failed to update quest: %v", err)
+		_ = c.Error(errFailedToSaveQuest)
+		return
+	}
+	c.JSON(http.StatusOK, quest)
+}
+
+func (a *Api) deleteQuestHandler(c *gin.Context) {
+	quest, err := a.getQuestByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := a.s.DeleteQuest(quest.QuestID); err != nil {
+		a.logger.Errorf("failed to delete quest: %v", err)
+		_ = c.Error(errFailedToSaveQuest)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (a *Api) getQuestByParam(c *gin.Context) (*models.Quest, error) {
+	quest, err := a.s.GetQuest(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errQuestNotFound
+		}
+		a.logger.Errorf("failed to get quest: %v", err)
+		return nil, errFailedToGetQuests
+	}
+	return quest, nil
+}
+
+// validateQuest checks the quest fields, the partner must be one of the quest partners of the config file
+func (a *Api) validateQuest(quest *models.Quest) bool {
+	partner, ok := a.cfg.GetQuestPartner(quest.Partner)
+	return ok && quest.IsValid(!partner.IsOpen())
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestIsPartnerRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	partners := []config.QuestPartner{{Name: "partner", AllowedIPs: []string{"203.0.113.7"}}}
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		expected       int
+	}{
+		{name: "allowed remote address", remoteAddr: "203.0.113.7:1234", expected: http.StatusOK},
+		{name: "spoofed forwarded ip", remoteAddr: "198.51.100.1:1234", forwardedFor: "203.0.113.7", expected: http.StatusForbidden},
+		{name: "forwarded ip of a trusted proxy", trustedProxies: []string{"198.51.100.1"}, remoteAddr: "198.51.100.1:1234", forwardedFor: "203.0.113.7", expected: http.StatusOK},
+		{name: "other forwarded ip of a trusted proxy", trustedProxies: []string{"198.51.100.1"}, remoteAddr: "198.51.100.1:1234", forwardedFor: "192.0.2.1", expected: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{QuestPartners: partners}
+			cfg.Server.TrustedProxies = tt.trustedProxies
+			router, err := newRouter(cfg)
+			assert.NoError(t, err)
+			a := &Api{cfg: cfg, router: router}
+			router.GET("/verify", func(c *gin.Context) {
+				if !a.isPartnerRequest(c, "partner") {
+					c.Status(http.StatusForbidden)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/verify", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestValidateQuest(t *testing.T) {
+	a := &Api{cfg: &config.Config{QuestPartners: []config.QuestPartner{
+		{Name: "open"},
+		{Name: "keyed", APIKey: "secret"},
+	}}}
+	tests := []struct {
+		name     string
+		quest    models.Quest
+		expected bool
+	}{
+		{name: "open partner without points", quest: models.Quest{QuestID: "q", Partner: "open", Criteria: models.QuestCriteriaHoldsVault}, expected: true},
+		{name: "open partner with points", quest: models.Quest{QuestID: "q", Partner: "open", Criteria: models.QuestCriteriaHoldsVault, Points: 100}, expected: false},
+		{name: "keyed partner with points", quest: models.Quest{QuestID: "q", Partner: "keyed", Criteria: models.QuestCriteriaHoldsVault, Points: 100}, expected: true},
+		{name: "unknown partner", quest: models.Quest{QuestID: "q", Partner: "unknown", Criteria: models.QuestCriteriaHoldsVault}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, a.validateQuest(&tt.quest))
+		})
+	}
+}
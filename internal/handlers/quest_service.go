@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -51,7 +52,7 @@ func (q *QuestService) initialize() error {
 		for _, coin := range coins {
 			for _, evmChain := range common.EVMChains {
 				if coin.Chain == evmChain {
-					q.ethAddressStore[coin.Address] = coin.VaultID
+					q.ethAddressStore[strings.ToLower(coin.Address)] = coin.VaultID
 					break
 				}
 			}
@@ -84,18 +85,19 @@ func (q *QuestService) initialize() error {
 					q.logger.Errorf("failed to get eth address for vault %d: %v", vault.ID, err)
 					continue
 				}
-				q.ethAddressStore[ethAddress] = vault.ID
+				q.ethAddressStore[strings.ToLower(ethAddress)] = vault.ID
 			}
 		}
 	}
 	return nil
 }
 
-func (q *QuestService) Exists(ethAddress string) bool {
+// VaultID returns the id of the vault owning the eth address, eth addresses are case-insensitive
+func (q *QuestService) VaultID(ethAddress string) (uint, bool) {
 	q.RLock()
 	defer q.RUnlock()
-	_, exists := q.ethAddressStore[ethAddress]
-	return exists
+	vaultID, exists := q.ethAddressStore[strings.ToLower(ethAddress)]
+	return vaultID, exists
 }
 
 func (q *QuestService) Add(vault models.Vault) {
@@ -103,7 +105,7 @@ func (q *QuestService) Add(vault models.Vault) {
 	defer q.Unlock()
 	ethAddress, err := vault.GetAddress(common.Ethereum)
 	if err == nil {
-		q.ethAddressStore[ethAddress] = vault.ID
+		q.ethAddressStore[strings.ToLower(ethAddress)] = vault.ID
 	}
 }
 
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReferralTop   = 20
+	defaultReferralDepth = 2
+	maxReferralDepth     = 5
+)
+
+// getReferralAnalyticsHandler returns the referral conversion, the top referrers and the suspicious referrers
+func (a *Api) getReferralAnalyticsHandler(c *gin.Context) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultReferralTop)))
+	if err != nil || top < 0 {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if top > MaxPageSize {
+		top = MaxPageSize
+	}
+	analytics, err := a.s.GetReferralAnalytics(top)
+	if err != nil {
+		a.logger.Errorf("failed to get referral analytics: %v", err)
+		_ = c.Error(errFailedToGetReferrals)
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
+
+// getReferralTreeHandler returns the vaults referred by a vault, and the vaults they referred, down to depth levels
+func (a *Api) getReferralTreeHandler(c *gin.Context) {
+	uid := c.Param("uid")
+	if uid == "" {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(defaultReferralDepth)))
+	if err != nil || depth < 1 {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if depth > maxReferralDepth {
+		depth = maxReferralDepth
+	}
+	vault, err := a.s.GetVaultByUID(uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get vault: %v", err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	tree, err := a.s.GetReferralTree(vault, depth)
+	if err != nil {
+		a.logger.Errorf("failed to get referral tree: %v", err)
+		_ = c.Error(errFailedToGetReferrals)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
 	"github.com/vultisig/airdrop-registry/internal/utils"
 )
 
 func (a *Api) getAllSeasonInfo(c *gin.Context) {
-	allSeasons := a.cfg.Seasons
+	allSeasons := a.seasonRegistry.All()
 	c.JSON(http.StatusOK, allSeasons)
 }
 
@@ -16,7 +23,21 @@ type SeasonPoints struct {
 	Points float64 `json:"points"`
 }
 
+// getTotalPointsBySeasonHandler returns the points of all vaults with their referral and swap volume boosts.
+// Seasons with a referral scheme already credit referrals to the points of the referrers (ApplyReferralRewards),
+// the referral count multiplier is only applied to seasons without one.
 func (a *Api) getTotalPointsBySeasonHandler(c *gin.Context) {
+	seasonID, err := strconv.ParseUint(c.Param("seasonID"), 10, 64)
+	if err != nil {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	season, ok := a.seasonRegistry.Get(uint(seasonID))
+	if !ok {
+		_ = c.Error(errSeasonNotFound)
+		return
+	}
+	referralRewarded := season.Referral.IsEnabled()
 	startId := uint(0)
 	totalPoints := 0.0
 	for {
@@ -30,7 +51,10 @@ func (a *Api) getTotalPointsBySeasonHandler(c *gin.Context) {
 			break
 		}
 		for _, vault := range allVaults {
-			referralMultiplier := utils.GetReferralMultiplier(vault.ReferralCount)
+			referralMultiplier := 1.0
+			if !referralRewarded {
+				referralMultiplier = utils.GetReferralMultiplier(vault.ReferralCount)
+			}
 			swapMultiplier := utils.GetSwapVolumeMultiplier(vault.SwapVolume)
 			totalPoints += vault.TotalPoints * referralMultiplier * swapMultiplier
 			startId = vault.ID
@@ -43,3 +67,204 @@ func (a *Api) getTotalPointsBySeasonHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, points)
 }
+
+func (a *Api) getSeasonsHandler(c *gin.Context) {
+	seasonList, err := a.s.GetSeasons()
+	if err != nil {
+		a.logger.Errorf("failed to get seasons: %v", err)
+		_ = c.Error(errFailedToGetSeasons)
+		return
+	}
+	c.JSON(http.StatusOK, seasonList)
+}
+
+func (a *Api) createSeasonHandler(c *gin.Context) {
+	var season models.Season
+	if err := c.ShouldBindJSON(&season); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	season.Model = gorm.Model{}
+	if !validateSeason(&season) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	// the registry can lag behind the seasons table, the database decides whether the season exists
+	if _, err := a.s.GetSeason(season.SeasonID); err == nil {
+		_ = c.Error(errSeasonAlreadyExists)
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Errorf("failed to get season: %v", err)
+		_ = c.Error(errFailedToGetSeasons)
+		return
+	}
+	if a.overlapsSeason(season) {
+		_ = c.Error(errSeasonOverlaps)
+		return
+	}
+	if err := a.s.CreateSeason(&season); err != nil {
+		a.logger.Errorf("failed to create season: %v", err)
+		_ = c.Error(errFailedToSaveSeason)
+		return
+	}
+	a.reloadSeasonRegistry()
+	c.JSON(http.StatusOK, season)
+}
+
+func (a *Api) updateSeasonHandler(c *gin.Context) {
+	existing, err := a.getSeasonByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	var season models.Season
+	if err := c.ShouldBindJSON(&season); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	// the season id is referenced by vaults and season stats, it can't be changed
+	season.Model = existing.Model
+	season.SeasonID = existing.SeasonID
+	if !validateSeason(&season) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if season.Start != existing.Start || season.End != existing.End || !milestonesAppended(existing.Milestones, season.Milestones) {
+		if err := a.checkSeasonNotStarted(existing); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	if a.overlapsSeason(season) {
+		_ = c.Error(errSeasonOverlaps)
+		return
+	}
+	if err := a.s.UpdateSeason(&season); err != nil {
+		a.logger.Errorf("failed to update season: %v", err)
+		_ = c.Error(errFailedToSaveSeason)
+		return
+	}
+	a.reloadSeasonRegistry()
+	c.JSON(http.StatusOK, season)
+}
+
+func (a *Api) deleteSeasonHandler(c *gin.Context) {
+	season, err := a.getSeasonByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := a.checkSeasonNotStarted(season); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := a.s.DeleteSeason(season.SeasonID); err != nil {
+		a.logger.Errorf("failed to delete season: %v", err)
+		_ = c.Error(errFailedToSaveSeason)
+		return
+	}
+	a.reloadSeasonRegistry()
+	c.Status(http.StatusOK)
+}
+
+func (a *Api) getSeasonByParam(c *gin.Context) (*models.Season, error) {
+	seasonID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, errInvalidRequest
+	}
+	season, err := a.s.GetSeason(uint(seasonID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errSeasonNotFound
+		}
+		a.logger.Errorf("failed to get season: %v", err)
+		return nil, errFailedToGetSeasons
+	}
+	return season, nil
+}
+
+// checkSeasonNotStarted returns errSeasonStarted if the season started or was closed, the dates of such a season
+// are referenced by the points, swaps and stats recorded for it
+func (a *Api) checkSeasonNotStarted(season *models.Season) error {
+	if time.Now().Unix() >= season.Start {
+		return errSeasonStarted
+	}
+	if _, err := a.s.GetSeasonClosure(season.SeasonID); err == nil {
+		return errSeasonStarted
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Errorf("failed to get season closure: %v", err)
+		return errFailedToGetSeasons
+	}
+	return nil
+}
+
+// overlapsSeason reports whether season runs at the same time as another season
+func (a *Api) overlapsSeason(season models.Season) bool {
+	for _, other := range a.seasonRegistry.All() {
+		if other.ID != season.SeasonID && season.Start < other.End.Unix() && other.Start.Unix() < season.End {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadSeasonRegistry refreshes the seasons served by the api, the worker picks up the changes through its season watcher
+func (a *Api) reloadSeasonRegistry() {
+	if _, err := a.s.RefreshSeasonRegistry(a.seasonRegistry); err != nil {
+		a.logger.Errorf("failed to reload season registry: %v", err)
+	}
+}
+
+// milestonesAppended reports whether milestones keeps the minimums of the existing milestones at their positions.
+// Vaults reference the milestones of a started season by position, so it only allows new milestones and prize changes.
+func milestonesAppended(existing, milestones []models.SeasonMilestone) bool {
+	if len(milestones) < len(existing) {
+		return false
+	}
+	for i := range existing {
+		if milestones[i].Minimum != existing[i].Minimum {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSeason checks the season fields, milestones must have increasing minimums and are numbered in the given order
+func validateSeason(season *models.Season) bool {
+	// season 0 stands for no season, e.g. between seasons
+	if season.SeasonID == 0 || season.Start <= 0 || season.End <= season.Start {
+		return false
+	}
+	referral := season.Referral
+	if referral.DirectShare < 0 || referral.DirectShare > 1 || referral.SecondLevelShare < 0 || referral.SecondLevelShare > 1 || referral.MaxPointsPerJob < 0 {
+		return false
+	}
+	for i := range season.Milestones {
+		milestone := &season.Milestones[i]
+		if milestone.Minimum <= 0 || milestone.Prize < 0 {
+			return false
+		}
+		if i > 0 && milestone.Minimum <= season.Milestones[i-1].Minimum {
+			return false
+		}
+		milestone.SeasonID = season.SeasonID
+		milestone.Position = i
+	}
+	for i := range season.NFTs {
+		nft := &season.NFTs[i]
+		if _, err := common.ChainFromString(nft.Chain); err != nil || nft.ContractAddress == "" || nft.Multiplier <= 0 {
+			return false
+		}
+		nft.SeasonID = season.SeasonID
+	}
+	for i := range season.Tokens {
+		token := &season.Tokens[i]
+		if _, err := common.ChainFromString(token.Chain); err != nil || token.Name == "" || token.Multiplier <= 0 {
+			return false
+		}
+		token.SeasonID = season.SeasonID
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestMilestonesAppended(t *testing.T) {
+	existing := []models.SeasonMilestone{{Minimum: 1000, Prize: 100}, {Minimum: 5000, Prize: 500}}
+
+	assert.True(t, milestonesAppended(existing, []models.SeasonMilestone{{Minimum: 1000, Prize: 150}, {Minimum: 5000, Prize: 500}}))
+	assert.True(t, milestonesAppended(existing, []models.SeasonMilestone{{Minimum: 1000, Prize: 100}, {Minimum: 5000, Prize: 500}, {Minimum: 9000, Prize: 900}}))
+	// removing, reordering or moving a milestone shifts the positions vaults reference
+	assert.False(t, milestonesAppended(existing, []models.SeasonMilestone{{Minimum: 1000, Prize: 100}}))
+	assert.False(t, milestonesAppended(existing, []models.SeasonMilestone{{Minimum: 500, Prize: 50}, {Minimum: 1000, Prize: 100}, {Minimum: 5000, Prize: 500}}))
+	assert.False(t, milestonesAppended(existing, []models.SeasonMilestone{{Minimum: 1000, Prize: 100}, {Minimum: 6000, Prize: 500}}))
+}
+
+func TestValidateSeason(t *testing.T) {
+	season := models.Season{
+		SeasonID:   2,
+		Start:      1735689600,
+		End:        1743465600,
+		Milestones: []models.SeasonMilestone{{Minimum: 1000, Prize: 100}, {Minimum: 5000, Prize: 500}},
+	}
+	assert.True(t, validateSeason(&season))
+	assert.Equal(t, uint(2), season.Milestones[1].SeasonID)
+	assert.Equal(t, 1, season.Milestones[1].Position)
+
+	// season 0 means no season
+	season.SeasonID = 0
+	assert.False(t, validateSeason(&season))
+	season.SeasonID = 2
+	season.End = season.Start
+	assert.False(t, validateSeason(&season))
+}
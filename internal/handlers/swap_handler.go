@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getSwapVolumeByAssetHandler returns the current season swap volume broken down by pool
+func (a *Api) getSwapVolumeByAssetHandler(c *gin.Context) {
+	volumes, err := a.s.GetSwapVolumeByAsset(a.seasonRegistry.Current().Start)
+	if err != nil {
+		a.logger.Errorf("failed to get swap volume by asset: %v", err)
+		_ = c.Error(errFailedToGetSwapVolume)
+		return
+	}
+	c.JSON(http.StatusOK, volumes)
+}
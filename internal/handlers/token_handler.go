@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// adminAuth rejects requests without the configured admin api key, the admin endpoints are disabled when no key is configured
+func (a *Api) adminAuth(c *gin.Context) {
+	apiKey := c.GetHeader("x-admin-api-key")
+	if a.cfg.Admin.APIKey == "" || subtle.ConstantTimeCompare([]byte(apiKey), []byte(a.cfg.Admin.APIKey)) != 1 {
+		_ = c.Error(errForbiddenAccess)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func (a *Api) getTokensHandler(c *gin.Context) {
+	tokenList, err := a.s.GetTokens()
+	if err != nil {
+		a.logger.Errorf("failed to get tokens: %v", err)
+		_ = c.Error(errFailedToGetTokens)
+		return
+	}
+	c.JSON(http.StatusOK, tokenList)
+}
+
+func (a *Api) createTokenHandler(c *gin.Context) {
+	// the multiplier is 1 unless the request sets it, an explicit 0 is stored as is
+	token := models.Token{Multiplier: 1}
+	if err := c.ShouldBindJSON(&token); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	token.Model = gorm.Model{}
+	if !validateToken(&token) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if _, ok := a.findRegisteredToken(token); ok {
+		_ = c.Error(errTokenAlreadyRegistered)
+		return
+	}
+	if err := a.s.CreateToken(&token); err != nil {
+		a.logger.Errorf("failed to create token: %v", err)
+		_ = c.Error(errFailedToSaveToken)
+		return
+	}
+	a.reloadTokenRegistry()
+	c.JSON(http.StatusOK, token)
+}
+
+func (a *Api) updateTokenHandler(c *gin.Context) {
+	existing, err := a.getTokenByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	var token models.Token
+	if err := c.ShouldBindJSON(&token); err != nil {
+		a.logger.Errorf("failed to bind json: %v", err)
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	token.Model = existing.Model
+	if !validateToken(&token) {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	if registered, ok := a.findRegisteredToken(token); ok && registered.ID != token.ID {
+		_ = c.Error(errTokenAlreadyRegistered)
+		return
+	}
+	if err := a.s.UpdateToken(&token); err != nil {
+		a.logger.Errorf("failed to update token: %v", err)
+		_ = c.Error(errFailedToSaveToken)
+		return
+	}
+	a.reloadTokenRegistry()
+	c.JSON(http.StatusOK, token)
+}
+
+func (a *Api) deleteTokenHandler(c *gin.Context) {
+	token, err := a.getTokenByParam(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := a.s.DeleteToken(token.ID); err != nil {
+		a.logger.Errorf("failed to delete token: %v", err)
+		_ = c.Error(errFailedToSaveToken)
+		return
+	}
+	a.reloadTokenRegistry()
+	c.Status(http.StatusOK)
+}
+
+func (a *Api) getTokenByParam(c *gin.Context) (*models.Token, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, errInvalidRequest
+	}
+	token, err := a.s.GetToken(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errTokenNotFound
+		}
+		a.logger.Errorf("failed to get token: %v", err)
+		return nil, errFailedToGetTokens
+	}
+	return token, nil
+}
+
+// reloadTokenRegistry refreshes the registry used by discovery, the worker picks up the changes at its next job
+func (a *Api) reloadTokenRegistry() {
+	if err := a.s.LoadTokenRegistry(a.tokenRegistry); err != nil {
+		a.logger.Errorf("failed to reload token registry: %v", err)
+	}
+}
+
+// validateToken checks the token fields, a token without type is fungible
+func validateToken(token *models.Token) bool {
+	if token.Type == "" {
+		token.Type = models.TokenTypeFungible
+	}
+	switch token.Type {
+	case models.TokenTypeFungible:
+		return token.Decimals >= 0 && token.Multiplier >= 0
+	case models.TokenTypeNFT:
+		return token.ContractAddress != "" && token.CollectionSlug != "" && token.Multiplier >= 0
+	default:
+		return false
+	}
+}
+
+// findRegisteredToken returns the registry entry token collides with, coins without contract only collide on ticker
+func (a *Api) findRegisteredToken(token models.Token) (models.Token, bool) {
+	registered, ok := a.tokenRegistry.Find(token.Chain, token.ContractAddress, token.Ticker)
+	if !ok || (token.ContractAddress == "" && !strings.EqualFold(registered.Ticker, token.Ticker)) {
+		return models.Token{}, false
+	}
+	return registered, true
+}
@@ -3,11 +3,15 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
+	"github.com/vultisig/airdrop-registry/config"
 	"github.com/vultisig/airdrop-registry/internal/models"
 )
 
@@ -25,6 +29,22 @@ func (a *Api) registerVaultHandler(c *gin.Context) {
 		_ = c.Error(errVaultAlreadyRegist)
 		return
 	}
+	var referrer *models.Vault
+	if vault.ReferralCode != "" {
+		var err error
+		if referrer, err = a.getReferrer(vault.ReferralCode); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	// a vault registered between seasons or after an early season close joins the next season, a vault left in a
+	// closed season or with season 0 would stop the worker until the season is closed again
+	closed, err := a.s.GetClosedSeasonIDs()
+	if err != nil {
+		a.logger.Error(err)
+		_ = c.Error(errFailedToRegisterVault)
+		return
+	}
 	vaultModel := models.Vault{
 		Name:            vault.Name,
 		Alias:           vault.Name,
@@ -34,7 +54,8 @@ func (a *Api) registerVaultHandler(c *gin.Context) {
 		HexChainCode:    vault.HexChainCode,
 		TotalPoints:     0,
 		JoinAirdrop:     false,
-		CurrentSeasonID: a.cfg.GetCurrentSeason().ID,
+		CurrentSeasonID: a.seasonRegistry.Open(closed).ID,
+		ReferralCode:    vault.ReferralCode,
 	}
 
 	if err := a.s.RegisterVault(&vaultModel); err != nil {
@@ -46,6 +67,15 @@ func (a *Api) registerVaultHandler(c *gin.Context) {
 		_ = c.Error(errFailedToRegisterVault)
 		return
 	}
+	if referrer != nil {
+		if _, err := a.s.CreateVaultReferral(referrer.ID, vaultModel.ID, models.ReferralSourceRegistry); err != nil {
+			a.logger.Errorf("failed to record referral of vault %d: %v", vaultModel.ID, err)
+		}
+	}
+	// the code is generated on first use otherwise, a failure here isn't fatal
+	if _, err := a.s.GetOrCreateReferralCode(vaultModel.ID); err != nil {
+		a.logger.Errorf("failed to create referral code of vault %d: %v", vaultModel.ID, err)
+	}
 	a.questService.Add(vaultModel)
 	c.Status(http.StatusCreated)
 }
@@ -78,6 +108,7 @@ func (a *Api) getVaultHandler(c *gin.Context) {
 		LPValue:               vault.LPValue,
 		NFTValue:              vault.NFTValue,
 		SwapVolume:            vault.SwapVolume,
+		SwapVolumeRank:        vault.SwapVolumeRank,
 		RegisteredAt:          vault.Model.CreatedAt.UTC().Unix(),
 		Coins:                 []models.ChainCoins{},
 		AvatarURL:             vault.AvatarURL,
@@ -103,12 +134,14 @@ func (a *Api) getVaultHandler(c *gin.Context) {
 		}
 	}
 	vaultResp.SeasonActivities = make([]models.SeasonStats, 0)
-	for _, season := range a.cfg.Seasons {
+	for _, season := range a.seasonRegistry.All() {
 		if season.ID == vault.CurrentSeasonID {
 			vaultResp.SeasonActivities = append(vaultResp.SeasonActivities, models.SeasonStats{
-				SeasonID: season.ID,
-				Rank:     vault.Rank,
-				Points:   vault.TotalPoints,
+				SeasonID:       season.ID,
+				Rank:           vault.Rank,
+				Points:         vault.TotalPoints,
+				SwapVolume:     vault.SwapVolume,
+				SwapVolumeRank: vault.SwapVolumeRank,
 			})
 		} else {
 			totalSeasonPoints, err := a.s.GetLeaderVaultTotalPointsBySeason(season.ID)
@@ -129,12 +162,21 @@ func (a *Api) getVaultHandler(c *gin.Context) {
 				return
 			}
 			vaultResp.SeasonActivities = append(vaultResp.SeasonActivities, models.SeasonStats{
-				SeasonID: season.ID,
-				Rank:     seasonStats.Rank,
-				Points:   (seasonStats.Points / totalSeasonPoints) * totalAirdropPoints,
+				SeasonID:       season.ID,
+				Rank:           seasonStats.Rank,
+				Points:         (seasonStats.Points / totalSeasonPoints) * totalAirdropPoints,
+				SwapVolume:     seasonStats.SwapVolume,
+				SwapVolumeRank: seasonStats.SwapVolumeRank,
 			})
 		}
 	}
+	badges, err := a.s.GetVaultBadges(vault.ID)
+	if err != nil {
+		a.logger.Error(err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	vaultResp.Badges = badges
 	c.JSON(http.StatusOK, vaultResp)
 }
 
@@ -175,6 +217,7 @@ func (a *Api) getVaultByUIDHandler(c *gin.Context) {
 		LPValue:        vault.LPValue,
 		NFTValue:       vault.NFTValue,
 		SwapVolume:     vault.SwapVolume,
+		SwapVolumeRank: vault.SwapVolumeRank,
 		Rank:           vault.Rank,
 		RegisteredAt:   vault.Model.CreatedAt.UTC().Unix(),
 		Coins:          []models.ChainCoins{},
@@ -203,12 +246,14 @@ func (a *Api) getVaultByUIDHandler(c *gin.Context) {
 		}
 	}
 	vaultResp.SeasonActivities = make([]models.SeasonStats, 0)
-	for _, season := range a.cfg.Seasons {
+	for _, season := range a.seasonRegistry.All() {
 		if season.ID == vault.CurrentSeasonID {
 			vaultResp.SeasonActivities = append(vaultResp.SeasonActivities, models.SeasonStats{
-				SeasonID: season.ID,
-				Rank:     vault.Rank,
-				Points:   vault.TotalPoints,
+				SeasonID:       season.ID,
+				Rank:           vault.Rank,
+				Points:         vault.TotalPoints,
+				SwapVolume:     vault.SwapVolume,
+				SwapVolumeRank: vault.SwapVolumeRank,
 			})
 		} else {
 			seasonStats, err := a.s.GetSeasonStats(vault.ID, season.ID)
@@ -218,12 +263,21 @@ func (a *Api) getVaultByUIDHandler(c *gin.Context) {
 				return
 			}
 			vaultResp.SeasonActivities = append(vaultResp.SeasonActivities, models.SeasonStats{
-				SeasonID: season.ID,
-				Rank:     seasonStats.Rank,
-				Points:   seasonStats.Points,
+				SeasonID:       season.ID,
+				Rank:           seasonStats.Rank,
+				Points:         seasonStats.Points,
+				SwapVolume:     seasonStats.SwapVolume,
+				SwapVolumeRank: seasonStats.SwapVolumeRank,
 			})
 		}
 	}
+	badges, err := a.s.GetVaultBadges(vault.ID)
+	if err != nil {
+		a.logger.Error(err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	vaultResp.Badges = badges
 	c.JSON(http.StatusOK, vaultResp)
 }
 func (a *Api) joinAirdrop(c *gin.Context) {
@@ -315,6 +369,7 @@ func (a *Api) deleteVaultHandler(c *gin.Context) {
 		return
 	}
 	a.questService.Remove(vault.ID)
+	a.dropLeaderboardCache()
 	c.Status(http.StatusOK)
 }
 
@@ -345,6 +400,7 @@ func (a *Api) updateAliasHandler(c *gin.Context) {
 			_ = c.Error(errFailedToUpdateVault)
 			return
 		}
+		a.dropLeaderboardCache()
 	} else {
 		_ = c.Error(errForbiddenAccess)
 		return
@@ -372,6 +428,33 @@ func (a *Api) updateReferralHandler(c *gin.Context) {
 		return
 	}
 	if v.HexChainCode == vault.HexChainCode && v.Uid == vault.Uid {
+		if a.cfg.ReferralWindowDays > 0 && time.Since(v.CreatedAt) > time.Duration(a.cfg.ReferralWindowDays)*24*time.Hour {
+			_ = c.Error(errReferralWindowClosed)
+			return
+		}
+		referrer, err := a.getReferrer(vault.ReferralCode)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if referrer.ID == v.ID {
+			_ = c.Error(errInvalidReferralCode)
+			return
+		}
+		created, err := a.s.CreateVaultReferral(referrer.ID, v.ID, models.ReferralSourceRegistry)
+		if errors.Is(err, models.ErrReferralCycle) {
+			_ = c.Error(errInvalidReferralCode)
+			return
+		}
+		if err != nil {
+			a.logger.Error(err)
+			_ = c.Error(errFailedToUpdateVault)
+			return
+		}
+		if !created {
+			_ = c.Error(errAlreadyReferred)
+			return
+		}
 		v.ReferralCode = vault.ReferralCode
 		if err := a.s.UpdateVault(v); err != nil {
 			a.logger.Error(err)
@@ -408,6 +491,23 @@ func (a *Api) getVaultsByRankHandler(c *gin.Context) {
 		return
 	}
 	seasonId := uint(id)
+	filter, err := parseLeaderboardFilter(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	cursor := c.Query("cursor")
+	if cursor != "" {
+		if from, err = decodeLeaderboardCursor(cursor); err != nil {
+			_ = c.Error(errInvalidRequest)
+			return
+		}
+	}
+	// filters and cursors are served from the leaderboard snapshot of the current season
+	if seasonId != a.seasonRegistry.Current().ID && (!filter.IsEmpty() || cursor != "") {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
 	vaultsResp := models.VaultsResponse{
 		Vaults:          []models.VaultResponse{},
 		TotalVaultCount: 0,
@@ -415,8 +515,25 @@ func (a *Api) getVaultsByRankHandler(c *gin.Context) {
 		TotalLP:         0,
 		TotalNFT:        0,
 	}
+	if seasonId == a.seasonRegistry.Current().ID {
+		snapshotResp, err := a.getLeaderboardSnapshot(from, limit, filter)
+		if err == nil {
+			c.JSON(http.StatusOK, snapshotResp)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			a.logger.Errorf("failed to get leaderboard snapshot: %v", err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+		// until the worker writes the first snapshot the leaderboard is aggregated from the vaults, filtered pages are empty
+		if !filter.IsEmpty() {
+			c.JSON(http.StatusOK, vaultsResp)
+			return
+		}
+	}
 	var vaults []models.Vault
-	if seasonId == a.cfg.GetCurrentSeason().ID {
+	if seasonId == a.seasonRegistry.Current().ID {
 		vaultsResp.TotalVaultCount, err = a.s.GetLeaderVaultCount()
 		if err != nil {
 			a.logger.Errorf("failed to get leader vault count: %v", err)
@@ -506,14 +623,7 @@ func (a *Api) getVaultsByRankHandler(c *gin.Context) {
 		}
 	}
 	for _, vault := range vaults {
-		vaultName := vault.Alias
-		if !vault.ShowNameInLeaderboard {
-			length := 10
-			if len(vault.Uid) < 10 {
-				length = len(vault.Uid)
-			}
-			vaultName = vault.Uid[:length]
-		}
+		vaultName := models.LeaderboardName(vault.Alias, vault.Uid, vault.ShowNameInLeaderboard)
 		vaultResp := models.VaultResponse{
 			Name:         vaultName,
 			Alias:        vaultName,
@@ -533,8 +643,9 @@ func (a *Api) getVaultsByRankHandler(c *gin.Context) {
 func (a *Api) getVaultsByVolumeHandler(c *gin.Context) {
 	fromStr := c.DefaultQuery("from", "0")
 	limitStr := c.DefaultQuery("limit", "10")
+	window := c.DefaultQuery("window", models.SwapWindowSeason)
 	from, err := strconv.ParseInt(fromStr, 10, 64)
-	if err != nil {
+	if err != nil || from < 0 {
 		_ = c.Error(errInvalidRequest)
 		return
 	}
@@ -551,49 +662,273 @@ func (a *Api) getVaultsByVolumeHandler(c *gin.Context) {
 		TotalVaultCount: 0,
 		TotalSwapVolume: 0,
 	}
-	vaultsResp.TotalVaultCount, err = a.s.GetLeaderVaultCount()
+	var vaults []models.Vault
+	if window == models.SwapWindowSeason {
+		vaultsResp.TotalVaultCount, err = a.s.GetSwapLeaderVaultCount()
+		if err != nil {
+			a.logger.Errorf("failed to get swap leader vault count: %v", err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+		vaultsResp.TotalSwapVolume, err = a.s.GetLeaderVaultTotalVolume()
+		if err != nil {
+			a.logger.Errorf("failed to get leader vault total volume: %v", err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+		vaults, err = a.s.GetSwapLeaderVaults(from, limit)
+		if err != nil {
+			a.logger.Errorf("failed to get leader vaults: %v", err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+	} else {
+		duration, ok := models.SwapWindowDuration(window)
+		if !ok {
+			_ = c.Error(errInvalidRequest)
+			return
+		}
+		vaults, err = a.getWindowSwapLeaderVaults(time.Now().Add(-duration), from, limit, &vaultsResp)
+		if err != nil {
+			a.logger.Errorf("failed to get %s swap leader vaults: %v", window, err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+	}
+	for _, vault := range vaults {
+		vaultName := models.LeaderboardName(vault.Alias, vault.Uid, vault.ShowNameInLeaderboard)
+		vaultResp := models.VaultResponse{
+			Name:           vaultName,
+			Alias:          vaultName,
+			TotalPoints:    vault.TotalPoints,
+			Rank:           vault.Rank,
+			SwapVolumeRank: vault.SwapVolumeRank,
+			Balance:        vault.Balance,
+			LPValue:        vault.LPValue,
+			NFTValue:       vault.NFTValue,
+			SwapVolume:     vault.SwapVolume,
+			RegisteredAt:   vault.Model.CreatedAt.UTC().Unix(),
+			AvatarURL:      vault.AvatarURL,
+		}
+		vaultsResp.Vaults = append(vaultsResp.Vaults, vaultResp)
+	}
+	c.JSON(http.StatusOK, vaultsResp)
+}
+
+// getWindowSwapLeaderVaults returns the vaults ranked by their swap volume since the given time,
+// the swap volume and rank of the returned vaults are the ones of the window
+func (a *Api) getWindowSwapLeaderVaults(since time.Time, from int64, limit int, vaultsResp *models.VaultsResponse) ([]models.Vault, error) {
+	var err error
+	vaultsResp.TotalVaultCount, vaultsResp.TotalSwapVolume, err = a.s.GetWindowSwapTotals(since)
 	if err != nil {
-		a.logger.Errorf("failed to get leader vault count: %v", err)
-		_ = c.Error(errFailedToGetVault)
+		return nil, err
+	}
+	volumes, err := a.s.GetWindowSwapLeaders(since, from, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(volumes))
+	for _, volume := range volumes {
+		ids = append(ids, volume.VaultID)
+	}
+	vaultByID := make(map[uint]models.Vault, len(ids))
+	found, err := a.s.GetVaultsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, vault := range found {
+		vaultByID[vault.ID] = vault
+	}
+	vaults := make([]models.Vault, 0, len(volumes))
+	for i, volume := range volumes {
+		vault, ok := vaultByID[volume.VaultID]
+		if !ok {
+			continue
+		}
+		vault.SwapVolume = volume.VolumeUSD
+		vault.SwapVolumeRank = from + int64(i+1)
+		vaults = append(vaults, vault)
+	}
+	return vaults, nil
+}
+
+// getVaultRanksHandler returns the rank and points of the vault after each job of a season, the current season by default
+func (a *Api) getVaultRanksHandler(c *gin.Context) {
+	ecdsaPublicKey := c.Param("ecdsaPublicKey")
+	eddsaPublicKey := c.Param("eddsaPublicKey")
+	seasonID, err := strconv.ParseUint(c.DefaultQuery("season", strconv.FormatUint(uint64(a.seasonRegistry.Current().ID), 10)), 10, 64)
+	if err != nil {
+		_ = c.Error(errInvalidRequest)
 		return
 	}
-	vaultsResp.TotalSwapVolume, err = a.s.GetLeaderVaultTotalVolume()
+	vault, err := a.s.GetVault(ecdsaPublicKey, eddsaPublicKey)
 	if err != nil {
-		a.logger.Errorf("failed to get leader vault total volume: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get vault: %v", err)
 		_ = c.Error(errFailedToGetVault)
 		return
 	}
-	vaults, err := a.s.GetSwapLeaderVaults(from, limit)
+	history, err := a.s.GetVaultRankHistory(vault.ID, uint(seasonID))
+	if err != nil {
+		a.logger.Errorf("failed to get vault rank history: %v", err)
+		_ = c.Error(errFailedToGetRankHistory)
+		return
+	}
+	c.JSON(http.StatusOK, newVaultRanksResponse(history))
+}
+
+// newVaultRanksResponse returns the rank history of a vault without its storage fields
+func newVaultRanksResponse(history []models.VaultRankHistory) []models.VaultRankResponse {
+	resp := make([]models.VaultRankResponse, 0, len(history))
+	for _, entry := range history {
+		resp = append(resp, models.VaultRankResponse{
+			JobDate:        entry.JobDate,
+			Rank:           entry.Rank,
+			Points:         entry.Points,
+			SwapVolumeRank: entry.SwapVolumeRank,
+		})
+	}
+	return resp
+}
+
+// getVaultMilestonesHandler returns the unlocked and upcoming milestones of the vault in a season, the current season by default
+func (a *Api) getVaultMilestonesHandler(c *gin.Context) {
+	ecdsaPublicKey := c.Param("ecdsaPublicKey")
+	eddsaPublicKey := c.Param("eddsaPublicKey")
+	seasonID, err := strconv.ParseUint(c.DefaultQuery("season", strconv.FormatUint(uint64(a.seasonRegistry.Current().ID), 10)), 10, 64)
+	if err != nil {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	season, ok := a.seasonRegistry.Get(uint(seasonID))
+	if !ok {
+		_ = c.Error(errSeasonNotFound)
+		return
+	}
+	vault, err := a.s.GetVault(ecdsaPublicKey, eddsaPublicKey)
 	if err != nil {
-		a.logger.Errorf("failed to get leader vaults: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get vault: %v", err)
 		_ = c.Error(errFailedToGetVault)
 		return
 	}
-	for i := range vaults {
-		vaults[i].Rank = from + int64(i+1)
+	points, reached := vault.TotalPoints, vault.NextMilestoneID
+	if vault.CurrentSeasonID != season.ID {
+		seasonStats, err := a.s.GetSeasonStats(vault.ID, season.ID)
+		if err != nil {
+			a.logger.Errorf("failed to get vault season stats: %v", err)
+			_ = c.Error(errFailedToGetVault)
+			return
+		}
+		points, reached = seasonStats.Points, seasonStats.MilestoneID
 	}
-	for _, vault := range vaults {
-		vaultName := vault.Alias
-		if !vault.ShowNameInLeaderboard {
-			length := 10
-			if len(vault.Uid) < 10 {
-				length = len(vault.Uid)
-			}
-			vaultName = vault.Uid[:length]
+	unlocked, err := a.s.GetVaultMilestones(vault.ID, season.ID)
+	if err != nil {
+		a.logger.Errorf("failed to get vault milestones: %v", err)
+		_ = c.Error(errFailedToGetMilestones)
+		return
+	}
+	c.JSON(http.StatusOK, newVaultMilestonesResponse(season, points, reached, unlocked))
+}
+
+// newVaultMilestonesResponse merges the milestones of the season with the ones the vault unlocked, the milestones
+// before reached count as unlocked even without record as they were unlocked before the unlocks were recorded
+func newVaultMilestonesResponse(season config.AirdropSeason, points float64, reached int, unlocked []models.VaultMilestone) models.VaultMilestonesResponse {
+	unlockedByIndex := make(map[int]models.VaultMilestone, len(unlocked))
+	for _, milestone := range unlocked {
+		unlockedByIndex[milestone.MilestoneIndex] = milestone
+	}
+	resp := models.VaultMilestonesResponse{
+		SeasonID:   season.ID,
+		Points:     points,
+		Milestones: make([]models.MilestoneStatus, 0, len(season.Milestones)),
+	}
+	for i, milestone := range season.Milestones {
+		status := models.MilestoneStatus{
+			Index:           i,
+			Minimum:         int64(milestone.Minimum),
+			Prize:           int64(milestone.Prize),
+			PointsRemaining: math.Max(0, float64(milestone.Minimum)-points),
 		}
-		vaultResp := models.VaultResponse{
-			Name:         vaultName,
-			Alias:        vaultName,
-			TotalPoints:  vault.TotalPoints,
-			Rank:         vault.Rank,
-			Balance:      vault.Balance,
-			LPValue:      vault.LPValue,
-			NFTValue:     vault.NFTValue,
-			SwapVolume:   vault.SwapVolume,
-			RegisteredAt: vault.Model.CreatedAt.UTC().Unix(),
-			AvatarURL:    vault.AvatarURL,
+		if record, ok := unlockedByIndex[i]; ok {
+			status.Unlocked = true
+			status.UnlockedAt = record.UnlockedAt
+			status.JobID = record.JobID
+		} else if i < reached {
+			status.Unlocked = true
 		}
-		vaultsResp.Vaults = append(vaultsResp.Vaults, vaultResp)
+		resp.Milestones = append(resp.Milestones, status)
 	}
-	c.JSON(http.StatusOK, vaultsResp)
+	for i := range resp.Milestones {
+		if !resp.Milestones[i].Unlocked {
+			resp.NextMilestone = &resp.Milestones[i]
+			resp.PointsToNext = resp.Milestones[i].PointsRemaining
+			break
+		}
+	}
+	return resp
+}
+
+// getReferrer returns the vault owning a referral code
+func (a *Api) getReferrer(code string) (*models.Vault, error) {
+	referrer, err := a.s.GetVaultByReferralCode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidReferralCode
+		}
+		a.logger.Errorf("failed to get vault by referral code: %v", err)
+		return nil, errFailedToGetReferrals
+	}
+	return referrer, nil
+}
+
+// getVaultReferralsHandler returns the referral code of the vault, the vault which referred it and the vaults it referred
+func (a *Api) getVaultReferralsHandler(c *gin.Context) {
+	ecdsaPublicKey := c.Param("ecdsaPublicKey")
+	eddsaPublicKey := c.Param("eddsaPublicKey")
+	vault, err := a.s.GetVault(ecdsaPublicKey, eddsaPublicKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(errVaultNotFound)
+			return
+		}
+		a.logger.Errorf("failed to get vault: %v", err)
+		_ = c.Error(errFailedToGetVault)
+		return
+	}
+	code, err := a.s.GetOrCreateReferralCode(vault.ID)
+	if err != nil {
+		a.logger.Errorf("failed to get referral code: %v", err)
+		_ = c.Error(errFailedToGetReferrals)
+		return
+	}
+	resp := models.VaultReferralsResponse{Code: code}
+	referrer, err := a.s.GetVaultReferrer(vault.ID)
+	switch {
+	case err == nil:
+		resp.ReferredBy = models.LeaderboardName(referrer.Alias, referrer.Uid, referrer.ShowNameInLeaderboard)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		a.logger.Errorf("failed to get vault referrer: %v", err)
+		_ = c.Error(errFailedToGetReferrals)
+		return
+	}
+	resp.Referrals, err = a.s.GetVaultReferrals(vault.ID)
+	if err != nil {
+		a.logger.Errorf("failed to get vault referrals: %v", err)
+		_ = c.Error(errFailedToGetReferrals)
+		return
+	}
+	resp.Total = int64(len(resp.Referrals))
+	for _, referral := range resp.Referrals {
+		if referral.Valid {
+			resp.Valid++
+		}
+	}
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestNewVaultRanksResponse(t *testing.T) {
+	history := []models.VaultRankHistory{
+		{Model: gorm.Model{ID: 11}, VaultID: 3, JobID: 20, SeasonID: 2, JobDate: 1735689600, Rank: 12, Points: 1500.5, SwapVolumeRank: 4},
+		{Model: gorm.Model{ID: 12}, VaultID: 3, JobID: 21, SeasonID: 2, JobDate: 1735776000, Rank: 9, Points: 1800},
+	}
+	resp := newVaultRanksResponse(history)
+	assert.Equal(t, []models.VaultRankResponse{
+		{JobDate: 1735689600, Rank: 12, Points: 1500.5, SwapVolumeRank: 4},
+		{JobDate: 1735776000, Rank: 9, Points: 1800},
+	}, resp)
+
+	buf, err := json.Marshal(resp[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"job_date":1735689600,"rank":12,"points":1500.5,"swap_volume_rank":4}`, string(buf))
+
+	// a vault without history gets an empty list rather than null
+	buf, err = json.Marshal(newVaultRanksResponse(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(buf))
+}
+
+func TestNewVaultMilestonesResponse(t *testing.T) {
+	season := config.AirdropSeason{
+		ID: 2,
+		Milestones: []config.Milestone{
+			{Minimum: 1000, Prize: 100},
+			{Minimum: 5000, Prize: 500},
+			{Minimum: 10000, Prize: 1000},
+		},
+	}
+	unlocked := []models.VaultMilestone{
+		{VaultID: 3, SeasonID: 2, MilestoneIndex: 1, JobID: 7, UnlockedAt: 1735689600, Prize: 500},
+	}
+
+	// the first milestone was reached before unlocks were recorded
+	resp := newVaultMilestonesResponse(season, 6000, 2, unlocked)
+	assert.Equal(t, uint(2), resp.SeasonID)
+	assert.Equal(t, 6000.0, resp.Points)
+	assert.Equal(t, []models.MilestoneStatus{
+		{Index: 0, Minimum: 1000, Prize: 100, Unlocked: true},
+		{Index: 1, Minimum: 5000, Prize: 500, Unlocked: true, UnlockedAt: 1735689600, JobID: 7},
+		{Index: 2, Minimum: 10000, Prize: 1000, PointsRemaining: 4000},
+	}, resp.Milestones)
+	if assert.NotNil(t, resp.NextMilestone) {
+		assert.Equal(t, 2, resp.NextMilestone.Index)
+	}
+	assert.Equal(t, 4000.0, resp.PointsToNext)
+
+	// a vault short of the first milestone
+	resp = newVaultMilestonesResponse(season, 250, 0, nil)
+	if assert.NotNil(t, resp.NextMilestone) {
+		assert.Equal(t, 0, resp.NextMilestone.Index)
+	}
+	assert.Equal(t, 750.0, resp.PointsToNext)
+	assert.Equal(t, 4750.0, resp.Milestones[1].PointsRemaining)
+
+	// a vault which reached every milestone has no next one
+	resp = newVaultMilestonesResponse(season, 20000, 3, unlocked)
+	for _, milestone := range resp.Milestones {
+		assert.True(t, milestone.Unlocked)
+		assert.Equal(t, 0.0, milestone.PointsRemaining)
+	}
+	assert.Nil(t, resp.NextMilestone)
+	assert.Equal(t, 0.0, resp.PointsToNext)
+
+	// a season without milestones
+	resp = newVaultMilestonesResponse(config.AirdropSeason{ID: 3}, 100, 0, nil)
+	assert.Empty(t, resp.Milestones)
+	assert.Nil(t, resp.NextMilestone)
+}
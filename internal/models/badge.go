@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Badge codes evaluated by the worker, definitions of the achievements feed with other codes are stored but never awarded
+const (
+	BadgeFirstSwap       = "first_swap"       // the vault swapped through a tracked swap source
+	BadgePoints100K      = "points_100k"      // the vault reached 100k points in a season
+	BadgeSeasonHolder    = "season_holder"    // the vault was registered before a season started and still held value when it closed
+	BadgeReferred10      = "referred_10"      // the vault has 10 valid referrals
+	BadgeThorGuardHolder = "thorguard_holder" // the vault holds a THORGuard nft
+)
+
+// Badge sources
+const (
+	BadgeSourceRegistry = "registry" // built-in definition
+	BadgeSourceVultiBot = "vultibot" // synced from the achievements feed of the referral bot
+)
+
+// Badge is the definition of an achievement vaults are awarded, a badge is only awarded between its start and end dates
+type Badge struct {
+	gorm.Model
+	Code        string `gorm:"type:varchar(64);not null;uniqueIndex" json:"code"`
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Icon        string `gorm:"type:varchar(512)" json:"icon"`
+	Color       string `gorm:"type:varchar(32)" json:"color"`
+	StartDate   int64  `gorm:"type:bigint;default:0" json:"start_date"` // unix time, 0 is unbounded
+	EndDate     int64  `gorm:"type:bigint;default:0" json:"end_date"`   // unix time, 0 is unbounded
+	Source      string `gorm:"type:varchar(20);not null" json:"source"`
+}
+
+func (*Badge) TableName() string {
+	return "badges"
+}
+
+// DefaultBadges are the definitions of the badges evaluated by the worker, used until the achievements feed
+// provides its own
+var DefaultBadges = []Badge{
+	{Code: BadgeFirstSwap, Name: "First Swap", Description: "Swapped with the vault", Source: BadgeSourceRegistry},
+	{Code: BadgePoints100K, Name: "100K Points", Description: "Reached 100,000 points in a season", Source: BadgeSourceRegistry},
+	{Code: BadgeSeasonHolder, Name: "Season Holder", Description: "Held through a whole season", Source: BadgeSourceRegistry},
+	{Code: BadgeReferred10, Name: "Top Referrer", Description: "Referred 10 vaults", Source: BadgeSourceRegistry},
+	{Code: BadgeThorGuardHolder, Name: "THORGuard Holder", Description: "Holds a THORGuard", Source: BadgeSourceRegistry},
+}
+
+// NewBadgeFromAchievement returns the badge definition of an achievement of the referral bot feed
+func NewBadgeFromAchievement(achievement AchievementsResponse) Badge {
+	return Badge{
+		Code:        achievement.Code,
+		Name:        achievement.Name,
+		Description: achievement.Description,
+		Icon:        achievement.Icon,
+		Color:       achievement.Color,
+		StartDate:   parseAchievementDate(achievement.StartDate),
+		EndDate:     parseAchievementDate(achievement.EndDate),
+		Source:      BadgeSourceVultiBot,
+	}
+}
+
+// parseAchievementDate returns the unix time of an achievement date, 0 if it's empty or can't be parsed
+func parseAchievementDate(date string) int64 {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Unix()
+		}
+	}
+	return 0
+}
+
+// VaultBadge is a badge awarded to a vault, a vault is awarded a badge once
+type VaultBadge struct {
+	gorm.Model
+	VaultID   uint   `gorm:"type:bigint;not null;uniqueIndex:vault_badge_idx" json:"vault_id"`
+	BadgeCode string `gorm:"type:varchar(64);not null;uniqueIndex:vault_badge_idx" json:"badge_code"`
+	SeasonID  uint   `gorm:"type:bigint;not null" json:"season_id"`  // season running when the badge was awarded
+	AwardedAt int64  `gorm:"type:bigint;not null" json:"awarded_at"` // unix time
+}
+
+func (*VaultBadge) TableName() string {
+	return "vault_badges"
+}
+
+// VaultBadgeResponse is a badge of a vault as shown on the vault pages
+type VaultBadgeResponse struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+	SeasonID    uint   `json:"season_id"`
+	AwardedAt   int64  `json:"awarded_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBadgeFromAchievement(t *testing.T) {
+	badge := NewBadgeFromAchievement(AchievementsResponse{
+		Code:      BadgeFirstSwap,
+		Name:      "First Swap",
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-03-31",
+	})
+	assert.Equal(t, BadgeFirstSwap, badge.Code)
+	assert.Equal(t, BadgeSourceVultiBot, badge.Source)
+	assert.Equal(t, int64(1735689600), badge.StartDate)
+	assert.Equal(t, int64(1743379200), badge.EndDate)
+
+	badge = NewBadgeFromAchievement(AchievementsResponse{Code: BadgeReferred10, StartDate: "soon"})
+	assert.Zero(t, badge.StartDate)
+	assert.Zero(t, badge.EndDate)
+}
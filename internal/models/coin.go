@@ -30,31 +30,37 @@ type ChainCoins struct {
 }
 
 type Coin struct {
-	ID              uint   `json:"id"`
-	Ticker          string `json:"ticker"`
-	ContractAddress string `json:"contract_address"`
-	Decimals        int    `json:"decimals"`
-	IsNative        bool   `json:"is_native"`
-	CMCId           int    `json:"cmc_id"`
-	Logo            string `json:"logo"`
+	ID               uint    `json:"id"`
+	Ticker           string  `json:"ticker"`
+	ContractAddress  string  `json:"contract_address"`
+	Decimals         int     `json:"decimals"`
+	IsNative         bool    `json:"is_native"`
+	CMCId            int     `json:"cmc_id"`
+	Logo             string  `json:"logo"`
+	ValuationStatus  string  `json:"valuation_status"`
+	CreditedUSDValue float64 `json:"credited_usd_value"`
 }
 
 func NewCoin(c CoinDBModel) Coin {
 	return Coin{
-		ID:              c.ID,
-		Ticker:          c.Ticker,
-		ContractAddress: c.ContractAddress,
-		Decimals:        c.Decimals,
-		IsNative:        c.IsNative,
-		CMCId:           c.CMCId,
-		Logo:            c.Logo,
+		ID:               c.ID,
+		Ticker:           c.Ticker,
+		ContractAddress:  c.ContractAddress,
+		Decimals:         c.Decimals,
+		IsNative:         c.IsNative,
+		CMCId:            c.CMCId,
+		Logo:             c.Logo,
+		ValuationStatus:  c.ValuationStatus,
+		CreditedUSDValue: c.CreditedUSDValue,
 	}
 }
 
 type CoinDBModel struct {
 	gorm.Model
 	CoinBase
-	VaultID uint `json:"vault_id" binding:"required" gorm:"not null"`
+	VaultID          uint    `json:"vault_id" binding:"required" gorm:"not null"`
+	ValuationStatus  string  `json:"valuation_status" gorm:"type:varchar(20)"`
+	CreditedUSDValue float64 `json:"credited_usd_value" gorm:"type:decimal(65,30)"`
 }
 
 func (CoinDBModel) TableName() string {
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+// LeaderboardSnapshot is the leaderboard entry of a vault, written by the worker after the vault ranks are updated
+// so the leaderboard is served without aggregating the vaults table. The totals row has VaultID 0.
+type LeaderboardSnapshot struct {
+	gorm.Model
+	VaultID               uint    `gorm:"type:bigint;not null;uniqueIndex" json:"vault_id"`
+	Uid                   string  `gorm:"type:varchar(255);index" json:"uid"`
+	Alias                 string  `gorm:"type:varchar(255)" json:"alias"`
+	ShowNameInLeaderboard bool    `gorm:"type:boolean;default:false" json:"show_name_in_leaderboard"`
+	AvatarURL             string  `gorm:"type:varchar(255)" json:"avatar_url"`
+	Rank                  int64   `gorm:"type:bigint;index" json:"rank"`
+	PreviousRank          int64   `gorm:"type:bigint;default:0" json:"previous_rank"` // rank in the previous snapshot, 0 if the vault wasn't ranked
+	TotalPoints           float64 `json:"total_points"`
+	Balance               int64   `gorm:"type:bigint;default:0" json:"balance"`
+	LPValue               int64   `gorm:"type:bigint;default:0" json:"lp_value"`
+	NFTValue              int64   `gorm:"type:bigint;default:0" json:"nft_value"`
+	VaultCount            int64   `gorm:"type:bigint;default:0" json:"vault_count"` // number of ranked vaults, totals row only
+	RegisteredAt          int64   `gorm:"type:bigint" json:"registered_at"`
+}
+
+func (*LeaderboardSnapshot) TableName() string {
+	return "leaderboard_snapshots"
+}
+
+// RankDelta returns the number of ranks the vault climbed since the previous snapshot, negative if it dropped
+// and 0 for vaults which weren't ranked before
+func (l *LeaderboardSnapshot) RankDelta() int64 {
+	if l.PreviousRank == 0 || l.Rank == 0 {
+		return 0
+	}
+	return l.PreviousRank - l.Rank
+}
+
+// LeaderboardName returns the name shown in the leaderboard, vaults which don't show their alias are shown by their uid prefix
+func LeaderboardName(alias, uid string, showName bool) string {
+	if showName {
+		return alias
+	}
+	length := 10
+	if len(uid) < 10 {
+		length = len(uid)
+	}
+	return uid[:length]
+}
+
+// LeaderboardFilter narrows the leaderboard snapshot entries, zero values don't filter
+type LeaderboardFilter struct {
+	AliasPrefix string        // vaults showing their name in the leaderboard with an alias starting with the prefix
+	MinPoints   float64       // vaults with at least these points
+	Chain       *common.Chain // vaults holding coins on the chain
+}
+
+func (f LeaderboardFilter) IsEmpty() bool {
+	return f.AliasPrefix == "" && f.MinPoints == 0 && f.Chain == nil
+}
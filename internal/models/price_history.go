@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+// PriceHistory stores the price used by a job for an asset, so points credited in the past can be audited
+type PriceHistory struct {
+	gorm.Model
+	JobID           uint         `gorm:"not null;uniqueIndex:price_history_idx" json:"job_id"`
+	Source          string       `gorm:"type:varchar(50);not null;uniqueIndex:price_history_idx" json:"source"`
+	CMCId           int          `gorm:"type:Integer;not null;default:0;uniqueIndex:price_history_idx;index:cmc_fetched_idx" json:"cmc_id"`
+	Chain           common.Chain `gorm:"type:varchar(50);not null;default:'';uniqueIndex:price_history_idx;index:asset_fetched_idx" json:"chain"`
+	Ticker          string       `gorm:"type:varchar(64);not null;default:'';uniqueIndex:price_history_idx;index:asset_fetched_idx" json:"ticker"`
+	ContractAddress string       `gorm:"type:varchar(128);not null;default:'';uniqueIndex:price_history_idx" json:"contract_address"`
+	PriceUSD        float64      `gorm:"type:decimal(65,30);not null" json:"price_usd"`
+	FetchedAt       time.Time    `gorm:"not null;index:cmc_fetched_idx;index:asset_fetched_idx" json:"fetched_at"`
+}
+
+func (*PriceHistory) TableName() string {
+	return "price_history"
+}
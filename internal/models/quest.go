@@ -0,0 +1,90 @@
+package models
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+// Quest criteria
+const (
+	QuestCriteriaHoldsVault   = "holds_vault"   // the address belongs to a registered vault
+	QuestCriteriaMinBalance   = "min_balance"   // the vault holds at least Minimum usd, liquidity positions and nfts included
+	QuestCriteriaSwapVolume   = "swap_volume"   // the vault swapped at least Minimum usd during the current season
+	QuestCriteriaChainAddress = "chain_address" // the vault has an address on Chain
+)
+
+// Quest is a partner quest, verified by the partner through the quest verify endpoint.
+// Quests are seeded from the quests of the config file and managed through the admin endpoints.
+type Quest struct {
+	gorm.Model
+	QuestID  string  `gorm:"type:varchar(64);not null;uniqueIndex" json:"quest_id"`
+	Partner  string  `gorm:"type:varchar(64);not null" json:"partner"` // name of the quest partner of the config file
+	Criteria string  `gorm:"type:varchar(32);not null" json:"criteria"`
+	Chain    string  `gorm:"type:varchar(50)" json:"chain"`
+	Minimum  float64 `gorm:"type:decimal(65,30);default:0" json:"minimum"`
+	Points   float64 `gorm:"type:decimal(65,30);default:0" json:"points"` // added to the vault points on the first completion
+	Enabled  bool    `json:"enabled"`
+}
+
+func (*Quest) TableName() string {
+	return "quests"
+}
+
+// IsValid checks the quest fields, authenticated tells if the quest partner checks an api key or the client ip.
+// Anyone can verify the quests of an open partner, such quests can't reward points.
+func (q *Quest) IsValid(authenticated bool) bool {
+	if q.QuestID == "" || len(q.QuestID) > 64 || q.Minimum < 0 || q.Points < 0 {
+		return false
+	}
+	if !authenticated && q.Points > 0 {
+		return false
+	}
+	switch q.Criteria {
+	case QuestCriteriaHoldsVault, QuestCriteriaMinBalance, QuestCriteriaSwapVolume:
+		return true
+	case QuestCriteriaChainAddress:
+		_, err := common.ChainFromString(q.Chain)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// IsCompletedBy returns true if the vault meets the quest criteria, hasChainAddress tells if the vault has an
+// address on the quest chain
+func (q *Quest) IsCompletedBy(vault *Vault, hasChainAddress bool) bool {
+	switch q.Criteria {
+	case QuestCriteriaHoldsVault:
+		return true
+	case QuestCriteriaMinBalance:
+		return float64(vault.Balance+vault.LPValue+vault.NFTValue) >= q.Minimum
+	case QuestCriteriaSwapVolume:
+		return vault.SwapVolume >= q.Minimum
+	case QuestCriteriaChainAddress:
+		return hasChainAddress
+	default:
+		return false
+	}
+}
+
+// QuestCompletion records the first successful verification of a quest for a vault
+type QuestCompletion struct {
+	gorm.Model
+	QuestID  string  `gorm:"type:varchar(64);not null;uniqueIndex:quest_vault_idx" json:"quest_id"`
+	VaultID  uint    `gorm:"type:bigint;not null;uniqueIndex:quest_vault_idx;index" json:"vault_id"`
+	SeasonID uint    `gorm:"type:bigint;not null" json:"season_id"`
+	Address  string  `gorm:"type:varchar(255)" json:"address"` // address the partner verified
+	Points   float64 `gorm:"type:decimal(65,30);default:0" json:"points"`
+}
+
+func (*QuestCompletion) TableName() string {
+	return "quest_completions"
+}
+
+// QuestVerifyResponse is the response of the quest verify endpoint, in the format coinmarketcap expects
+type QuestVerifyResponse struct {
+	Result struct {
+		IsValid bool `json:"is_valid"`
+	} `json:"result"`
+}
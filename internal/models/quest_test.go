@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestIsCompletedBy(t *testing.T) {
+	vault := &Vault{Balance: 40, LPValue: 5, NFTValue: 5, SwapVolume: 120}
+	assert.True(t, (&Quest{Criteria: QuestCriteriaHoldsVault}).IsCompletedBy(vault, false))
+	assert.True(t, (&Quest{Criteria: QuestCriteriaMinBalance, Minimum: 50}).IsCompletedBy(vault, false))
+	assert.False(t, (&Quest{Criteria: QuestCriteriaMinBalance, Minimum: 51}).IsCompletedBy(vault, false))
+	assert.True(t, (&Quest{Criteria: QuestCriteriaSwapVolume, Minimum: 100}).IsCompletedBy(vault, false))
+	assert.False(t, (&Quest{Criteria: QuestCriteriaSwapVolume, Minimum: 200}).IsCompletedBy(vault, false))
+	assert.True(t, (&Quest{Criteria: QuestCriteriaChainAddress, Chain: "Solana"}).IsCompletedBy(vault, true))
+	assert.False(t, (&Quest{Criteria: QuestCriteriaChainAddress, Chain: "Solana"}).IsCompletedBy(vault, false))
+	assert.False(t, (&Quest{Criteria: "unknown"}).IsCompletedBy(vault, true))
+}
+
+func TestQuestIsValid(t *testing.T) {
+	assert.True(t, (&Quest{QuestID: "cmc", Criteria: QuestCriteriaHoldsVault}).IsValid(false))
+	// open partners can't reward points
+	assert.False(t, (&Quest{QuestID: "cmc", Criteria: QuestCriteriaHoldsVault, Points: 100}).IsValid(false))
+	assert.True(t, (&Quest{QuestID: "cmc", Criteria: QuestCriteriaHoldsVault, Points: 100}).IsValid(true))
+	assert.True(t, (&Quest{QuestID: "sol", Criteria: QuestCriteriaChainAddress, Chain: "Solana"}).IsValid(true))
+	assert.False(t, (&Quest{QuestID: "sol", Criteria: QuestCriteriaChainAddress, Chain: "solana"}).IsValid(true))
+	assert.False(t, (&Quest{QuestID: "", Criteria: QuestCriteriaHoldsVault}).IsValid(true))
+	assert.False(t, (&Quest{QuestID: "q", Criteria: QuestCriteriaMinBalance, Minimum: -1}).IsValid(true))
+	assert.False(t, (&Quest{QuestID: "q", Criteria: "unknown"}).IsValid(true))
+}
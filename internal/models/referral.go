@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrReferralCycle is returned when the referrer descends from the referee in the referral tree
+var ErrReferralCycle = errors.New("referral cycle")
+
+// Referral sources
+const (
+	ReferralSourceRegistry = "registry" // referral code given at registration or through the referral endpoint
+	ReferralSourceVultiBot = "vultibot" // imported from the referral bot
+)
+
+// ReferralCode is the unique code a vault shares to refer other vaults
+type ReferralCode struct {
+	gorm.Model
+	VaultID uint   `gorm:"type:bigint;not null;uniqueIndex" json:"vault_id"`
+	Code    string `gorm:"type:varchar(16);not null;uniqueIndex" json:"code"`
+}
+
+func (*ReferralCode) TableName() string {
+	return "referral_codes"
+}
+
+// VaultReferral links a referee vault to the vault which referred it, a vault is referred at most once
+type VaultReferral struct {
+	gorm.Model
+	ReferrerID uint   `gorm:"type:bigint;not null;index" json:"referrer_id"`
+	RefereeID  uint   `gorm:"type:bigint;not null;uniqueIndex" json:"referee_id"`
+	Source     string `gorm:"type:varchar(20);not null" json:"source"`
+}
+
+func (*VaultReferral) TableName() string {
+	return "vault_referrals"
+}
+
+// ReferralReward is the referral points a referrer earned in a job, from the points its valid referees earned
+type ReferralReward struct {
+	gorm.Model
+	VaultID           uint    `gorm:"type:bigint;not null;uniqueIndex:vault_job_idx" json:"vault_id"`
+	JobID             uint    `gorm:"type:bigint;not null;uniqueIndex:vault_job_idx;index" json:"job_id"`
+	SeasonID          uint    `gorm:"type:bigint;not null" json:"season_id"`
+	DirectPoints      float64 `json:"direct_points"`       // points earned by the direct referees
+	SecondLevelPoints float64 `json:"second_level_points"` // points earned by the referees of the referees
+	Points            float64 `json:"points"`              // reward added to the referrer points, capped by the season scheme
+}
+
+func (*ReferralReward) TableName() string {
+	return "referral_rewards"
+}
+
+// ReferralLink is a referral with the state of the referee, used by the referral analytics
+type ReferralLink struct {
+	ReferrerID uint
+	RefereeID  uint
+	ReferredAt int64 // unix time
+	Valid      bool
+}
+
+// ReferrerStats summarizes the referrals of a referrer
+type ReferrerStats struct {
+	VaultID          uint     `json:"vault_id"`
+	Uid              string   `json:"uid"`
+	Referrals        int64    `json:"referrals"`
+	ValidReferrals   int64    `json:"valid_referrals"`
+	Conversion       float64  `json:"conversion"`         // share of the referrals which are valid
+	SecondLevel      int64    `json:"second_level"`       // referrals made by the referees
+	MaxReferralsHour int64    `json:"max_referrals_hour"` // largest number of referrals within an hour
+	Flags            []string `json:"flags,omitempty"`
+}
+
+// Referral analytics flags
+const (
+	ReferralFlagLowConversion = "low_conversion" // many referrals of which few hold value
+	ReferralFlagBurst         = "burst"          // many referrals registered within an hour
+)
+
+// ReferralAnalytics is the admin overview of the referrals
+type ReferralAnalytics struct {
+	Referrers      int64           `json:"referrers"`
+	Referrals      int64           `json:"referrals"`
+	ValidReferrals int64           `json:"valid_referrals"`
+	Conversion     float64         `json:"conversion"`
+	TopReferrers   []ReferrerStats `json:"top_referrers"`
+	Suspicious     []ReferrerStats `json:"suspicious"`
+}
+
+// ReferralTreeNode is a vault with the vaults it referred
+type ReferralTreeNode struct {
+	VaultID    uint               `json:"vault_id"`
+	Uid        string             `json:"uid"`
+	Valid      bool               `json:"valid"`
+	ReferredAt int64              `json:"referred_at,omitempty"`
+	Children   []ReferralTreeNode `json:"children"`
+}
+
+// ReferralEntry is a vault referred by another vault, as shown to the referrer
+type ReferralEntry struct {
+	Name         string `json:"name"`
+	RegisteredAt int64  `json:"registered_at"` // unix time the referee registered
+	ReferredAt   int64  `json:"referred_at"`   // unix time of the referral link
+	Valid        bool   `json:"valid"`         // the referee holds enough value for the referral to count
+	Source       string `json:"source"`
+}
+
+// VaultReferralsResponse lists the referral code of a vault and the vaults it referred
+type VaultReferralsResponse struct {
+	Code       string          `json:"code"`
+	ReferredBy string          `json:"referred_by"` // name of the referrer, empty if the vault wasn't referred
+	Total      int64           `json:"total"`
+	Valid      int64           `json:"valid"`
+	Referrals  []ReferralEntry `json:"referrals"`
+}
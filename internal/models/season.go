@@ -0,0 +1,69 @@
+package models
+
+import "gorm.io/gorm"
+
+// Season is an airdrop season, seeded from the seasons of the config file and managed through the admin endpoints.
+// SeasonID is the season number vaults and season stats refer to, it starts at 0 so it isn't the primary key.
+type Season struct {
+	gorm.Model
+	SeasonID   uint              `gorm:"type:bigint;not null;uniqueIndex" json:"season_id"`
+	Start      int64             `gorm:"type:bigint;not null" json:"start"` // unix time
+	End        int64             `gorm:"type:bigint;not null" json:"end"`   // unix time
+	Milestones []SeasonMilestone `gorm:"foreignKey:SeasonID;references:SeasonID" json:"milestones"`
+	NFTs       []SeasonNFT       `gorm:"foreignKey:SeasonID;references:SeasonID" json:"nfts"`
+	Tokens     []SeasonToken     `gorm:"foreignKey:SeasonID;references:SeasonID" json:"tokens"`
+	Referral   SeasonReferral    `gorm:"embedded;embeddedPrefix:referral_" json:"referral"`
+}
+
+// SeasonReferral is the referral reward scheme of a season, see config.ReferralScheme
+type SeasonReferral struct {
+	DirectShare      float64 `gorm:"type:decimal(10,4);default:0" json:"direct_share"`
+	SecondLevelShare float64 `gorm:"type:decimal(10,4);default:0" json:"second_level_share"`
+	MaxPointsPerJob  float64 `gorm:"type:decimal(65,30);default:0" json:"max_points_per_job"`
+}
+
+func (*Season) TableName() string {
+	return "seasons"
+}
+
+// SeasonMilestone is a vulti milestone of a season, milestones are reached in Position order
+type SeasonMilestone struct {
+	gorm.Model
+	SeasonID uint  `gorm:"type:bigint;not null;index" json:"season_id"`
+	Position int   `gorm:"type:int;not null" json:"position"`
+	Minimum  int64 `gorm:"type:bigint;not null" json:"minimum"` // minimum amount of vulti to reach the milestone
+	Prize    int64 `gorm:"type:bigint;not null" json:"prize"`
+}
+
+func (*SeasonMilestone) TableName() string {
+	return "season_milestones"
+}
+
+// SeasonNFT is an nft collection boosting the points of its holders during a season
+type SeasonNFT struct {
+	gorm.Model
+	SeasonID        uint    `gorm:"type:bigint;not null;index" json:"season_id"`
+	Name            string  `gorm:"type:varchar(255)" json:"name"`
+	CollectionName  string  `gorm:"type:varchar(255)" json:"collection_name"`
+	Chain           string  `gorm:"type:varchar(255);not null" json:"chain"`
+	ContractAddress string  `gorm:"type:varchar(255);not null" json:"contract_address"`
+	Multiplier      float64 `gorm:"type:decimal(10,4);not null" json:"multiplier"`
+}
+
+func (*SeasonNFT) TableName() string {
+	return "season_nfts"
+}
+
+// SeasonToken is a token boosting the points of its holders during a season
+type SeasonToken struct {
+	gorm.Model
+	SeasonID        uint    `gorm:"type:bigint;not null;index" json:"season_id"`
+	Name            string  `gorm:"type:varchar(255);not null" json:"name"`
+	Chain           string  `gorm:"type:varchar(255);not null" json:"chain"`
+	ContractAddress string  `gorm:"type:varchar(255)" json:"contract_address"`
+	Multiplier      float64 `gorm:"type:decimal(10,4);not null" json:"multiplier"`
+}
+
+func (*SeasonToken) TableName() string {
+	return "season_tokens"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "gorm.io/gorm"
+
+// Season closure statuses
+const (
+	SeasonClosureInProgress = "in_progress"
+	SeasonClosureClosed     = "closed"
+)
+
+// SeasonClosure records the rollover of a season by the season close command, the worker doesn't score
+// vaults of a season which isn't closed yet
+type SeasonClosure struct {
+	gorm.Model
+	SeasonID     uint    `gorm:"type:bigint;not null;uniqueIndex" json:"season_id"`
+	NextSeasonID uint    `gorm:"type:bigint;not null" json:"next_season_id"`
+	Status       string  `gorm:"type:varchar(20);not null" json:"status"`
+	VaultCount   int64   `gorm:"type:bigint;default:0" json:"vault_count"` // vaults with committed season stats
+	TotalPoints  float64 `gorm:"type:decimal(65,30);default:0" json:"total_points"`
+	ClosedAt     int64   `gorm:"type:bigint;default:0" json:"closed_at"` // unix time, 0 while in progress
+}
+
+func (*SeasonClosure) TableName() string {
+	return "season_closures"
+}
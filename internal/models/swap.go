@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SwapSourceLegacy is the source of the swaps holding the volume vaults accumulated before the swap ledger existed
+const SwapSourceLegacy = "legacy"
+
+// Swap is a single affiliate swap reported by a volume tracker, vault swap volume is derived from this table
+type Swap struct {
+	gorm.Model
+	TxHash      string    `gorm:"type:varchar(128);not null;uniqueIndex:swap_tx_hash_idx" json:"tx_hash"`
+	FromAddress string    `gorm:"type:varchar(255);not null;index:swap_from_address_idx" json:"from_address"`
+	VolumeUSD   float64   `gorm:"type:decimal(65,30);not null;default:0" json:"volume_usd"`
+	SwappedAt   time.Time `gorm:"not null;index:swap_vault_time_idx" json:"swapped_at"`
+	Source      string    `gorm:"type:varchar(50);not null" json:"source"`
+	Asset       string    `gorm:"type:varchar(128);not null;default:'';index:swap_asset_idx" json:"asset"` // pool the swap went through, empty if the tracker doesn't report it
+	VaultID     uint      `gorm:"not null;default:0;index:swap_vault_time_idx" json:"vault_id"`
+	// token the swapper received and its amount in base units, set by the trackers pricing swaps themselves so a
+	// swap without a price when it's fetched is priced by a later job
+	TokenChain string `gorm:"type:varchar(50);not null;default:''" json:"token_chain"`
+	Token      string `gorm:"type:varchar(255);not null;default:''" json:"token"` // contract address, empty for the native token
+	RawAmount  string `gorm:"type:varchar(100);not null;default:''" json:"raw_amount"`
+}
+
+func (*Swap) TableName() string {
+	return "swaps"
+}
+
+// IsUnpriced returns true if the swap has a received token but no volume yet
+func (s *Swap) IsUnpriced() bool {
+	return s.VolumeUSD == 0 && s.RawAmount != ""
+}
+
+// TokenAmount returns the received amount in token units, 0 if the raw amount is invalid
+func (s *Swap) TokenAmount(decimals int) float64 {
+	raw, ok := new(big.Int).SetString(s.RawAmount, 10)
+	if !ok {
+		return 0
+	}
+	amount, _ := new(big.Float).Quo(new(big.Float).SetInt(raw), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))).Float64()
+	return amount
+}
+
+// NormalizeTxHash lower cases 32 bytes hex hashes and strips the 0x prefix, so the same swap reported by
+// different trackers (e.g. midgard reports upper case hashes without prefix) gets the same key.
+// Other hashes (e.g. base58 solana signatures) are case-sensitive and kept as they are.
+func NormalizeTxHash(hash string) string {
+	hash = strings.TrimSpace(hash)
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X")
+	if len(trimmed) != 64 {
+		return hash
+	}
+	if _, err := hex.DecodeString(trimmed); err == nil {
+		return strings.ToLower(trimmed)
+	}
+	return hash
+}
+
+// NormalizeSwapAddress lower cases EVM addresses, other chains use case-sensitive addresses
+func NormalizeSwapAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// AssetVolume is the swap volume of a single pool
+type AssetVolume struct {
+	Asset     string  `json:"asset"`
+	VolumeUSD float64 `json:"volume_usd"`
+	SwapCount int64   `json:"swap_count"`
+}
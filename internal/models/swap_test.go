@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTxHash(t *testing.T) {
+	evmHash := "0x7A541E32D9CE12A7413D716B2870B5D2FE2AC31A91D67EE1F765D0B24E27F774"
+	assert.Equal(t, "7a541e32d9ce12a7413d716b2870b5d2fe2ac31a91d67ee1f765d0b24e27f774", NormalizeTxHash(evmHash))
+	assert.Equal(t, "7a541e32d9ce12a7413d716b2870b5d2fe2ac31a91d67ee1f765d0b24e27f774", NormalizeTxHash(evmHash[2:]))
+	// base58 signatures are case-sensitive, even when all their characters are hex digits
+	solanaSignature := "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"
+	assert.Equal(t, solanaSignature, NormalizeTxHash(solanaSignature))
+	hexLikeSignature := "ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF1234567890ABCDEF12"
+	assert.Equal(t, hexLikeSignature, NormalizeTxHash(hexLikeSignature))
+}
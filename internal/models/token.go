@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+// Token types of the token registry
+const (
+	TokenTypeFungible = "fungible"
+	TokenTypeNFT      = "nft"
+)
+
+// Token is an entry of the token registry, the single source of token metadata used by discovery, balances and scoring
+type Token struct {
+	gorm.Model
+	Chain           common.Chain `json:"chain" binding:"required" gorm:"type:varchar(50);uniqueIndex:token_chain_contract_ticker_idx;not null"`
+	ContractAddress string       `json:"contract_address" gorm:"type:varchar(255);uniqueIndex:token_chain_contract_ticker_idx"` // empty for native coins
+	Ticker          string       `json:"ticker" gorm:"type:varchar(255);uniqueIndex:token_chain_contract_ticker_idx"`           // tells apart coins without contract
+	Decimals        int          `json:"decimals" gorm:"type:Integer"`
+	CMCId           int          `json:"cmc_id" gorm:"type:Integer"`
+	Logo            string       `json:"logo" gorm:"type:varchar(255)"`
+	Type            string       `json:"type" gorm:"type:varchar(20);default:fungible"`
+	CollectionSlug  string       `json:"collection_slug" gorm:"type:varchar(255)"` // OpenSea slug of NFT collections, used for pricing
+	ScoringEnabled  bool         `json:"scoring_enabled"`
+	Multiplier      float64      `json:"multiplier" gorm:"type:decimal(10,4)"` // boost applied when the season doesn't configure one
+}
+
+func (*Token) TableName() string {
+	return "tokens"
+}
+
+// IsNFT returns true if the token is an NFT collection
+func (t *Token) IsNFT() bool {
+	return t.Type == TokenTypeNFT
+}
+
+// Matches returns true if the token is the given chain and contract, EVM contract addresses are case-insensitive
+func (t *Token) Matches(chain common.Chain, contractAddress string) bool {
+	if t.Chain != chain {
+		return false
+	}
+	if strings.HasPrefix(t.ContractAddress, "0x") {
+		return strings.EqualFold(t.ContractAddress, contractAddress)
+	}
+	return t.ContractAddress == contractAddress
+}
+
+// CoinBase returns the coin metadata of the token
+func (t *Token) CoinBase() CoinBase {
+	return CoinBase{
+		Chain:           t.Chain,
+		Ticker:          t.Ticker,
+		ContractAddress: t.ContractAddress,
+		Decimals:        t.Decimals,
+		IsNative:        t.ContractAddress == "",
+		CMCId:           t.CMCId,
+		Logo:            t.Logo,
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+// TokenQuote is the market data of a token used to price it and guard against thin markets
+type TokenQuote struct {
+	CMCId     int
+	PriceUSD  float64
+	Volume24h float64
+	MarketCap float64
+}
+
+// Valuation statuses of a coin holding after the liquidity guard is applied
+const (
+	ValuationStatusOK       = "ok"
+	ValuationStatusCapped   = "capped"
+	ValuationStatusExcluded = "excluded"
+	ValuationStatusNoQuote  = "no_quote" // the guard is enabled but CMC returned no quote for the token
+)
+
+// CoinValuation is the outcome of the liquidity guard for a coin holding
+type CoinValuation struct {
+	CoinID           uint
+	Status           string
+	CreditedUSDValue float64
+}
@@ -28,6 +28,7 @@ type Vault struct {
 	Balance               int64   `gorm:"type:bigint;default:0" json:"balance"`                   // latest balance of the vault
 	LPValue               int64   `gorm:"type:bigint;default:0" json:"lp_value"`
 	SwapVolume            float64 `gorm:"type:decimal(65,30);default:0" json:"swap_volume"`
+	SwapVolumeRank        int64   `gorm:"type:bigint;default:0" json:"swap_volume_rank"` // season swap volume rank, 0 if the vault has no volume
 	NFTValue              int64   `gorm:"type:bigint;default:0" json:"nft_value"`
 	AvatarURL             string  `gorm:"type:varchar(255)" json:"avatar_url"`
 	AvatarCollectionID    string  `gorm:"type:varchar(255)" json:"avatar_collection_id"`
@@ -42,6 +43,19 @@ type Vault struct {
 func (*Vault) TableName() string {
 	return "vaults"
 }
+
+// GetHexPublicKey returns the public key coins of the chain are registered with
+func (v *Vault) GetHexPublicKey(chain common.Chain) (string, error) {
+	if chain.IsEdDSA() {
+		return v.EDDSA, nil
+	}
+	childPublicKey, err := tss.GetDerivedPubKey(v.ECDSA, v.HexChainCode, chain.GetDerivePath(), false)
+	if err != nil {
+		return "", fmt.Errorf("fail to get child public key")
+	}
+	return childPublicKey, nil
+}
+
 func (v *Vault) GetAddress(chain common.Chain) (string, error) {
 	derivePath := chain.GetDerivePath()
 	var childPublicKey string
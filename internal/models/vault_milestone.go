@@ -0,0 +1,38 @@
+package models
+
+import "gorm.io/gorm"
+
+// VaultMilestone records the unlock of a season milestone by a vault and the prize added to its points
+type VaultMilestone struct {
+	gorm.Model
+	VaultID        uint  `gorm:"type:bigint;not null;uniqueIndex:vault_season_milestone_idx" json:"vault_id"`
+	SeasonID       uint  `gorm:"type:bigint;not null;uniqueIndex:vault_season_milestone_idx" json:"season_id"`
+	MilestoneIndex int   `gorm:"type:int;not null;uniqueIndex:vault_season_milestone_idx" json:"milestone_index"` // position of the milestone in the season, starting at 0
+	JobID          uint  `gorm:"type:bigint;not null" json:"job_id"`                                              // job which unlocked the milestone
+	UnlockedAt     int64 `gorm:"type:bigint;not null" json:"unlocked_at"`                                         // unix time
+	Prize          int64 `gorm:"type:bigint;not null" json:"prize"`
+}
+
+func (*VaultMilestone) TableName() string {
+	return "vault_milestones"
+}
+
+// MilestoneStatus is a season milestone as seen by a vault
+type MilestoneStatus struct {
+	Index           int     `json:"index"`
+	Minimum         int64   `json:"minimum"`
+	Prize           int64   `json:"prize"`
+	Unlocked        bool    `json:"unlocked"`
+	UnlockedAt      int64   `json:"unlocked_at,omitempty"`
+	JobID           uint    `json:"job_id,omitempty"`
+	PointsRemaining float64 `json:"points_remaining"` // points the vault still needs to reach the milestone, 0 once reached
+}
+
+// VaultMilestonesResponse lists the unlocked and upcoming milestones of a vault in a season
+type VaultMilestonesResponse struct {
+	SeasonID      uint              `json:"season_id"`
+	Points        float64           `json:"points"`
+	NextMilestone *MilestoneStatus  `json:"next_milestone"` // first locked milestone, nil once all are unlocked
+	PointsToNext  float64           `json:"points_to_next"`
+	Milestones    []MilestoneStatus `json:"milestones"`
+}
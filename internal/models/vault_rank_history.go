@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// VaultRankHistory stores the rank and points of a vault after a job, so users can follow their trajectory through a season
+type VaultRankHistory struct {
+	gorm.Model
+	VaultID        uint    `gorm:"type:bigint;not null;uniqueIndex:vault_job_idx;index:vault_season_idx" json:"vault_id"`
+	JobID          uint    `gorm:"type:bigint;not null;uniqueIndex:vault_job_idx" json:"job_id"`
+	SeasonID       uint    `gorm:"type:bigint;not null;index:vault_season_idx" json:"season_id"`
+	JobDate        int64   `gorm:"type:bigint;not null" json:"job_date"` // unix time of the job date
+	Rank           int64   `gorm:"type:bigint" json:"rank"`
+	Points         float64 `json:"points"`
+	SwapVolumeRank int64   `gorm:"type:bigint;default:0" json:"swap_volume_rank"`
+}
+
+func (*VaultRankHistory) TableName() string {
+	return "vault_rank_history"
+}
+
+// VaultRankResponse is the rank and points of a vault after a job, as returned by the vault ranks endpoint
+type VaultRankResponse struct {
+	JobDate        int64   `json:"job_date"` // unix time of the job date
+	Rank           int64   `json:"rank"`
+	Points         float64 `json:"points"`
+	SwapVolumeRank int64   `json:"swap_volume_rank"`
+}
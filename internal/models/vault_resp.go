@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // VaultResponse to client side(front-end web)
 type VaultResponse struct {
 	UId                   string        `json:"uid"`
@@ -10,6 +12,7 @@ type VaultResponse struct {
 	TotalPoints           float64       `json:"total_points"`
 	JoinAirdrop           bool          `json:"join_airdrop"`
 	Rank                  int64         `json:"rank"`
+	RankDelta             int64         `json:"rank_delta"` // ranks climbed since the previous job, leaderboard only
 	SwapVolumeRank        int64         `json:"swap_volume_rank"`
 	Balance               int64         `json:"balance"`
 	LPValue               int64         `json:"lp_value"`
@@ -22,12 +25,41 @@ type VaultResponse struct {
 	ReferralCode          string        `json:"referral_code"`
 	ReferralCount         int64         `json:"referral_count"`
 	SeasonActivities      []SeasonStats `json:"season_stats"` // Needed to highlight user in the leaderboard of each season
+
+	// badges awarded to the vault, in the order they were awarded
+	Badges []VaultBadgeResponse `json:"badges"`
 }
 
 type SeasonStats struct {
-	SeasonID uint    `json:"season_id"`
-	Rank     int64   `json:"rank"`
-	Points   float64 `json:"points"`
+	SeasonID       uint    `json:"season_id"`
+	Rank           int64   `json:"rank"`
+	Points         float64 `json:"points"`
+	SwapVolume     float64 `json:"swap_volume"`
+	SwapVolumeRank int64   `json:"swap_volume_rank"`
+}
+
+// Swap leaderboard windows
+const (
+	SwapWindowSeason = "season"
+	SwapWindow7D     = "7d"
+	SwapWindow30D    = "30d"
+)
+
+// SwapWindowDuration returns the length of a rolling swap leaderboard window, false for unknown or season windows
+func SwapWindowDuration(window string) (time.Duration, bool) {
+	switch window {
+	case SwapWindow7D:
+		return 7 * 24 * time.Hour, true
+	case SwapWindow30D:
+		return 30 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// VaultSwapVolume is the swap volume of a vault in a leaderboard window
+type VaultSwapVolume struct {
+	VaultID   uint
+	VolumeUSD float64
 }
 
 type VaultsResponse struct {
@@ -37,4 +69,5 @@ type VaultsResponse struct {
 	TotalLP         int64           `json:"total_lp"`
 	TotalNFT        int64           `json:"total_nft"`
 	TotalSwapVolume float64         `json:"total_swap_volume"`
+	NextCursor      string          `json:"next_cursor,omitempty"` // cursor of the next page, empty on the last page
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VolumeCheckpoint tracks up to when the swaps of a (tracker, affiliate) pair are fetched,
+// so each volume source can fail and catch up independently
+type VolumeCheckpoint struct {
+	gorm.Model
+	Tracker     string    `gorm:"type:varchar(50);not null;uniqueIndex:volume_checkpoint_idx" json:"tracker"`
+	Affiliate   string    `gorm:"type:varchar(255);not null;uniqueIndex:volume_checkpoint_idx" json:"affiliate"`
+	LastFetched time.Time `gorm:"not null" json:"last_fetched"`    // swaps before this time are stored
+	Cursor      string    `gorm:"type:varchar(255)" json:"cursor"` // page cursor of an interrupted fetch, empty if none
+	CursorTo    int64     `json:"cursor_to"`                       // end (unix) of the window the cursor belongs to
+	LastAttempt time.Time `json:"last_attempt"`
+	LastError   string    `gorm:"type:varchar(1024)" json:"last_error"`
+}
+
+func (*VolumeCheckpoint) TableName() string {
+	return "volume_checkpoints"
+}
+
+// VolumeSourceStatus is the fetch state of a volume source reported by the job status endpoint, the error of the
+// last attempt is only flagged as it may hold details of the upstream request
+type VolumeSourceStatus struct {
+	Tracker     string    `json:"tracker"`
+	Affiliate   string    `json:"affiliate"`
+	LastFetched time.Time `json:"last_fetched"`
+	LastAttempt time.Time `json:"last_attempt"`
+	HasError    bool      `json:"has_error"`
+	IsLagging   bool      `json:"is_lagging"`
+}
+
+// JobStatus is the latest job and the state of its volume sources
+type JobStatus struct {
+	JobDate         string               `json:"job_date"`
+	IsSuccess       bool                 `json:"is_success"`
+	IsVolumeFetched bool                 `json:"is_volume_fetched"`
+	Sources         []VolumeSourceStatus `json:"sources"`
+}
@@ -0,0 +1,227 @@
+package seasons
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// Registry holds the airdrop seasons of the seasons table in memory. The current season is cached until the next
+// season boundary, so looking it up in the worker loop doesn't scan the seasons.
+type Registry struct {
+	mu      sync.RWMutex
+	seasons []config.AirdropSeason // sorted by start
+	version string                 // version of the seasons table the registry was loaded from
+
+	current      config.AirdropSeason
+	currentFrom  time.Time // the cached current season is valid from currentFrom until currentUntil
+	currentUntil time.Time
+}
+
+func NewRegistry(seasons []config.AirdropSeason) *Registry {
+	r := &Registry{}
+	r.Load(seasons, "")
+	return r
+}
+
+// Load replaces the seasons of the registry
+func (r *Registry) Load(seasons []config.AirdropSeason, version string) {
+	sorted := make([]config.AirdropSeason, len(seasons))
+	copy(sorted, seasons)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seasons = sorted
+	r.version = version
+	r.currentFrom = time.Time{}
+	r.currentUntil = time.Time{}
+}
+
+// Version returns the version of the seasons table the registry was loaded from
+func (r *Registry) Version() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// All returns the seasons sorted by start
+func (r *Registry) All() []config.AirdropSeason {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]config.AirdropSeason, len(r.seasons))
+	copy(res, r.seasons)
+	return res
+}
+
+// Get returns the season with the given id
+func (r *Registry) Get(id uint) (config.AirdropSeason, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, season := range r.seasons {
+		if season.ID == id {
+			return season, true
+		}
+	}
+	return config.AirdropSeason{}, false
+}
+
+// Next returns the first season starting after the given season ends
+func (r *Registry) Next(id uint) (config.AirdropSeason, bool) {
+	season, ok := r.Get(id)
+	if !ok {
+		return config.AirdropSeason{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, candidate := range r.seasons {
+		if candidate.ID != id && !candidate.Start.Before(season.End) {
+			return candidate, true
+		}
+	}
+	return config.AirdropSeason{}, false
+}
+
+// Open returns the season vaults registering now join: the first season by start which hasn't ended and isn't in
+// closed, i.e. the next season between seasons and the following one after a season was closed before its end.
+// Without such a season it's the last season, whose close command moves the vault once a season follows.
+func (r *Registry) Open(closed map[uint]bool) config.AirdropSeason {
+	return r.openAt(time.Now(), closed)
+}
+
+func (r *Registry) openAt(now time.Time, closed map[uint]bool) config.AirdropSeason {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, season := range r.seasons {
+		if now.Before(season.End) && !closed[season.ID] {
+			return season
+		}
+	}
+	if len(r.seasons) == 0 {
+		return config.AirdropSeason{}
+	}
+	return r.seasons[len(r.seasons)-1]
+}
+
+// Current returns the season running now, a zero season between seasons
+func (r *Registry) Current() config.AirdropSeason {
+	return r.currentAt(time.Now())
+}
+
+func (r *Registry) currentAt(now time.Time) config.AirdropSeason {
+	r.mu.RLock()
+	if !now.Before(r.currentFrom) && now.Before(r.currentUntil) {
+		defer r.mu.RUnlock()
+		return r.current
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = config.AirdropSeason{}
+	r.currentFrom = now
+	// the current season can only change at the next start or end of a season
+	r.currentUntil = time.Unix(1<<62, 0)
+	for _, season := range r.seasons {
+		if now.After(season.Start) && now.Before(season.End) {
+			r.current = season
+		}
+		for _, boundary := range []time.Time{season.Start, season.End} {
+			if !boundary.Before(now) && boundary.Before(r.currentUntil) {
+				r.currentUntil = boundary
+			}
+		}
+	}
+	return r.current
+}
+
+// FromModel returns the airdrop season of a seasons table row
+func FromModel(season models.Season) config.AirdropSeason {
+	res := config.AirdropSeason{
+		ID:         season.SeasonID,
+		Start:      time.Unix(season.Start, 0).UTC(),
+		End:        time.Unix(season.End, 0).UTC(),
+		Milestones: make([]config.Milestone, 0, len(season.Milestones)),
+		NFTs:       make([]config.NFT, 0, len(season.NFTs)),
+		Tokens:     make([]config.Token, 0, len(season.Tokens)),
+		Referral: config.ReferralScheme{
+			DirectShare:      season.Referral.DirectShare,
+			SecondLevelShare: season.Referral.SecondLevelShare,
+			MaxPointsPerJob:  season.Referral.MaxPointsPerJob,
+		},
+	}
+	milestones := make([]models.SeasonMilestone, len(season.Milestones))
+	copy(milestones, season.Milestones)
+	sort.SliceStable(milestones, func(i, j int) bool {
+		return milestones[i].Position < milestones[j].Position
+	})
+	for _, milestone := range milestones {
+		res.Milestones = append(res.Milestones, config.Milestone{Minimum: int(milestone.Minimum), Prize: int(milestone.Prize)})
+	}
+	for _, nft := range season.NFTs {
+		res.NFTs = append(res.NFTs, config.NFT{
+			Token: config.Token{
+				Multiplier:      nft.Multiplier,
+				Name:            nft.Name,
+				Chain:           nft.Chain,
+				ContractAddress: nft.ContractAddress,
+			},
+			CollectionName: nft.CollectionName,
+		})
+	}
+	for _, token := range season.Tokens {
+		res.Tokens = append(res.Tokens, config.Token{
+			Multiplier:      token.Multiplier,
+			Name:            token.Name,
+			Chain:           token.Chain,
+			ContractAddress: token.ContractAddress,
+		})
+	}
+	return res
+}
+
+// ToModel returns the seasons table row of an airdrop season, used to seed the table from the config file
+func ToModel(season config.AirdropSeason) models.Season {
+	res := models.Season{
+		SeasonID: season.ID,
+		Start:    season.Start.UTC().Unix(),
+		End:      season.End.UTC().Unix(),
+		Referral: models.SeasonReferral{
+			DirectShare:      season.Referral.DirectShare,
+			SecondLevelShare: season.Referral.SecondLevelShare,
+			MaxPointsPerJob:  season.Referral.MaxPointsPerJob,
+		},
+	}
+	for i, milestone := range season.Milestones {
+		res.Milestones = append(res.Milestones, models.SeasonMilestone{
+			SeasonID: season.ID,
+			Position: i,
+			Minimum:  int64(milestone.Minimum),
+			Prize:    int64(milestone.Prize),
+		})
+	}
+	for _, nft := range season.NFTs {
+		res.NFTs = append(res.NFTs, models.SeasonNFT{
+			SeasonID:        season.ID,
+			Name:            nft.Name,
+			CollectionName:  nft.CollectionName,
+			Chain:           nft.Chain,
+			ContractAddress: nft.ContractAddress,
+			Multiplier:      nft.Multiplier,
+		})
+	}
+	for _, token := range season.Tokens {
+		res.Tokens = append(res.Tokens, models.SeasonToken{
+			SeasonID:        season.ID,
+			Name:            token.Name,
+			Chain:           token.Chain,
+			ContractAddress: token.ContractAddress,
+			Multiplier:      token.Multiplier,
+		})
+	}
+	return res
+}
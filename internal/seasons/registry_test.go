@@ -0,0 +1,74 @@
+package seasons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/config"
+)
+
+func TestRegistry(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewRegistry([]config.AirdropSeason{
+		{ID: 3, Start: start.AddDate(0, 6, 0), End: start.AddDate(0, 9, 0)},
+		{ID: 1, Start: start, End: start.AddDate(0, 3, 0)},
+		{ID: 2, Start: start.AddDate(0, 3, 0), End: start.AddDate(0, 5, 0)},
+	})
+
+	all := registry.All()
+	assert.Equal(t, []uint{1, 2, 3}, []uint{all[0].ID, all[1].ID, all[2].ID})
+
+	next, ok := registry.Next(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint(2), next.ID)
+	next, ok = registry.Next(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), next.ID)
+	_, ok = registry.Next(3)
+	assert.False(t, ok)
+	_, ok = registry.Next(4)
+	assert.False(t, ok)
+
+	assert.Equal(t, uint(1), registry.currentAt(start.AddDate(0, 1, 0)).ID)
+	// the cached season is dropped at the season end
+	assert.Equal(t, uint(2), registry.currentAt(start.AddDate(0, 4, 0)).ID)
+	// between seasons
+	assert.Equal(t, uint(0), registry.currentAt(start.AddDate(0, 5, 10)).ID)
+	assert.True(t, registry.currentAt(start.AddDate(0, 5, 10)).Start.IsZero())
+	assert.Equal(t, uint(3), registry.currentAt(start.AddDate(0, 7, 0)).ID)
+	assert.Equal(t, uint(0), registry.currentAt(start.AddDate(1, 0, 0)).ID)
+
+	// vaults join the running season, the next one between seasons and the following one once a season is closed
+	assert.Equal(t, uint(1), registry.openAt(start.AddDate(0, 1, 0), nil).ID)
+	assert.Equal(t, uint(2), registry.openAt(start.AddDate(0, 1, 0), map[uint]bool{1: true}).ID)
+	assert.Equal(t, uint(3), registry.openAt(start.AddDate(0, 5, 10), nil).ID)
+	assert.Equal(t, uint(3), registry.openAt(start.AddDate(1, 0, 0), nil).ID)
+
+	// loading drops the cached season
+	registry.Load([]config.AirdropSeason{{ID: 4, Start: start, End: start.AddDate(2, 0, 0)}}, "1")
+	assert.Equal(t, uint(4), registry.currentAt(start.AddDate(1, 0, 0)).ID)
+	assert.Equal(t, "1", registry.Version())
+}
+
+func TestModelConversion(t *testing.T) {
+	season := config.AirdropSeason{
+		ID:         2,
+		Start:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:        time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		Milestones: []config.Milestone{{Minimum: 5000, Prize: 100}, {Minimum: 10000, Prize: 300}},
+		NFTs: []config.NFT{{
+			Token:          config.Token{Multiplier: 1.5, Name: "THORGUARDS", Chain: "Ethereum", ContractAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291"},
+			CollectionName: "thorguards",
+		}},
+		Tokens:   []config.Token{{Multiplier: 2, Name: "VULT", Chain: "Ethereum", ContractAddress: "0xb788144df611029c60b859df47e79b7726c4deba"}},
+		Referral: config.ReferralScheme{DirectShare: 0.1, SecondLevelShare: 0.02, MaxPointsPerJob: 500},
+	}
+	model := ToModel(season)
+	assert.Equal(t, uint(2), model.SeasonID)
+	assert.Equal(t, 1, model.Milestones[1].Position)
+	// milestones are ordered by position
+	model.Milestones[0], model.Milestones[1] = model.Milestones[1], model.Milestones[0]
+	assert.Equal(t, season, FromModel(model))
+}
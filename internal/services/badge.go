@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+const (
+	badgePointsThreshold    = 100_000
+	badgeReferralsThreshold = 10
+	badgeHolderMinValue     = 50 // usd a vault must still hold when the season closes for the season holder badge
+)
+
+// badgeRule selects the ids of the vaults meeting the criteria of a badge
+type badgeRule struct {
+	query string
+	args  []interface{}
+}
+
+// badgeRules are the badges awarded in bulk after every job, the thorguard holder badge is awarded while the job
+// fetches the nft balances
+var badgeRules = map[string]badgeRule{
+	models.BadgeFirstSwap: {
+		query: `SELECT vaults.id FROM vaults
+			WHERE vaults.deleted_at IS NULL AND vaults.join_airdrop = 1
+				AND EXISTS (SELECT 1 FROM swaps WHERE swaps.vault_id = vaults.id AND swaps.deleted_at IS NULL)`,
+	},
+	models.BadgePoints100K: {
+		query: `SELECT vaults.id FROM vaults
+			WHERE vaults.deleted_at IS NULL AND vaults.join_airdrop = 1 AND vaults.total_points >= ?`,
+		args: []interface{}{badgePointsThreshold},
+	},
+	models.BadgeReferred10: {
+		query: `SELECT vaults.id FROM vaults
+			WHERE vaults.deleted_at IS NULL AND vaults.join_airdrop = 1 AND vaults.referral_count >= ?`,
+		args: []interface{}{badgeReferralsThreshold},
+	},
+	models.BadgeSeasonHolder: {
+		query: `SELECT DISTINCT vaults.id FROM vault_season_stats
+			JOIN seasons ON seasons.season_id = vault_season_stats.season_id AND seasons.deleted_at IS NULL
+			JOIN vaults ON vaults.id = vault_season_stats.vault_id AND vaults.deleted_at IS NULL AND vaults.join_airdrop = 1
+			WHERE vault_season_stats.deleted_at IS NULL AND UNIX_TIMESTAMP(vaults.created_at) <= seasons.start
+				AND vault_season_stats.balance + vault_season_stats.lp_value + vault_season_stats.nft_value >= ?`,
+		args: []interface{}{badgeHolderMinValue},
+	},
+}
+
+// SeedBadges stores the definitions of the badges evaluated by the worker which aren't stored yet
+func (s *Storage) SeedBadges() error {
+	badges := make([]models.Badge, len(models.DefaultBadges))
+	copy(badges, models.DefaultBadges)
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&badges).Error; err != nil {
+		return fmt.Errorf("failed to seed badges: %w", err)
+	}
+	return nil
+}
+
+// SyncBadges creates or updates the badge definitions by code
+func (s *Storage) SyncBadges(badges []models.Badge) error {
+	if len(badges) == 0 {
+		return nil
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "icon", "color", "start_date", "end_date", "source", "updated_at"}),
+	}).Create(&badges).Error
+	if err != nil {
+		return fmt.Errorf("failed to sync badges: %w", err)
+	}
+	return nil
+}
+
+// activeBadgeCondition restricts the badges to the ones which can be awarded at the given unix time
+const activeBadgeCondition = `badges.deleted_at IS NULL AND (badges.start_date = 0 OR badges.start_date <= ?) AND (badges.end_date = 0 OR badges.end_date >= ?)`
+
+// AwardBadges awards the badges of badgeRules to the vaults meeting their criteria, it returns the number of awards
+func (s *Storage) AwardBadges(seasonID uint, now time.Time) (int64, error) {
+	var awarded int64
+	for code, rule := range badgeRules {
+		qry := `INSERT IGNORE INTO vault_badges (created_at, updated_at, vault_id, badge_code, season_id, awarded_at)
+			SELECT NOW(), NOW(), candidates.id, badges.code, ?, ?
+			FROM (` + rule.query + `) AS candidates
+			JOIN badges ON badges.code = ? AND ` + activeBadgeCondition
+		args := []interface{}{seasonID, now.Unix()}
+		args = append(args, rule.args...)
+		args = append(args, code, now.Unix(), now.Unix())
+		result := s.db.Exec(qry, args...)
+		if result.Error != nil {
+			return awarded, fmt.Errorf("failed to award badge %s: %w", code, result.Error)
+		}
+		awarded += result.RowsAffected
+	}
+	return awarded, nil
+}
+
+// AwardVaultBadge awards a badge to a vault, unless the badge isn't active or the vault already has it
+func (s *Storage) AwardVaultBadge(vaultID uint, code string, seasonID uint, now time.Time) error {
+	qry := `INSERT IGNORE INTO vault_badges (created_at, updated_at, vault_id, badge_code, season_id, awarded_at)
+		SELECT NOW(), NOW(), ?, badges.code, ?, ? FROM badges WHERE badges.code = ? AND ` + activeBadgeCondition
+	if err := s.db.Exec(qry, vaultID, seasonID, now.Unix(), code, now.Unix(), now.Unix()).Error; err != nil {
+		return fmt.Errorf("failed to award badge %s: %w", code, err)
+	}
+	return nil
+}
+
+// GetVaultBadges returns the badges awarded to a vault, in the order they were awarded
+func (s *Storage) GetVaultBadges(vaultID uint) ([]models.VaultBadgeResponse, error) {
+	badges := make([]models.VaultBadgeResponse, 0)
+	err := s.db.Table("vault_badges").
+		Select("badges.code, badges.name, badges.description, badges.icon, badges.color, vault_badges.season_id, vault_badges.awarded_at").
+		Joins("JOIN badges ON badges.code = vault_badges.badge_code AND badges.deleted_at IS NULL").
+		Where("vault_badges.vault_id = ? AND vault_badges.deleted_at IS NULL", vaultID).
+		Order("vault_badges.awarded_at asc, vault_badges.id asc").
+		Scan(&badges).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault badges: %w", err)
+	}
+	return badges, nil
+}
@@ -2,11 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/vultisig/airdrop-registry/internal/common"
 	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 )
 
 // AddCoin adds a coin to the vault
@@ -17,6 +22,77 @@ func (s *Storage) AddCoin(coin *models.CoinDBModel) error {
 	return nil
 }
 
+// AddCoins adds the coins in a single transaction, either all coins are added or none. It returns the ids of the added coins.
+func (s *Storage) AddCoins(coins []models.CoinDBModel) ([]uint, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range coins {
+			if err := tx.Create(&coins[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add coins: %w", err)
+	}
+	ids := make([]uint, 0, len(coins))
+	for _, coin := range coins {
+		ids = append(ids, coin.ID)
+	}
+	return ids, nil
+}
+
+// AddDiscoveredCoins adds the discovered tokens the vault doesn't have yet as coins,
+// tokens without the metadata needed to price them are skipped. It returns the ids of the added coins;
+// a coin which fails to be added doesn't stop the others, the failures are returned joined along with the ids.
+func (s *Storage) AddDiscoveredCoins(vault *models.Vault, discovered []models.CoinBase) ([]uint, error) {
+	existing, err := s.GetCoins(vault.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0)
+	var errs []error
+	for _, coin := range discovered {
+		if !tokens.IsCompleteToken(coin) || hasCoin(existing, coin) {
+			continue
+		}
+		hexPublicKey, err := vault.GetHexPublicKey(coin.Chain)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get public key of %s: %w", coin.Chain, err))
+			continue
+		}
+		coin.HexPublicKey = hexPublicKey
+		coin.Balance = ""
+		coin.USDValue = ""
+		coin.PriceUSD = ""
+		coinDB := models.CoinDBModel{
+			CoinBase: coin,
+			VaultID:  vault.ID,
+		}
+		if err := s.AddCoin(&coinDB); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", coin.Chain, coin.ContractAddress, err))
+			continue
+		}
+		existing = append(existing, coinDB)
+		ids = append(ids, coinDB.ID)
+	}
+	return ids, errors.Join(errs...)
+}
+
+// hasCoin returns true if coins has the same token as coin, or a coin with the same chain, ticker and address
+// (the unique key of the coins table)
+func hasCoin(coins []models.CoinDBModel, coin models.CoinBase) bool {
+	for i := range coins {
+		if tokens.IsSameToken(coins[i].CoinBase, coin) {
+			return true
+		}
+		if coins[i].Chain == coin.Chain && strings.EqualFold(coins[i].Ticker, coin.Ticker) && strings.EqualFold(coins[i].Address, coin.Address) {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteCoin deletes a coin by its ID , and the vault id
 func (s *Storage) DeleteCoin(coinID string, vaultID uint) error {
 	if err := s.db.Where("id = ? AND vault_id = ?", coinID, vaultID).Unscoped().Delete(&models.CoinDBModel{}).Error; err != nil {
@@ -60,6 +136,16 @@ func (s *Storage) UpdateCoinPrice(chain common.Chain, ticker string, priceUSD fl
 	return nil
 }
 
+func (s *Storage) UpdateCoinPriceByContract(chain common.Chain, contractAddress string, priceUSD float64) error {
+	qry := `UPDATE coins SET price_usd = ? WHERE chain = ? AND contract_address = ?`
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Exec(qry, priceUSD, chain.String(), contractAddress).Error; err != nil {
+		return fmt.Errorf("failed to update coin price: %w", err)
+	}
+	return nil
+}
+
 func (s *Storage) UpdateCoinPriceByCMCID(cmcID int, priceUSD float64) error {
 	qry := `UPDATE coins SET price_usd = ? WHERE cmc_id = ? `
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
@@ -101,3 +187,53 @@ func (s *Storage) UpdateCoinBalance(coinID uint64, balance float64) error {
 	}
 	return nil
 }
+
+// UpdateCoinValuations records the outcome of the liquidity guard for the given coins in a single update
+func (s *Storage) UpdateCoinValuations(valuations []models.CoinValuation) error {
+	if len(valuations) == 0 {
+		return nil
+	}
+	statusArgs := make([]any, 0, 2*len(valuations))
+	valueArgs := make([]any, 0, 2*len(valuations))
+	ids := make([]uint, 0, len(valuations))
+	for _, v := range valuations {
+		statusArgs = append(statusArgs, v.CoinID, v.Status)
+		valueArgs = append(valueArgs, v.CoinID, v.CreditedUSDValue)
+		ids = append(ids, v.CoinID)
+	}
+	cases := strings.Repeat(" WHEN ? THEN ?", len(valuations))
+	qry := `UPDATE coins SET valuation_status = CASE id` + cases + ` END, credited_usd_value = CASE id` + cases + ` END WHERE id IN ?`
+	args := append(append(statusArgs, valueArgs...), ids)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Exec(qry, args...).Error; err != nil {
+		return fmt.Errorf("failed to update coin valuations: %w", err)
+	}
+	return nil
+}
+
+// FixCoinsMetadata corrects the decimals, CMC id and logo of the given coins in a single transaction,
+// either all coins are updated or none
+func (s *Storage) FixCoinsMetadata(coins []models.CoinDBModel) error {
+	if len(coins) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, coin := range coins {
+			if err := tx.Model(&models.CoinDBModel{}).Where("id = ?", coin.ID).Updates(map[string]any{
+				"decimals": coin.Decimals,
+				"cmc_id":   coin.CMCId,
+				"logo":     coin.Logo,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update coin %d: %w", coin.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fix coins metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestHasCoin(t *testing.T) {
+	existing := []models.CoinDBModel{
+		{CoinBase: models.CoinBase{Chain: common.Ethereum, Ticker: "USDC", Address: "0xvault", ContractAddress: "0xusdc"}},
+	}
+	tests := []struct {
+		name     string
+		coin     models.CoinBase
+		expected bool
+	}{
+		{
+			name:     "same token",
+			coin:     models.CoinBase{Chain: common.Ethereum, Ticker: "USDC.e", Address: "0xvault", ContractAddress: "0xUSDC"},
+			expected: true,
+		},
+		{
+			name:     "same chain, ticker and address with another contract",
+			coin:     models.CoinBase{Chain: common.Ethereum, Ticker: "usdc", Address: "0xVault", ContractAddress: "0xfake"},
+			expected: true,
+		},
+		{
+			name:     "other token",
+			coin:     models.CoinBase{Chain: common.Ethereum, Ticker: "USDT", Address: "0xvault", ContractAddress: "0xusdt"},
+			expected: false,
+		},
+		{
+			name:     "same token on another chain",
+			coin:     models.CoinBase{Chain: common.Base, Ticker: "USDC", Address: "0xvault", ContractAddress: "0xusdc"},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasCoin(existing, tt.coin))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// WriteLeaderboardSnapshot replaces the leaderboard snapshot with the current vault ranks,
+// the ranks of the replaced snapshot are kept as previous ranks
+func (s *Storage) WriteLeaderboardSnapshot() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	db := s.db.WithContext(ctx)
+
+	var previous []models.LeaderboardSnapshot
+	if err := db.Select("vault_id", "`rank`").Where("vault_id > 0").Find(&previous).Error; err != nil {
+		return fmt.Errorf("failed to get previous leaderboard snapshot: %w", err)
+	}
+	previousRanks := make(map[uint]int64, len(previous))
+	for _, entry := range previous {
+		previousRanks[entry.VaultID] = entry.Rank
+	}
+	var vaults []models.Vault
+	if err := db.Select("id", "uid", "alias", "show_name_in_leaderboard", "avatar_url", "`rank`", "total_points", "balance", "lp_value", "nft_value", "created_at").
+		Where("join_airdrop = 1 AND `rank` > 0").
+		Order("`rank` asc").
+		Find(&vaults).Error; err != nil {
+		return fmt.Errorf("failed to get ranked vaults: %w", err)
+	}
+	totals := models.LeaderboardSnapshot{VaultCount: int64(len(vaults))}
+	if err := db.Model(&models.Vault{}).
+		Select("COALESCE(SUM(balance), 0), COALESCE(SUM(lp_value), 0), COALESCE(SUM(nft_value), 0)").
+		Row().Scan(&totals.Balance, &totals.LPValue, &totals.NFTValue); err != nil {
+		return fmt.Errorf("failed to get leaderboard totals: %w", err)
+	}
+	snapshots := append(buildLeaderboardSnapshots(vaults, previousRanks), totals)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM leaderboard_snapshots").Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(snapshots, 1000).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write leaderboard snapshot: %w", err)
+	}
+	return nil
+}
+
+// buildLeaderboardSnapshots returns the snapshot entries of the ranked vaults
+func buildLeaderboardSnapshots(vaults []models.Vault, previousRanks map[uint]int64) []models.LeaderboardSnapshot {
+	snapshots := make([]models.LeaderboardSnapshot, 0, len(vaults)+1)
+	for _, vault := range vaults {
+		snapshots = append(snapshots, models.LeaderboardSnapshot{
+			VaultID:               vault.ID,
+			Uid:                   vault.Uid,
+			Alias:                 vault.Alias,
+			ShowNameInLeaderboard: vault.ShowNameInLeaderboard,
+			AvatarURL:             vault.AvatarURL,
+			Rank:                  vault.Rank,
+			PreviousRank:          previousRanks[vault.ID],
+			TotalPoints:           vault.TotalPoints,
+			Balance:               vault.Balance,
+			LPValue:               vault.LPValue,
+			NFTValue:              vault.NFTValue,
+			RegisteredAt:          vault.CreatedAt.UTC().Unix(),
+		})
+	}
+	return snapshots
+}
+
+// GetLeaderboardSnapshotTotals returns the totals row of the leaderboard snapshot, gorm.ErrRecordNotFound if no snapshot was written yet
+func (s *Storage) GetLeaderboardSnapshotTotals() (*models.LeaderboardSnapshot, error) {
+	var totals models.LeaderboardSnapshot
+	if err := s.db.Where("vault_id = 0").First(&totals).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get leaderboard snapshot totals: %w", err)
+	}
+	return &totals, nil
+}
+
+// leaderboardSnapshotColumns are the snapshot columns served with the name settings of the live vault, so renames,
+// opt-outs and deleted vaults show up before the next snapshot
+const leaderboardSnapshotColumns = "leaderboard_snapshots.id, leaderboard_snapshots.created_at, leaderboard_snapshots.updated_at, " +
+	"leaderboard_snapshots.vault_id, leaderboard_snapshots.uid, vaults.alias, vaults.show_name_in_leaderboard, vaults.avatar_url, " +
+	"leaderboard_snapshots.`rank`, leaderboard_snapshots.previous_rank, leaderboard_snapshots.total_points, leaderboard_snapshots.balance, " +
+	"leaderboard_snapshots.lp_value, leaderboard_snapshots.nft_value, leaderboard_snapshots.vault_count, leaderboard_snapshots.registered_at"
+
+// leaderboardSnapshotQuery returns the query of the vault entries of the snapshot joined with their live vault
+func (s *Storage) leaderboardSnapshotQuery() *gorm.DB {
+	return s.db.Model(&models.LeaderboardSnapshot{}).
+		Select(leaderboardSnapshotColumns).
+		Joins("JOIN vaults ON vaults.id = leaderboard_snapshots.vault_id AND vaults.deleted_at IS NULL").
+		Where("leaderboard_snapshots.vault_id > 0")
+}
+
+// GetLeaderboardSnapshots returns the snapshot entries matching filter ranked after fromRank
+func (s *Storage) GetLeaderboardSnapshots(fromRank int64, limit int, filter models.LeaderboardFilter) ([]models.LeaderboardSnapshot, error) {
+	var snapshots []models.LeaderboardSnapshot
+	qry := s.leaderboardSnapshotQuery().Where("leaderboard_snapshots.`rank` > ?", fromRank)
+	if filter.AliasPrefix != "" {
+		qry = qry.Where("vaults.show_name_in_leaderboard = 1 AND vaults.alias LIKE ?", escapeLike(filter.AliasPrefix)+"%")
+	}
+	if filter.MinPoints > 0 {
+		qry = qry.Where("leaderboard_snapshots.total_points >= ?", filter.MinPoints)
+	}
+	if filter.Chain != nil {
+		// usd_value is a varchar column, compared as a decimal rather than relying on the implicit conversion
+		qry = qry.Where("leaderboard_snapshots.vault_id IN (SELECT vault_id FROM coins WHERE chain = ? AND CAST(usd_value AS DECIMAL(65,30)) > 0 AND deleted_at IS NULL)", *filter.Chain)
+	}
+	if err := qry.Order("leaderboard_snapshots.`rank` asc").Limit(limit).Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetLeaderboardSnapshotsAround returns the snapshot entries of the n ranks above and below the vault of the given uid,
+// gorm.ErrRecordNotFound if the vault isn't in the snapshot
+func (s *Storage) GetLeaderboardSnapshotsAround(uid string, n int64) ([]models.LeaderboardSnapshot, error) {
+	var entry models.LeaderboardSnapshot
+	if err := s.leaderboardSnapshotQuery().Where("leaderboard_snapshots.uid = ?", uid).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get leaderboard snapshot: %w", err)
+	}
+	var snapshots []models.LeaderboardSnapshot
+	if err := s.leaderboardSnapshotQuery().Where("leaderboard_snapshots.`rank` BETWEEN ? AND ?", entry.Rank-n, entry.Rank+n).
+		Order("leaderboard_snapshots.`rank` asc").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// escapeLike escapes the LIKE wildcards of a user supplied pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestBuildLeaderboardSnapshots(t *testing.T) {
+	registeredAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	vaults := []models.Vault{
+		{Model: gorm.Model{ID: 7, CreatedAt: registeredAt}, Uid: "uid7", Alias: "climber", ShowNameInLeaderboard: true, Rank: 1, TotalPoints: 300},
+		{Model: gorm.Model{ID: 3, CreatedAt: registeredAt}, Uid: "uid3", Rank: 2, TotalPoints: 200},
+		{Model: gorm.Model{ID: 9, CreatedAt: registeredAt}, Uid: "uid9", Rank: 3, TotalPoints: 100},
+	}
+	previousRanks := map[uint]int64{7: 4, 3: 1}
+
+	snapshots := buildLeaderboardSnapshots(vaults, previousRanks)
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, uint(7), snapshots[0].VaultID)
+	assert.Equal(t, "climber", snapshots[0].Alias)
+	assert.Equal(t, registeredAt.Unix(), snapshots[0].RegisteredAt)
+	assert.Equal(t, int64(3), snapshots[0].RankDelta())
+	assert.Equal(t, int64(-1), snapshots[1].RankDelta())
+	// vaults ranked for the first time don't move
+	assert.Equal(t, int64(0), snapshots[2].PreviousRank)
+	assert.Equal(t, int64(0), snapshots[2].RankDelta())
+}
+
+func TestLeaderboardName(t *testing.T) {
+	assert.Equal(t, "alias", models.LeaderboardName("alias", "0123456789abcdef", true))
+	assert.Equal(t, "0123456789", models.LeaderboardName("alias", "0123456789abcdef", false))
+	assert.Equal(t, "0123", models.LeaderboardName("alias", "0123", false))
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "vault", expected: "vault"},
+		{value: "100%", expected: `100\%`},
+		{value: "my_vault", expected: `my\_vault`},
+		{value: `back\slash`, expected: `back\\slash`},
+		{value: `%_\`, expected: `\%\_\\`},
+		{value: "", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, escapeLike(tt.value))
+		})
+	}
+}
@@ -3,11 +3,15 @@ package services
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
@@ -16,33 +20,44 @@ import (
 	"github.com/vultisig/airdrop-registry/internal/common"
 	"github.com/vultisig/airdrop-registry/internal/liquidity"
 	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
 	"github.com/vultisig/airdrop-registry/internal/stake"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
 	"github.com/vultisig/airdrop-registry/internal/utils"
 	"github.com/vultisig/airdrop-registry/internal/volume"
 )
 
 const MinBalanceForValidReferral = 50 // 50 USDT
+
+const thorGuardCollectionSlug = "thorguards" // holders are awarded the thorguard holder badge
+
+const valuationBatchSize = 500 // coin valuations a worker buffers before writing them
+
 // PointWorker is a worker that processes points
 type PointWorker struct {
-	logger                 *logrus.Logger
-	storage                *Storage
-	priceResolver          *PriceResolver
-	balanceResolver        *balance.BalanceResolver
-	lpResolver             *liquidity.LiquidityPositionResolver
-	saverResolver          *liquidity.SaverPositionResolver
-	referralResolver       *ReferralResolverService
-	volumeResolver         *volume.VolumeResolver
-	startCoinID            int64
-	wg                     *sync.WaitGroup
-	stopChan               chan struct{}
-	cfg                    *config.Config
-	isJobInProgress        bool
-	isVolumeFetched        bool // flag to indicate if volume fetched successfully
-	whitelistNFTCollection []models.NFTCollection
-	rujiraStakeResolver    *stake.RujiraStakeResolver
-}
-
-func NewPointWorker(cfg *config.Config, storage *Storage, priceResolver *PriceResolver, balanceResolver *balance.BalanceResolver, volumeResolver *volume.VolumeResolver, referralResolver *ReferralResolverService) (*PointWorker, error) {
+	logger              *logrus.Logger
+	storage             *Storage
+	priceResolver       *PriceResolver
+	balanceResolver     *balance.BalanceResolver
+	lpResolver          *liquidity.LiquidityPositionResolver
+	saverResolver       *liquidity.SaverPositionResolver
+	referralResolver    *ReferralResolverService
+	volumeResolver      *volume.VolumeResolver
+	startCoinID         int64
+	wg                  *sync.WaitGroup
+	stopChan            chan struct{}
+	cfg                 *config.Config
+	isJobInProgress     bool
+	isVolumeFetched     bool // flag to indicate if volume fetched successfully
+	tokenRegistry       *tokens.Registry
+	seasonRegistry      *seasons.Registry
+	rujiraStakeResolver *stake.RujiraStakeResolver
+	tokenQuotes         map[int]models.TokenQuote // CMC quotes of the current job, keyed by cmc id
+	tokenDiscovery      *tokens.VaultDiscoveryService
+	discoveredAddresses *cache.Cache // addresses discovered recently, keyed by chain and address
+}
+
+func NewPointWorker(cfg *config.Config, storage *Storage, priceResolver *PriceResolver, balanceResolver *balance.BalanceResolver, volumeResolver *volume.VolumeResolver, referralResolver *ReferralResolverService, tokenRegistry *tokens.Registry, seasonRegistry *seasons.Registry, tokenDiscovery *tokens.VaultDiscoveryService) (*PointWorker, error) {
 
 	if nil == storage {
 		return nil, fmt.Errorf("storage is nil")
@@ -50,34 +65,44 @@ func NewPointWorker(cfg *config.Config, storage *Storage, priceResolver *PriceRe
 	if nil == priceResolver {
 		return nil, fmt.Errorf("priceResolver is nil")
 	}
+	if nil == tokenRegistry {
+		return nil, fmt.Errorf("tokenRegistry is nil")
+	}
+	if nil == seasonRegistry {
+		return nil, fmt.Errorf("seasonRegistry is nil")
+	}
+	if cfg.TokenDiscovery.Enabled && nil == tokenDiscovery {
+		return nil, fmt.Errorf("tokenDiscovery is nil")
+	}
 
 	return &PointWorker{
-		logger:           logrus.WithField("module", "point_worker").Logger,
-		storage:          storage,
-		priceResolver:    priceResolver,
-		balanceResolver:  balanceResolver,
-		lpResolver:       liquidity.NewLiquidtyPositionResolver(),
-		referralResolver: referralResolver,
-		saverResolver:    liquidity.NewSaverPositionResolver(),
-		volumeResolver:   volumeResolver,
-		startCoinID:      cfg.Worker.StartID,
-		stopChan:         make(chan struct{}),
-		wg:               &sync.WaitGroup{},
-		cfg:              cfg,
-		whitelistNFTCollection: []models.NFTCollection{
-			{
-				Chain:             common.Ethereum,
-				CollectionAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291",
-				CollectionSlug:    "thorguards",
-			},
-		},
+		logger:              logrus.WithField("module", "point_worker").Logger,
+		storage:             storage,
+		priceResolver:       priceResolver,
+		balanceResolver:     balanceResolver,
+		lpResolver:          liquidity.NewLiquidtyPositionResolver(),
+		referralResolver:    referralResolver,
+		saverResolver:       liquidity.NewSaverPositionResolver(),
+		volumeResolver:      volumeResolver,
+		startCoinID:         cfg.Worker.StartID,
+		stopChan:            make(chan struct{}),
+		wg:                  &sync.WaitGroup{},
+		cfg:                 cfg,
+		tokenRegistry:       tokenRegistry,
+		seasonRegistry:      seasonRegistry,
 		rujiraStakeResolver: stake.NewRujiraStakeResolver(),
+		tokenDiscovery:      tokenDiscovery,
+		discoveredAddresses: cache.New(time.Duration(cfg.TokenDiscovery.CacheTTLHours)*time.Hour, time.Hour),
 	}, nil
 }
 
 func (p *PointWorker) Run() error {
-	p.wg.Add(1)
+	p.wg.Add(2)
 	go p.scheduler()
+	go func() {
+		defer p.wg.Done()
+		p.storage.WatchSeasonRegistry(p.seasonRegistry, time.Duration(p.cfg.SeasonReloadSeconds)*time.Second, p.stopChan)
+	}()
 	return nil
 }
 func (p *PointWorker) scheduler() {
@@ -157,46 +182,249 @@ func (p *PointWorker) startJob(job *models.Job) {
 		p.logger.Infof("continue lp calculation job %s from %d", job.JobDate.Format("2006-01-02"), job.CurrentVaultID)
 	}
 
-	if err := p.updateCoinPrice(); err != nil {
-		p.logger.Errorf("failed to update coin prices: %e", err)
+	// the season is captured once, a season change while the job runs is picked up by the next job
+	season := p.seasonRegistry.Current()
+	// seasons are rolled over by the season close command, scoring vaults of an unclosed season would mix the seasons
+	pending, err := p.storage.GetVaultCountBeforeSeason(season.ID)
+	if err != nil {
+		p.logger.Errorf("failed to count vaults of previous seasons: %e", err)
 		return
 	}
-	//default value for lastVolumeFetch is first of June 2025
-	lastVolumeFetch := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
-	lastVolumeJob, err := p.storage.GetLastVolumeFetch()
-	if err == nil {
-		lastVolumeFetch = models.GetDate(lastVolumeJob.JobDate)
+	if pending > 0 {
+		p.logger.Errorf("%d vaults are in a previous season, run `season close` before scoring season %d", pending, season.ID)
+		return
+	}
+	// a season closed before its end (season close --force) is not scored anymore, the next season is scored once it starts
+	if _, err := p.storage.GetSeasonClosure(season.ID); err == nil {
+		p.logger.Errorf("season %d is closed, waiting for the next season to start", season.ID)
+		return
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		p.logger.Errorf("failed to get last volume fetch: %e", err)
+		p.logger.Errorf("failed to get season closure: %e", err)
 		return
 	}
-
-	// TODO: make sure logic for from/to is correct
-	if err := p.volumeResolver.LoadVolume(lastVolumeFetch, models.GetDate(job.JobDate)); err != nil {
+	ahead, err := p.storage.GetVaultCountAfterSeason(season.ID)
+	if err != nil {
+		p.logger.Errorf("failed to count vaults of next seasons: %e", err)
+		return
+	}
+	if ahead > 0 {
+		p.logger.Errorf("%d vaults are in a season after %d, waiting for the next season to start", ahead, season.ID)
+		return
+	}
+	// pick up the token registry changes made through the admin endpoints since the last job
+	if err := p.storage.LoadTokenRegistry(p.tokenRegistry); err != nil {
+		p.logger.Errorf("failed to load token registry: %e", err)
+		return
+	}
+	p.syncBadges()
+	if err := p.updateCoinPrice(job); err != nil {
+		p.logger.Errorf("failed to update coin prices: %e", err)
+		return
+	}
+	volumeFetched, err := p.loadVolume(job, season)
+	if err != nil {
 		p.logger.Errorf("failed to load volume: %e", err)
-	} else {
-		p.logger.Infof("volume fetch completed successfully (from %d to %d)", lastVolumeFetch, models.GetDate(job.JobDate))
-		p.isVolumeFetched = true
+		return
 	}
+	p.priceSwaps(season)
+	p.isVolumeFetched = volumeFetched
 
 	p.wg.Add(1)
 	workChan := make(chan models.CoinDBModel)
 	// worker channel for lp calculation (key is vault id and value is vault addresses)
 	positionWorkerChan := make(chan models.VaultAddress)
-	go p.taskProvider(job, workChan, positionWorkerChan)
+	go p.taskProvider(job, season, workChan, positionWorkerChan)
 
 	// We have 2 type of concurrent workers, one for updating balance and one for updating position
 	for i := 0; i < 2; i++ {
 		p.wg.Add(1)
 		idx := i
-		go p.activePositionWorker(idx, positionWorkerChan, *job)
+		go p.activePositionWorker(idx, positionWorkerChan, *job, season)
 	}
 	for i := 0; i < int(p.cfg.Worker.Concurrency); i++ {
 		p.wg.Add(1)
 		idx := i
-		go p.taskWorker(idx, workChan, *job)
+		go p.taskWorker(idx, workChan, *job, season)
+	}
+
+}
+
+// loadVolume fetches the swaps of every volume source from its own checkpoint up to the job date.
+// A failing source is recorded on its checkpoint and retried on the next job without blocking the others,
+// it returns true only when all sources are up to date.
+func (p *PointWorker) loadVolume(job *models.Job, season config.AirdropSeason) (bool, error) {
+	//default value for lastVolumeFetch is first of June 2025
+	lastVolumeFetch := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	lastVolumeJob, err := p.storage.GetLastVolumeFetch()
+	if err == nil {
+		lastVolumeFetch = time.Unix(models.GetDate(lastVolumeJob.JobDate), 0)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to get last volume fetch: %w", err)
+	}
+	hasSwaps, err := p.storage.HasSwaps()
+	if err != nil {
+		return false, fmt.Errorf("failed to check recorded swaps: %w", err)
+	}
+	if !hasSwaps {
+		// first job with the swap ledger, keep the volume vaults accumulated before it and fetch from where the
+		// previous job stopped, or backfill the whole season when there is nothing to keep
+		seeded, err := p.storage.SeedLegacySwaps(season)
+		if err != nil {
+			return false, err
+		}
+		p.logger.Infof("seeded the swap ledger with the volume of %d vaults", seeded)
+		if seeded == 0 && !season.Start.IsZero() {
+			lastVolumeFetch = season.Start
+		}
+	}
+
+	to := models.GetDate(job.JobDate)
+	allFetched := true
+	for _, source := range p.volumeResolver.Sources() {
+		trackerName := source.Name()
+		checkpoint, err := p.storage.GetVolumeCheckpoint(trackerName, source.Affiliate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			checkpoint = &models.VolumeCheckpoint{
+				Tracker:     trackerName,
+				Affiliate:   source.Affiliate,
+				LastFetched: lastVolumeFetch,
+			}
+		} else if err != nil {
+			return false, err
+		}
+		checkpoint.LastAttempt = time.Now()
+		if paginated, ok := source.Tracker.(volume.IPaginatedTracker); ok {
+			err = p.loadPaginatedVolume(paginated, checkpoint, to)
+		} else {
+			err = p.loadSourceVolume(source, checkpoint, to)
+		}
+		if err != nil {
+			p.logger.Errorf("failed to fetch volume from %s for %s: %v", trackerName, source.Affiliate, err)
+			allFetched = false
+			checkpoint.LastError = redactError(err)
+			if len(checkpoint.LastError) > 1024 {
+				checkpoint.LastError = checkpoint.LastError[:1024]
+			}
+		} else {
+			checkpoint.LastError = ""
+		}
+		if err := p.storage.SaveVolumeCheckpoint(checkpoint); err != nil {
+			return false, err
+		}
+	}
+	return allFetched, nil
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s"']+`)
+
+// redactError returns the message of a volume source error without the path and query of the urls it holds,
+// trackers pass api keys in the query string
+func redactError(err error) string {
+	return urlPattern.ReplaceAllStringFunc(err.Error(), func(raw string) string {
+		u, parseErr := url.Parse(raw)
+		if parseErr != nil {
+			return "[url]"
+		}
+		return u.Scheme + "://" + u.Host
+	})
+}
+
+// priceSwaps sets the volume of the season swaps which had no price when they were fetched, with the price recorded
+// at the time of the swap. Swaps still without a price are tried again by the next job.
+func (p *PointWorker) priceSwaps(season config.AirdropSeason) {
+	swaps, err := p.storage.GetUnpricedSwaps(season.Start)
+	if err != nil {
+		p.logger.Errorf("failed to get unpriced swaps: %v", err)
+		return
+	}
+	priced := 0
+	for _, swap := range swaps {
+		chain, err := common.ChainFromString(swap.TokenChain)
+		if err != nil {
+			p.logger.Warnf("swap %s has an unknown token chain %s", swap.TxHash, swap.TokenChain)
+			continue
+		}
+		price, decimals, err := p.storage.GetTokenPrice(chain, swap.Token, swap.SwappedAt)
+		if err != nil {
+			p.logger.Warnf("failed to get price of %s on %s for tx %s: %v", swap.Token, chain, swap.TxHash, err)
+			continue
+		}
+		volumeUSD := swap.TokenAmount(decimals) * price
+		if volumeUSD == 0 {
+			continue
+		}
+		if err := p.storage.UpdateSwapVolume(swap.ID, volumeUSD); err != nil {
+			p.logger.Errorf("failed to price swap %s: %v", swap.TxHash, err)
+			continue
+		}
+		priced++
 	}
+	p.logger.Infof("priced %d of %d unpriced swaps", priced, len(swaps))
+}
+
+// loadSourceVolume fetches the swaps of a source from its checkpoint up to the given time in one go
+func (p *PointWorker) loadSourceVolume(source volume.VolumeSource, checkpoint *models.VolumeCheckpoint, to int64) error {
+	from := checkpoint.LastFetched.Unix()
+	// windows may overlap, swaps are de-duplicated by tx hash when stored
+	swaps, err := p.volumeResolver.FetchSwaps(source, from, to)
+	if err != nil {
+		return err
+	}
+	if err := p.storage.SaveSwaps(swaps); err != nil {
+		return err
+	}
+	p.logger.Infof("fetched %d swaps from %s for %s (from %d to %d)", len(swaps), checkpoint.Tracker, checkpoint.Affiliate, from, to)
+	checkpoint.LastFetched = time.Unix(to, 0)
+	return nil
+}
 
+// loadPaginatedVolume fetches the swaps of a paginated source page by page and stores the cursor after
+// every page, an interrupted window is resumed first and then the rest up to the given time is fetched.
+// Swaps still in progress hold the checkpoint back, so they are fetched again once they are done.
+func (p *PointWorker) loadPaginatedVolume(tracker volume.IPaginatedTracker, checkpoint *models.VolumeCheckpoint, to int64) error {
+	from := checkpoint.LastFetched.Unix()
+	windowTo := to
+	if checkpoint.Cursor != "" && checkpoint.CursorTo > 0 {
+		windowTo = checkpoint.CursorTo
+		p.logger.Infof("resume %s volume for %s from cursor %s", checkpoint.Tracker, checkpoint.Affiliate, checkpoint.Cursor)
+	}
+	cursor := checkpoint.Cursor
+	var pendingSince int64
+	for {
+		page, err := p.volumeResolver.FetchSwapsPage(tracker, checkpoint.Affiliate, from, windowTo, cursor)
+		if err != nil {
+			return err
+		}
+		if err := p.storage.SaveSwaps(page.Swaps); err != nil {
+			return err
+		}
+		if page.PendingSince > 0 && (pendingSince == 0 || page.PendingSince < pendingSince) {
+			pendingSince = page.PendingSince
+		}
+		cursor = page.NextCursor
+		if cursor != "" {
+			checkpoint.Cursor = cursor
+			checkpoint.CursorTo = windowTo
+			if err := p.storage.SaveVolumeCheckpoint(checkpoint); err != nil {
+				return err
+			}
+			continue
+		}
+		// window is complete
+		fetchedTo := windowTo
+		if pendingSince > 0 && pendingSince < fetchedTo {
+			fetchedTo = pendingSince
+		}
+		checkpoint.LastFetched = time.Unix(fetchedTo, 0)
+		checkpoint.Cursor = ""
+		checkpoint.CursorTo = 0
+		if windowTo >= to {
+			p.logger.Infof("fetched %s volume for %s up to %d", checkpoint.Tracker, checkpoint.Affiliate, fetchedTo)
+			return nil
+		}
+		// the resumed window is done, continue with the rest up to the job date
+		from, windowTo = windowTo, to
+	}
 }
 
 func (p *PointWorker) Stop() {
@@ -204,7 +432,7 @@ func (p *PointWorker) Stop() {
 	p.wg.Wait()
 }
 
-func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBModel, positionWorkerChan chan models.VaultAddress) {
+func (p *PointWorker) taskProvider(job *models.Job, season config.AirdropSeason, workChan chan models.CoinDBModel, positionWorkerChan chan models.VaultAddress) {
 	defer p.wg.Done()
 	p.isJobInProgress = true
 	defer func() {
@@ -230,24 +458,9 @@ func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBM
 			break
 		}
 		for i, vault := range vaults {
-			if vault.CurrentSeasonID < p.cfg.GetCurrentSeason().ID {
-				p.logger.Infof("vault %d is not in current season, commiting old season points", vault.ID)
-				if err := p.storage.CommitSeasonPoints(vault, p.cfg.GetCurrentSeason().ID); err != nil {
-					p.logger.Errorf("failed to commit season points for vault %d: %v", vault.ID, err)
-					continue
-				}
-			}
-			// Fetch referral count
-			vaults[i].ReferralCount, err = p.getValidReferralCount(vault.ECDSA, vault.EDDSA)
-			if err != nil {
-				p.logger.Errorf("failed to get referral count for vault %d: %v", vault.ID, err)
-				continue
-			}
-
-			err = p.storage.UpdateReferralCount(&vaults[i])
-			if err != nil {
-				p.logger.Errorf("failed to update referral count for vault %d: %v", vault.ID, err)
-				continue
+			// the referral bot is an optional source of referrals, the referral counts are computed from the stored referrals
+			if p.referralResolver != nil {
+				p.importReferrals(vault)
 			}
 
 			coins, err := p.storage.GetCoins(vault.ID)
@@ -255,7 +468,6 @@ func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBM
 				p.logger.Errorf("failed to get coins for vault: %v", err)
 				continue
 			}
-			var totalVolume float64
 			address := make(map[string]interface{})
 			//generate vault address for all chains
 			for _, chain := range common.GetAllChains() {
@@ -284,18 +496,24 @@ func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBM
 				}
 			}
 
-			// fetch volume for each coin
+			if p.cfg.TokenDiscovery.Enabled {
+				p.discoverTokens(&vaults[i])
+			}
+
+			// link the vault addresses to their swaps and derive the season volume from the swap ledger
+			addresses := make([]string, 0, len(coins))
 			for _, coin := range coins {
 				if _, ok := address[coin.Address]; ok {
 					continue // skip if address already exists
 				}
-				coinVolume := p.volumeResolver.GetVolume(coin.Address)
-				if coinVolume > 0 {
-					totalVolume += coinVolume
-				}
+				addresses = append(addresses, coin.Address)
 				address[coin.Address] = nil
 			}
-			err = p.storage.UpdateVolume(vault.ID, totalVolume)
+			if err := p.storage.AssignSwapsToVault(vault.ID, addresses); err != nil {
+				p.logger.Errorf("failed to assign swaps for vault %d: %v", vault.ID, err)
+				continue
+			}
+			err = p.storage.UpdateVolume(vault.ID, season.Start)
 			if err != nil {
 				p.logger.Errorf("failed to update volume for vault %d: %v", vault.ID, err)
 				continue
@@ -347,17 +565,44 @@ func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBM
 		if err := p.storage.UpdateVaultBalance(); err != nil {
 			p.logger.Errorf("failed to update vault balance: %v", err)
 		}
-		if p.cfg.GetCurrentSeason().ID > 0 {
-			p.logger.Infof("update vaults total point based on new formula for season %d", p.cfg.GetCurrentSeason().ID)
+		if err := p.storage.UpdateReferralCounts(); err != nil {
+			p.logger.Errorf("failed to update referral counts: %v", err)
+		}
+		if season.ID > 0 {
+			p.logger.Infof("update vaults total point based on new formula for season %d", season.ID)
+			// referral rewards are computed from the job points, before UpdateVaultTotalPoints resets them
+			if rewarded, err := p.storage.ApplyReferralRewards(job, season); err != nil {
+				p.logger.Errorf("failed to apply referral rewards: %v", err)
+			} else if rewarded > 0 {
+				p.logger.Infof("applied referral rewards to %d vaults for job %d", rewarded, job.ID)
+			}
 			if err := p.storage.UpdateVaultTotalPoints(); err != nil {
 				p.logger.Errorf("failed to update vault total points: %v", err)
 			}
-			if err := p.updateVaultsMilestone(); err != nil {
+			if err := p.updateVaultsMilestone(job, season); err != nil {
 				p.logger.Errorf("failed to update vaults milestones: %v", err)
 			}
 		}
+		ranked := true
 		if err := p.storage.UpdateVaultRanks(); err != nil {
 			p.logger.Errorf("failed to update vault ranks: %v", err)
+			ranked = false
+		} else if err := p.storage.WriteLeaderboardSnapshot(); err != nil {
+			p.logger.Errorf("failed to write leaderboard snapshot: %v", err)
+		}
+		if err := p.storage.UpdateVaultSwapRanks(); err != nil {
+			p.logger.Errorf("failed to update vault swap ranks: %v", err)
+			ranked = false
+		}
+		if ranked {
+			if err := p.storage.WriteVaultRankHistory(job, season.ID); err != nil {
+				p.logger.Errorf("failed to write vault rank history: %v", err)
+			}
+		}
+		if awarded, err := p.storage.AwardBadges(season.ID, time.Now()); err != nil {
+			p.logger.Errorf("failed to award badges: %v", err)
+		} else if awarded > 0 {
+			p.logger.Infof("awarded %d badges for job %d", awarded, job.ID)
 		}
 	}
 	if p.isVolumeFetched {
@@ -370,7 +615,46 @@ func (p *PointWorker) taskProvider(job *models.Job, workChan chan models.CoinDBM
 		}
 	}
 }
-func (p *PointWorker) updateVaultsMilestone() error {
+
+// discoverTokens adds the tokens the vault holds on the discovery supported chains but never added as coins.
+// Addresses are discovered at most once per cache period, failing chains are retried on the next job.
+func (p *PointWorker) discoverTokens(vault *models.Vault) {
+	discovered := make([]models.CoinBase, 0)
+	cacheKeys := make([]string, 0)
+	for _, chain := range p.tokenDiscovery.Chains() {
+		addr, err := vault.GetAddress(chain)
+		if err != nil {
+			p.logger.Errorf("failed to get address for vault %d on chain %s: %v", vault.ID, chain, err)
+			continue
+		}
+		cacheKey := fmt.Sprintf("%s_%s", chain, addr)
+		if _, found := p.discoveredAddresses.Get(cacheKey); found {
+			continue
+		}
+		time.Sleep(time.Duration(p.cfg.TokenDiscovery.RequestDelayMs) * time.Millisecond)
+		coins, err := p.tokenDiscovery.DiscoverAddress(chain, addr)
+		if err != nil {
+			p.logger.Errorf("failed to discover tokens for vault %d on chain %s: %v", vault.ID, chain, err)
+			continue
+		}
+		discovered = append(discovered, coins...)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	ids, err := p.storage.AddDiscoveredCoins(vault, discovered)
+	if err != nil {
+		// coins which failed to be added are skipped until the address is discovered again
+		p.logger.Errorf("failed to add discovered coins for vault %d: %v", vault.ID, err)
+	}
+	for _, cacheKey := range cacheKeys {
+		p.discoveredAddresses.Set(cacheKey, true, cache.DefaultExpiration)
+	}
+	if len(ids) > 0 {
+		p.logger.Infof("added %d discovered coins to vault %d", len(ids), vault.ID)
+	}
+}
+
+// updateVaultsMilestone unlocks the season milestones the vaults reached, in order, adding their prizes to the vault points
+func (p *PointWorker) updateVaultsMilestone(job *models.Job, season config.AirdropSeason) error {
 	startId := uint(0)
 	for {
 		vaults, err := p.storage.GetVaultsWithPage(startId, 1000)
@@ -382,24 +666,30 @@ func (p *PointWorker) updateVaultsMilestone() error {
 			break
 		}
 		for _, vault := range vaults {
-			for i := 0; i < len(p.cfg.GetCurrentSeason().Milestones); i++ {
-				// if total points is greater than or equal to milestone minimum
-				if vault.TotalPoints >= float64(p.cfg.GetCurrentSeason().Milestones[i].Minimum) {
-					// if this milestone is locked
-					if vault.NextMilestoneID <= i {
-						// unlock milestone: update vault total points and next milestone id
-						p.storage.UpdateVaultMilestone(vault.ID, i+1, float64(p.cfg.GetCurrentSeason().Milestones[i].Prize))
-					}
+			startId = vault.ID
+			// milestones the vault already unlocked are skipped
+			for i := vault.NextMilestoneID; i < len(season.Milestones); i++ {
+				milestone := season.Milestones[i]
+				if vault.TotalPoints < float64(milestone.Minimum) {
+					break
+				}
+				unlocked, err := p.storage.UnlockVaultMilestone(vault.ID, season.ID, job, i, int64(milestone.Prize))
+				if err != nil {
+					p.logger.Errorf("failed to unlock milestone: %v", err)
+					break
+				}
+				if !unlocked {
+					// the vault moved on since it was read, the next job retries
+					break
 				}
 			}
-			startId = vault.ID
 		}
 	}
 	p.logger.Info("all vaults processed for milestones")
 	return nil
 }
 
-func (p *PointWorker) activePositionWorker(idx int, workerChan <-chan models.VaultAddress, job models.Job) {
+func (p *PointWorker) activePositionWorker(idx int, workerChan <-chan models.VaultAddress, job models.Job, season config.AirdropSeason) {
 	p.logger.Infof("active position worker %d started", idx)
 	defer p.wg.Done()
 	for {
@@ -414,15 +704,19 @@ func (p *PointWorker) activePositionWorker(idx int, workerChan <-chan models.Vau
 			if err := p.updatePosition(v, job.Multiplier); err != nil {
 				p.logger.Errorf("failed to update position: %v", err)
 			}
-			if err := p.updateNFTBalance(v, job.Multiplier); err != nil {
+			if err := p.updateNFTBalance(v, job.Multiplier, season); err != nil {
 				p.logger.Errorf("failed to update nft balance: %v", err)
 			}
 		}
 	}
 }
-func (p *PointWorker) taskWorker(idx int, workerChan <-chan models.CoinDBModel, job models.Job) {
+func (p *PointWorker) taskWorker(idx int, workerChan <-chan models.CoinDBModel, job models.Job, season config.AirdropSeason) {
 	p.logger.Infof("worker %d started", idx)
 	defer p.wg.Done()
+	valuations := make([]models.CoinValuation, 0, valuationBatchSize)
+	defer func() {
+		p.saveCoinValuations(valuations)
+	}()
 	for {
 		select {
 		case <-p.stopChan:
@@ -432,13 +726,28 @@ func (p *PointWorker) taskWorker(idx int, workerChan <-chan models.CoinDBModel,
 			if !more {
 				return
 			}
-			if err := p.updateBalance(t, job.Multiplier); err != nil {
+			valuation, err := p.updateBalance(t, job.Multiplier, season)
+			if err != nil {
 				p.logger.Errorf("failed to update balance: %v", err)
+				continue
+			}
+			valuations = append(valuations, valuation)
+			if len(valuations) >= valuationBatchSize {
+				p.saveCoinValuations(valuations)
+				valuations = valuations[:0]
 			}
 		}
 	}
 }
 
+// saveCoinValuations records the outcome of the liquidity guard for a batch of coins
+func (p *PointWorker) saveCoinValuations(valuations []models.CoinValuation) {
+	if err := p.storage.UpdateCoinValuations(valuations); err != nil {
+		// log the error and move on, the guarded values are still used for points
+		p.logger.Errorf("failed to update %d coin valuations: %v", len(valuations), err)
+	}
+}
+
 func (p *PointWorker) updatePosition(vaultAddress models.VaultAddress, multiplier int64) error {
 	newlp, err := p.fetchPosition(vaultAddress)
 	if err != nil {
@@ -464,9 +773,9 @@ func (p *PointWorker) updatePosition(vaultAddress models.VaultAddress, multiplie
 	return nil
 }
 
-func (p *PointWorker) updateNFTBalance(vaultAddress models.VaultAddress, multiplier int64) error {
+func (p *PointWorker) updateNFTBalance(vaultAddress models.VaultAddress, multiplier int64, season config.AirdropSeason) error {
 	var nftValue int64
-	nftValue, err := p.fetchNFTValue(vaultAddress)
+	nftValue, held, err := p.fetchNFTValue(vaultAddress, season)
 	if err != nil {
 		p.logger.Errorf("failed to fetch nft value for vault id %d , using old nft value: %v", vaultAddress.GetVaultID(), err)
 		nftValue, err = p.storage.GetNFTValue(vaultAddress.GetVaultID())
@@ -478,6 +787,11 @@ func (p *PointWorker) updateNFTBalance(vaultAddress models.VaultAddress, multipl
 		if err := p.storage.UpdateNFTValue(vaultAddress.GetVaultID(), nftValue); err != nil {
 			p.logger.Errorf("failed to update nft value: %v", err)
 		}
+		if slices.Contains(held, thorGuardCollectionSlug) {
+			if err := p.storage.AwardVaultBadge(vaultAddress.GetVaultID(), models.BadgeThorGuardHolder, season.ID, time.Now()); err != nil {
+				p.logger.Errorf("failed to award thorguard badge: %v", err)
+			}
+		}
 	}
 	newPoints := float64(nftValue * multiplier)
 	if newPoints == 0 {
@@ -541,46 +855,54 @@ func (p *PointWorker) fetchPosition(vaultAddress models.VaultAddress) (int64, er
 	newLP := tcmayalp + saver + tcyStake + rujiraStake
 	return int64(newLP), nil
 }
-func (p *PointWorker) fetchNFTValue(vault models.VaultAddress) (int64, error) {
+
+// fetchNFTValue returns the value of the nfts the vault holds and the slugs of the collections it holds
+func (p *PointWorker) fetchNFTValue(vault models.VaultAddress, season config.AirdropSeason) (int64, []string, error) {
 	sum := float64(0)
-	for _, nft := range p.whitelistNFTCollection {
+	held := make([]string, 0)
+	for _, nft := range p.tokenRegistry.NFTCollections() {
 		address := vault.GetAddress(nft.Chain)
 		if address != "" {
 			token := models.CoinDBModel{CoinBase: models.CoinBase{
 				Chain:           nft.Chain,
 				Address:         address,
-				ContractAddress: nft.CollectionAddress,
+				ContractAddress: nft.ContractAddress,
 				Decimals:        0,
 				IsNative:        false,
 			}}
 			balance, err := p.balanceResolver.GetBalanceWithRetry(token)
 			if err != nil {
-				return 0, fmt.Errorf("failed to get balance for address:%s : %v", address, err)
+				return 0, nil, fmt.Errorf("failed to get balance for address:%s : %v", address, err)
 			}
 			price, err := p.priceResolver.GetOpenSeaCollectionMinPrice(nft.CollectionSlug)
 			if err != nil {
-				return 0, fmt.Errorf("failed to get price for collection:%s : %v", nft.CollectionSlug, err)
+				return 0, nil, fmt.Errorf("failed to get price for collection:%s : %v", nft.CollectionSlug, err)
+			}
+			if balance > 0 {
+				held = append(held, nft.CollectionSlug)
 			}
-			seasonMultiplier := p.getSeasonMultiplierForNFT(token)
+			seasonMultiplier := p.getSeasonMultiplierForNFT(token, season)
 			sum += balance * float64(seasonMultiplier) * price
 		}
 	}
-	return int64(sum), nil
+	return int64(sum), held, nil
 }
-func (p *PointWorker) updateBalance(coin models.CoinDBModel, multiplier int64) error {
+
+// updateBalance adds the points of a coin holding to its vault and returns the valuation of the holding
+func (p *PointWorker) updateBalance(coin models.CoinDBModel, multiplier int64, season config.AirdropSeason) (models.CoinValuation, error) {
 	p.logger.Infof("start to update balance for chain: %s, ticker: %s, address: %s ", coin.Chain, coin.Ticker, coin.Address)
 	coinBalance, err := p.balanceResolver.GetBalanceWithRetry(coin)
 	if err != nil {
 		p.logger.Errorf("failed to get balance for address:%s : %v", coin.Address, err)
 		prevBalance, errP := strconv.ParseFloat(coin.Balance, 64)
 		if errP != nil {
-			return fmt.Errorf("failed to parse previous balance: %w", errP)
+			return models.CoinValuation{}, fmt.Errorf("failed to parse previous balance: %w", errP)
 		}
 		// server failed to get the latest balance , assume his previous balance is correct and use it to accumulate points
 		coinBalance = prevBalance
 	} else {
 		if err := p.storage.UpdateCoinBalance(uint64(coin.ID), coinBalance); err != nil {
-			return fmt.Errorf("failed to update coin balance: %w", err)
+			return models.CoinValuation{}, fmt.Errorf("failed to update coin balance: %w", err)
 		}
 	}
 	if coin.PriceUSD == "" {
@@ -589,20 +911,25 @@ func (p *PointWorker) updateBalance(coin models.CoinDBModel, multiplier int64) e
 	// increase vault's point
 	price, err := strconv.ParseFloat(coin.PriceUSD, 64)
 	if err != nil {
-		return fmt.Errorf("failed to parse coin price: %w", err)
+		return models.CoinValuation{}, fmt.Errorf("failed to parse coin price: %w", err)
+	}
+	value, status := guardCoinValue(coin, coinBalance*price, p.tokenQuotes, p.cfg.Valuation)
+	if status != models.ValuationStatusOK {
+		p.logger.Infof("coin %d (%s %s) valuation %s: %f -> %f", coin.ID, coin.Chain, coin.Ticker, status, coinBalance*price, value)
 	}
-	seasonMultiplier := p.getSeasonMultiplierForCoin(coin)
-	newPoints := float64(coinBalance * price * float64(multiplier) * float64(seasonMultiplier))
+	valuation := models.CoinValuation{CoinID: coin.ID, Status: status, CreditedUSDValue: value}
+	seasonMultiplier := p.getSeasonMultiplierForCoin(coin, season)
+	newPoints := float64(value * float64(multiplier) * float64(seasonMultiplier))
 	if newPoints == 0 {
-		return nil
+		return valuation, nil
 	}
 	if err := p.storage.IncreaseVaultTotalValue(coin.VaultID, newPoints); err != nil {
-		return fmt.Errorf("failed to increase vault total points: %w", err)
+		return models.CoinValuation{}, fmt.Errorf("failed to increase vault total points: %w", err)
 	}
-	return nil
+	return valuation, nil
 }
 
-func (p *PointWorker) updateCoinPrice() error {
+func (p *PointWorker) updateCoinPrice(job *models.Job) error {
 	p.logger.Info("start to update coin prices")
 	coinIdentities, err := p.storage.GetUniqueCoins()
 	if err != nil {
@@ -612,111 +939,221 @@ func (p *PointWorker) updateCoinPrice() error {
 	if len(coinIdentities) == 0 {
 		return nil
 	}
-	coinPrices, err := p.priceResolver.GetAllTokenPrices(coinIdentities)
+	tokenQuotes, err := p.priceResolver.GetAllTokenQuotes(coinIdentities)
 	if err != nil {
 		return fmt.Errorf("failed to get all token prices: %w", err)
 	}
+	p.tokenQuotes = tokenQuotes
+	coinPrices := make(map[int]float64, len(tokenQuotes))
+	for id, quote := range tokenQuotes {
+		coinPrices[id] = quote.PriceUSD
+	}
 	p.logger.Infof("%+v", coinPrices)
-	for id, coinIden := range coinPrices {
-		if err := p.storage.UpdateCoinPriceByCMCID(id, coinIden); err != nil {
-			p.logger.Errorf("failed to update coin price: %d, err: %v", id, err)
+	fetchedAt := time.Now()
+	history := make([]models.PriceHistory, 0, len(coinPrices))
+	for id, price := range coinPrices {
+		history = append(history, models.PriceHistory{
+			JobID:     job.ID,
+			Source:    config.PriceSourceCMC,
+			CMCId:     id,
+			PriceUSD:  price,
+			FetchedAt: fetchedAt,
+		})
+	}
+	rulePrices := make([]rulePrice, 0)
+	for _, rule := range p.cfg.GetActivePricingRules(fetchedAt) {
+		rp, err := p.resolvePricingRule(rule)
+		if err != nil {
 			// log the error and move on
+			p.logger.Errorf("failed to resolve pricing rule for %s %s%s: %v", rule.Chain, rule.Ticker, rule.ContractAddress, err)
 			continue
 		}
+		rulePrices = append(rulePrices, rp)
+		history = append(history, models.PriceHistory{
+			JobID:           job.ID,
+			Source:          rule.Source,
+			CMCId:           findCMCID(coinIdentities, rp.chain, rule),
+			Chain:           rp.chain,
+			Ticker:          rule.Ticker,
+			ContractAddress: rule.ContractAddress,
+			PriceUSD:        rp.price,
+			FetchedAt:       fetchedAt,
+		})
+	}
+	// prices have to be recorded before they are used to credit points
+	if err := p.storage.SavePriceHistory(history); err != nil {
+		return fmt.Errorf("failed to save price history: %w", err)
 	}
-	cacaoPrice, err := p.priceResolver.GetMidgardCacaoPrices()
+	// a resumed job keeps the prices it recorded before the restart
+	recorded, err := p.storage.GetJobPriceHistory(job.ID)
 	if err != nil {
-		p.logger.Errorf("failed to get CACAO price: %v", err)
-	} else {
-		if err := p.storage.UpdateCoinPrice(common.MayaChain, "CACAO", cacaoPrice); err != nil {
-			p.logger.Errorf("failed to update CACAO price: %v", err)
+		return fmt.Errorf("failed to get job price history: %w", err)
+	}
+	rulePrices = useRecordedPrices(recorded, coinPrices, p.tokenQuotes)
+	for id, price := range coinPrices {
+		if err := p.storage.UpdateCoinPriceByCMCID(id, price); err != nil {
+			p.logger.Errorf("failed to update coin price: %d, err: %v", id, err)
+			// log the error and move on
+			continue
 		}
 	}
-	kweenPrice, err := p.priceResolver.GetCoinGeckoPrice("kween", "usd")
-	if err != nil {
-		p.logger.Errorf("failed to get KWEEN price: %v", err)
-	} else {
-		if err := p.storage.UpdateCoinPrice(common.Solana, "KWEEN", kweenPrice); err != nil {
-			p.logger.Errorf("failed to update KWEEN price: %v", err)
+	for _, rp := range rulePrices {
+		if err := p.applyRulePrice(rp); err != nil {
+			p.logger.Errorf("failed to update price for %s %s%s: %v", rp.rule.Chain, rp.rule.Ticker, rp.rule.ContractAddress, err)
 		}
 	}
 
-	vthorPrice, err := p.priceResolver.GetLiFiPrice("eth", "0x815C23eCA83261b6Ec689b60Cc4a58b54BC24D8D")
+	defer p.logger.Info("finish updating coin prices")
+	return nil
+}
+
+type rulePrice struct {
+	rule  config.PricingRule
+	chain common.Chain
+	price float64
+}
+
+// resolvePricingRule fetches the price from the rule's source
+func (p *PointWorker) resolvePricingRule(rule config.PricingRule) (rulePrice, error) {
+	chain, err := common.ChainFromString(rule.Chain)
 	if err != nil {
-		p.logger.Errorf("failed to get VTHOR price: %v", err)
-	} else {
-		if err := p.storage.UpdateCoinPrice(common.Ethereum, "vTHOR", vthorPrice); err != nil {
-			p.logger.Errorf("failed to update VTHOR price: %v", err)
-		}
-	}
-	mayaPrice := float64(40)
-	if err := p.storage.UpdateCoinPrice(common.MayaChain, "MAYA", mayaPrice); err != nil {
-		p.logger.Errorf("failed to update VTHOR price: %v", err)
+		return rulePrice{}, err
 	}
-	tcyPrice, err := p.priceResolver.GetMidgardPrices("THOR.TCY")
+	price, err := p.priceResolver.GetPriceByRule(rule)
 	if err != nil {
-		p.logger.Errorf("failed to get TCY price: %v", err)
-	} else {
-		if err := p.storage.UpdateCoinPrice(common.THORChain, "THOR.TCY", tcyPrice); err != nil {
-			p.logger.Errorf("failed to update TCY price: %v", err)
-		}
+		return rulePrice{}, fmt.Errorf("failed to get price from %s: %w", rule.Source, err)
 	}
-	rujiraPrice, err := p.priceResolver.GetCoinGeckoPrice("rujira", "usd")
-	if err != nil {
-		p.logger.Errorf("failed to get Rujira price: %v", err)
-	} else {
-		if err := p.storage.UpdateCoinPrice(common.THORChain, "RUJIRA", rujiraPrice); err != nil {
-			p.logger.Errorf("failed to update Rujira price: %v", err)
+	return rulePrice{rule: rule, chain: chain, price: price}, nil
+}
+
+// applyRulePrice sets the resolved price on all coins matching the rule
+func (p *PointWorker) applyRulePrice(rp rulePrice) error {
+	if rp.rule.ContractAddress != "" {
+		return p.storage.UpdateCoinPriceByContract(rp.chain, rp.rule.ContractAddress, rp.price)
+	}
+	return p.storage.UpdateCoinPrice(rp.chain, rp.rule.Ticker, rp.price)
+}
+
+// useRecordedPrices replaces the fetched prices with the prices recorded by the job, which are the prices of its
+// first run for the assets that run priced. It returns the rule prices to apply.
+func useRecordedPrices(recorded []models.PriceHistory, coinPrices map[int]float64, quotes map[int]models.TokenQuote) []rulePrice {
+	rules := make([]rulePrice, 0)
+	for _, price := range recorded {
+		if price.Source == config.PriceSourceCMC && price.Chain == common.Undefined {
+			coinPrices[price.CMCId] = price.PriceUSD
+			if quote, ok := quotes[price.CMCId]; ok {
+				quote.PriceUSD = price.PriceUSD
+				quotes[price.CMCId] = quote
+			}
+			continue
 		}
+		rules = append(rules, rulePrice{
+			rule: config.PricingRule{
+				Chain:           price.Chain.String(),
+				Ticker:          price.Ticker,
+				ContractAddress: price.ContractAddress,
+				Source:          price.Source,
+			},
+			chain: price.Chain,
+			price: price.PriceUSD,
+		})
 	}
+	return rules
+}
 
-	defer p.logger.Info("finish updating coin prices")
-	return nil
+// findCMCID returns the CMC id of the coins matched by a pricing rule, or 0 if they have none
+func findCMCID(coinIdentities []models.CoinIdentity, chain common.Chain, rule config.PricingRule) int {
+	for _, coin := range coinIdentities {
+		if coin.Chain != chain || coin.CMCId == 0 {
+			continue
+		}
+		if rule.ContractAddress != "" && strings.EqualFold(coin.ContractAddress, rule.ContractAddress) {
+			return coin.CMCId
+		}
+		if rule.ContractAddress == "" && coin.Ticker == rule.Ticker {
+			return coin.CMCId
+		}
+	}
+	return 0
 }
 
-func (p *PointWorker) getValidReferralCount(ecdsaKey string, eddsaKey string) (int64, error) {
-	referrals, err := p.referralResolver.GetReferrals(ecdsaKey, eddsaKey)
+// syncBadges stores the badge definitions, the achievements feed of the referral bot overrides the built-in ones.
+// Failures are logged, badges are then awarded with the definitions already stored.
+func (p *PointWorker) syncBadges() {
+	if err := p.storage.SeedBadges(); err != nil {
+		p.logger.Errorf("failed to seed badges: %v", err)
+		return
+	}
+	if p.referralResolver == nil {
+		return
+	}
+	achievements, err := p.referralResolver.GetAllAchievements(models.AchievementsRequest{})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get referrals: %w", err)
+		p.logger.Errorf("failed to get achievements: %v", err)
+		return
 	}
-
-	var cnt int64
-	for _, r := range referrals {
-		// User can not refer himself
-		if r.WalletPublicKeyEcdsa == ecdsaKey && r.WalletPublicKeyEddsa == eddsaKey {
+	badges := make([]models.Badge, 0, len(achievements))
+	for _, achievement := range achievements {
+		if achievement.Code == "" {
 			continue
 		}
+		badges = append(badges, models.NewBadgeFromAchievement(achievement))
+	}
+	if err := p.storage.SyncBadges(badges); err != nil {
+		p.logger.Errorf("failed to sync badges: %v", err)
+	}
+}
+
+// importReferrals records the referrals the referral bot knows for the vault, referees already referred are skipped
+func (p *PointWorker) importReferrals(vault models.Vault) {
+	referrals, err := p.referralResolver.GetReferrals(vault.ECDSA, vault.EDDSA)
+	if err != nil {
+		p.logger.Errorf("failed to get referrals of vault %d: %v", vault.ID, err)
+		return
+	}
+	for _, r := range referrals {
 		if r.WalletPublicKeyEcdsa == "" || r.WalletPublicKeyEddsa == "" {
 			continue
 		}
-		v, err := p.storage.GetVault(r.WalletPublicKeyEcdsa, r.WalletPublicKeyEddsa)
-		if err != nil || v == nil {
+		referee, err := p.storage.GetVault(r.WalletPublicKeyEcdsa, r.WalletPublicKeyEddsa)
+		if err != nil {
 			p.logger.Warnf("Referral vault not found for ECDSA: %s, EDDSA: %s",
 				r.WalletPublicKeyEcdsa, r.WalletPublicKeyEddsa)
 			continue
 		}
-		if v.Balance+v.LPValue+v.NFTValue >= MinBalanceForValidReferral {
-			cnt++
+		// User can not refer himself
+		if referee.ID == vault.ID {
+			continue
+		}
+		if _, err := p.storage.CreateVaultReferral(vault.ID, referee.ID, models.ReferralSourceVultiBot); err != nil {
+			p.logger.Errorf("failed to import referral of vault %d: %v", vault.ID, err)
 		}
 	}
-
-	return cnt, nil
 }
 
-func (p *PointWorker) getSeasonMultiplierForCoin(coin models.CoinDBModel) float64 {
-	for _, token := range p.cfg.GetCurrentSeason().Tokens {
+func (p *PointWorker) getSeasonMultiplierForCoin(coin models.CoinDBModel, season config.AirdropSeason) float64 {
+	for _, token := range season.Tokens {
 		if token.Chain == coin.Chain.String() && token.Name == coin.Ticker && coin.ContractAddress == token.ContractAddress {
 			return token.Multiplier
 		}
 	}
-	return 1
+	return p.getRegistryMultiplier(coin)
 }
 
-func (p *PointWorker) getSeasonMultiplierForNFT(coin models.CoinDBModel) float64 {
-	for _, collection := range p.cfg.GetCurrentSeason().NFTs {
+func (p *PointWorker) getSeasonMultiplierForNFT(coin models.CoinDBModel, season config.AirdropSeason) float64 {
+	for _, collection := range season.NFTs {
 		if collection.Chain == coin.Chain.String() && collection.ContractAddress == coin.ContractAddress {
 			return collection.Multiplier
 		}
 	}
+	return p.getRegistryMultiplier(coin)
+}
+
+// getRegistryMultiplier returns the multiplier of the token registry entry of coin, used when the season doesn't configure one
+func (p *PointWorker) getRegistryMultiplier(coin models.CoinDBModel) float64 {
+	token, ok := p.tokenRegistry.Find(coin.Chain, coin.ContractAddress, coin.Ticker)
+	if ok && token.Multiplier > 0 {
+		return token.Multiplier
+	}
 	return 1
 }
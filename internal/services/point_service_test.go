@@ -1,9 +1,12 @@
 package services
 
 import (
+	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/vultisig/airdrop-registry/config"
 	"github.com/vultisig/airdrop-registry/internal/common"
@@ -41,3 +44,14 @@ func TestPointService(t *testing.T) {
 		t.Logf("New LP Value: %v", newLPValue)
 	}
 }
+
+func TestRedactError(t *testing.T) {
+	urlErr := &url.Error{
+		Op:  "Get",
+		URL: "https://api.etherscan.io/v2/api?chainid=1&module=account&apikey=SECRET",
+		Err: fmt.Errorf("dial tcp: i/o timeout"),
+	}
+	err := fmt.Errorf("failed to fetch 1inch volume: %w", urlErr)
+	assert.Equal(t, `failed to fetch 1inch volume: Get "https://api.etherscan.io": dial tcp: i/o timeout`, redactError(err))
+	assert.Equal(t, "unexpected status code: 500", redactError(fmt.Errorf("unexpected status code: 500")))
+}
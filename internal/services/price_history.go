@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// SavePriceHistory records the prices used by a job, the prices already recorded for the job are kept so a resumed
+// job credits the remaining vaults with the prices it credited the others with
+func (s *Storage) SavePriceHistory(prices []models.PriceHistory) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(prices, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to save price history: %w", err)
+	}
+	return nil
+}
+
+// GetJobPriceHistory returns the prices recorded by the given job
+func (s *Storage) GetJobPriceHistory(jobID uint) ([]models.PriceHistory, error) {
+	var prices []models.PriceHistory
+	if err := s.db.Where("job_id = ?", jobID).Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price history of job %d: %w", jobID, err)
+	}
+	return prices, nil
+}
+
+// GetPriceHistory returns the recorded prices of the given CMC id between from and to
+func (s *Storage) GetPriceHistory(cmcID int, from, to time.Time, limit int) ([]models.PriceHistory, error) {
+	var prices []models.PriceHistory
+	if err := s.db.Where("cmc_id = ? AND fetched_at >= ? AND fetched_at <= ?", cmcID, from, to).
+		Order("fetched_at asc").
+		Limit(limit).
+		Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price history for cmc id %d: %w", cmcID, err)
+	}
+	return prices, nil
+}
+
+// GetAssetPriceHistory returns the prices recorded by pricing rules for an asset between from and to, it covers the
+// assets priced without a CMC id. The asset is matched on its contract address, or on its ticker if it has none.
+func (s *Storage) GetAssetPriceHistory(chain common.Chain, ticker, contractAddress string, from, to time.Time, limit int) ([]models.PriceHistory, error) {
+	var prices []models.PriceHistory
+	qry := s.db.Where("chain = ? AND fetched_at >= ? AND fetched_at <= ?", chain, from, to)
+	if contractAddress != "" {
+		qry = qry.Where("LOWER(contract_address) = ?", strings.ToLower(contractAddress))
+	} else {
+		qry = qry.Where("ticker = ? AND contract_address = ''", ticker)
+	}
+	if err := qry.Order("fetched_at asc").Limit(limit).Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price history for %s %s%s: %w", chain, ticker, contractAddress, err)
+	}
+	return prices, nil
+}
+
+// GetTokenPrice returns the USD price of a token at the given time and its decimals. The token has to be in the token
+// registry, which provides the decimals and the CMC id. The price is the latest price recorded for the CMC id before
+// that time, with a fallback to the current price of the coin. An empty contract address means the native token.
+func (s *Storage) GetTokenPrice(chain common.Chain, contractAddress string, at time.Time) (float64, int, error) {
+	var token models.Token
+	qry := s.db.Where("chain = ? AND type = ?", chain, models.TokenTypeFungible)
+	if contractAddress == "" {
+		qry = qry.Where("contract_address = ''")
+	} else {
+		qry = qry.Where("LOWER(contract_address) = ?", strings.ToLower(contractAddress))
+	}
+	if err := qry.Order("id asc").First(&token).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get token %s on %s from the token registry: %w", contractAddress, chain, err)
+	}
+	if token.CMCId > 0 {
+		var price models.PriceHistory
+		err := s.db.Where("cmc_id = ? AND fetched_at <= ?", token.CMCId, at).Order("fetched_at desc").First(&price).Error
+		if err == nil {
+			return price.PriceUSD, token.Decimals, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, 0, fmt.Errorf("failed to get price history for cmc id %d: %w", token.CMCId, err)
+		}
+	}
+	var coin models.CoinDBModel
+	qry = s.db.Where("chain = ?", chain)
+	if contractAddress == "" {
+		qry = qry.Where("is_native_token = ?", true)
+		if token.Ticker != "" {
+			qry = qry.Where("ticker = ?", token.Ticker)
+		}
+	} else {
+		qry = qry.Where("LOWER(contract_address) = ?", strings.ToLower(contractAddress))
+	}
+	if err := qry.Order("id asc").First(&coin).Error; err != nil {
+		return 0, 0, fmt.Errorf("no price recorded for %s on %s: %w", token.Ticker, chain, err)
+	}
+	price, err := strconv.ParseFloat(coin.PriceUSD, 64)
+	if err != nil || price == 0 {
+		return 0, 0, fmt.Errorf("no price recorded for %s on %s", token.Ticker, chain)
+	}
+	return price, token.Decimals, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestUseRecordedPrices(t *testing.T) {
+	// prices recorded by the first run of the job, the resumed run fetched other prices
+	recorded := []models.PriceHistory{
+		{JobID: 7, Source: config.PriceSourceCMC, CMCId: 1, PriceUSD: 60000},
+		{JobID: 7, Source: config.PriceSourceMayaMidgard, Chain: common.MayaChain, Ticker: "CACAO", PriceUSD: 0.5},
+		{JobID: 7, Source: config.PriceSourceLiFi, CMCId: 3, Chain: common.Ethereum, Ticker: "vTHOR", ContractAddress: "0x815C", PriceUSD: 2},
+	}
+	coinPrices := map[int]float64{1: 65000, 2: 1}
+	quotes := map[int]models.TokenQuote{
+		1: {CMCId: 1, PriceUSD: 65000, Volume24h: 100},
+		2: {CMCId: 2, PriceUSD: 1, Volume24h: 10},
+	}
+	rules := useRecordedPrices(recorded, coinPrices, quotes)
+
+	assert.Equal(t, map[int]float64{1: 60000, 2: 1}, coinPrices)
+	assert.Equal(t, models.TokenQuote{CMCId: 1, PriceUSD: 60000, Volume24h: 100}, quotes[1])
+	assert.Len(t, rules, 2)
+	assert.Equal(t, common.MayaChain, rules[0].chain)
+	assert.Equal(t, "CACAO", rules[0].rule.Ticker)
+	assert.Equal(t, 0.5, rules[0].price)
+	assert.Equal(t, "0x815C", rules[1].rule.ContractAddress)
+	assert.Equal(t, 2.0, rules[1].price)
+}
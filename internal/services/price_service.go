@@ -201,6 +201,19 @@ func (p *PriceResolver) GetMidgardCacaoPrices() (float64, error) {
 	return 0, fmt.Errorf("price not found in response")
 }
 func (p *PriceResolver) GetAllTokenPrices(coinIds []models.CoinIdentity) (map[int]float64, error) {
+	quotes, err := p.GetAllTokenQuotes(coinIds)
+	if err != nil {
+		return nil, err
+	}
+	priceMap := make(map[int]float64)
+	for id, quote := range quotes {
+		priceMap[id] = quote.PriceUSD
+	}
+	return priceMap, nil
+}
+
+// GetAllTokenQuotes returns the CMC quotes (price, 24h volume and market cap) of the given coins
+func (p *PriceResolver) GetAllTokenQuotes(coinIds []models.CoinIdentity) (map[int]models.TokenQuote, error) {
 	strIds := p.resolveIds(coinIds)
 	url := CMC_Base_URL + "/v2/cryptocurrency/quotes/latest?id=" + strIds
 	resp, err := http.Get(url)
@@ -220,7 +233,9 @@ func (p *PriceResolver) GetAllTokenPrices(coinIds []models.CoinIdentity) (map[in
 			Slug   string `json:"slug"`
 			Quote  struct {
 				USD struct {
-					Price float64 `json:"price"`
+					Price     float64 `json:"price"`
+					Volume24h float64 `json:"volume_24h"`
+					MarketCap float64 `json:"market_cap"`
 				} `json:"USD"`
 			} `json:"quote"`
 		} `json:"data"`
@@ -229,11 +244,47 @@ func (p *PriceResolver) GetAllTokenPrices(coinIds []models.CoinIdentity) (map[in
 	if err := json.NewDecoder(resp.Body).Decode(&cmcQuoteResp); err != nil {
 		return nil, fmt.Errorf("error decoding CMC quote response: %w", err)
 	}
-	priceMap := make(map[int]float64)
+	quotes := make(map[int]models.TokenQuote)
 	for _, item := range cmcQuoteResp.Data {
-		priceMap[item.ID] = item.Quote.USD.Price
+		quotes[item.ID] = models.TokenQuote{
+			CMCId:     item.ID,
+			PriceUSD:  item.Quote.USD.Price,
+			Volume24h: item.Quote.USD.Volume24h,
+			MarketCap: item.Quote.USD.MarketCap,
+		}
+	}
+	return quotes, nil
+}
+
+// GetPriceByRule resolves the USD price of a coin using the source defined in the given pricing rule
+func (p *PriceResolver) GetPriceByRule(rule config.PricingRule) (float64, error) {
+	switch rule.Source {
+	case config.PriceSourceFixed:
+		return rule.FixedPrice, nil
+	case config.PriceSourceCoinGecko:
+		return p.GetCoinGeckoPrice(rule.SourceID, "usd")
+	case config.PriceSourceLiFi:
+		return p.GetLiFiPrice(rule.SourceChain, rule.SourceID)
+	case config.PriceSourceMidgard:
+		return p.GetMidgardPrices(rule.SourceID)
+	case config.PriceSourceMayaMidgard:
+		return p.GetMidgardCacaoPrices()
+	case config.PriceSourceCMC:
+		cmcID, err := strconv.Atoi(rule.SourceID)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cmc id %s: %w", rule.SourceID, err)
+		}
+		priceMap, err := p.GetAllTokenPrices([]models.CoinIdentity{{CMCId: cmcID}})
+		if err != nil {
+			return 0, err
+		}
+		if price, ok := priceMap[cmcID]; ok {
+			return price, nil
+		}
+		return 0, fmt.Errorf("price not found for cmc id %d", cmcID)
+	default:
+		return 0, fmt.Errorf("unsupported price source: %s", rule.Source)
 	}
-	return priceMap, nil
 }
 
 type OpenSeaBestCollectionResponse struct {
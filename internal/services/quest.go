@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// GetQuests returns all quests
+func (s *Storage) GetQuests() ([]models.Quest, error) {
+	var quests []models.Quest
+	if err := s.db.Order("id asc").Find(&quests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quests: %w", err)
+	}
+	return quests, nil
+}
+
+// GetQuest returns the quest with the given quest id, gorm.ErrRecordNotFound if none is stored
+func (s *Storage) GetQuest(questID string) (*models.Quest, error) {
+	var quest models.Quest
+	if err := s.db.Where("quest_id = ?", questID).First(&quest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get quest: %w", err)
+	}
+	return &quest, nil
+}
+
+func (s *Storage) CreateQuest(quest *models.Quest) error {
+	if err := s.db.Create(quest).Error; err != nil {
+		return fmt.Errorf("failed to create quest: %w", err)
+	}
+	return nil
+}
+
+// UpdateQuest saves all fields of quest
+func (s *Storage) UpdateQuest(quest *models.Quest) error {
+	if err := s.db.Save(quest).Error; err != nil {
+		return fmt.Errorf("failed to update quest: %w", err)
+	}
+	return nil
+}
+
+// DeleteQuest permanently deletes a quest, its completions are kept
+func (s *Storage) DeleteQuest(questID string) error {
+	if err := s.db.Unscoped().Where("quest_id = ?", questID).Delete(&models.Quest{}).Error; err != nil {
+		return fmt.Errorf("failed to delete quest: %w", err)
+	}
+	return nil
+}
+
+// SeedQuests stores the quests of the config file while the quests table is empty, the quests are validated like
+// the ones created through the admin endpoints
+func (s *Storage) SeedQuests(cfg *config.Config) error {
+	var count int64
+	if err := s.db.Model(&models.Quest{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count quests: %w", err)
+	}
+	if count > 0 || len(cfg.Quests) == 0 {
+		return nil
+	}
+	quests := make([]models.Quest, 0, len(cfg.Quests))
+	for _, seed := range cfg.Quests {
+		quest := models.Quest{
+			QuestID:  seed.ID,
+			Partner:  seed.Partner,
+			Criteria: seed.Criteria,
+			Chain:    seed.Chain,
+			Minimum:  seed.Minimum,
+			Points:   seed.Points,
+			Enabled:  seed.Enabled,
+		}
+		partner, ok := cfg.GetQuestPartner(quest.Partner)
+		if !ok {
+			return fmt.Errorf("quest %s has an unknown partner %s", quest.QuestID, quest.Partner)
+		}
+		if !quest.IsValid(!partner.IsOpen()) {
+			return fmt.Errorf("quest %s is invalid, check its criteria and that quests of an open partner reward no points", quest.QuestID)
+		}
+		quests = append(quests, quest)
+	}
+	if err := s.db.Create(&quests).Error; err != nil {
+		return fmt.Errorf("failed to seed quests: %w", err)
+	}
+	return nil
+}
+
+// VaultHasChainAddress returns true if the vault has a coin on the given chain
+func (s *Storage) VaultHasChainAddress(vaultID uint, chain common.Chain) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.CoinDBModel{}).Where("vault_id = ? AND chain = ?", vaultID, chain).Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to get vault coins: %w", err)
+	}
+	return count > 0, nil
+}
+
+// CompleteQuest records the completion of a quest by a vault and adds the quest points to the vault, in one
+// transaction. It returns false if the vault had already completed the quest.
+func (s *Storage) CompleteQuest(quest *models.Quest, vaultID, seasonID uint, address string) (bool, error) {
+	created := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.QuestCompletion{
+			QuestID:  quest.QuestID,
+			VaultID:  vaultID,
+			SeasonID: seasonID,
+			Address:  address,
+			Points:   quest.Points,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		created = result.RowsAffected > 0
+		if !created || quest.Points <= 0 {
+			return nil
+		}
+		return tx.Model(&models.Vault{}).Where("id = ?", vaultID).
+			UpdateColumn("total_points", gorm.Expr("total_points + ?", quest.Points)).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to complete quest: %w", err)
+	}
+	return created, nil
+}
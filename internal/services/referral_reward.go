@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+const (
+	referralFanoutThreshold     = 20  // referrers with at least this many referrals are checked for a low conversion
+	referralLowConversion       = 0.2 // conversion below which a large fan-out is flagged
+	referralBurstThreshold      = 10  // referrals within an hour from which a referrer is flagged
+	referralBurstWindowSeconds  = int64(time.Hour / time.Second)
+	maxReferralTreeNodes        = 1000
+	referralRewardsBatchSize    = 1000
+	referralRewardsQueryTimeout = 10 * time.Minute
+)
+
+// referralShares is the points the valid referees of a referrer earned in the current job
+type referralShares struct {
+	ReferrerID        uint
+	DirectPoints      float64
+	SecondLevelPoints float64
+}
+
+// ApplyReferralRewards adds the referral rewards of the season scheme to the referrers, from the points their valid
+// referees earn in the job. It must run before the job points are added to the vaults, a job is rewarded once.
+func (s *Storage) ApplyReferralRewards(job *models.Job, season config.AirdropSeason) (int, error) {
+	if !season.Referral.IsEnabled() {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), referralRewardsQueryTimeout)
+	defer cancel()
+	db := s.db.WithContext(ctx)
+
+	var rewarded int64
+	if err := db.Model(&models.ReferralReward{}).Where("job_id = ?", job.ID).Count(&rewarded).Error; err != nil {
+		return 0, fmt.Errorf("failed to count referral rewards: %w", err)
+	}
+	if rewarded > 0 {
+		return 0, nil
+	}
+	// a job adds SQRT(total_vault_value) to the points of a vault, see UpdateVaultTotalPoints
+	qry := `SELECT shares.referrer_id, SUM(shares.direct_points), SUM(shares.second_level_points)
+		FROM (
+			SELECT vault_referrals.referrer_id, SQRT(referees.total_vault_value) AS direct_points, 0 AS second_level_points
+			FROM vault_referrals
+			JOIN vaults AS referees ON referees.id = vault_referrals.referee_id AND referees.deleted_at IS NULL
+			WHERE vault_referrals.deleted_at IS NULL AND referees.total_vault_value > 0
+				AND referees.balance + referees.lp_value + referees.nft_value >= ?
+			UNION ALL
+			SELECT parents.referrer_id, 0, SQRT(referees.total_vault_value)
+			FROM vault_referrals AS parents
+			JOIN vault_referrals AS children ON children.referrer_id = parents.referee_id AND children.deleted_at IS NULL
+			JOIN vaults AS referees ON referees.id = children.referee_id AND referees.deleted_at IS NULL
+			WHERE parents.deleted_at IS NULL AND children.referee_id <> parents.referrer_id AND referees.total_vault_value > 0
+				AND referees.balance + referees.lp_value + referees.nft_value >= ?
+		) AS shares
+		JOIN vaults AS referrers ON referrers.id = shares.referrer_id AND referrers.deleted_at IS NULL AND referrers.join_airdrop = 1
+		GROUP BY shares.referrer_id`
+	rows, err := db.Raw(qry, MinBalanceForValidReferral, MinBalanceForValidReferral).Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get referral shares: %w", err)
+	}
+	defer rows.Close()
+	var shares []referralShares
+	for rows.Next() {
+		var share referralShares
+		if err := rows.Scan(&share.ReferrerID, &share.DirectPoints, &share.SecondLevelPoints); err != nil {
+			return 0, fmt.Errorf("failed to scan referral shares: %w", err)
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to get referral shares: %w", err)
+	}
+	rewards := buildReferralRewards(shares, job.ID, season)
+	if len(rewards) == 0 {
+		return 0, nil
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(rewards, referralRewardsBatchSize).Error; err != nil {
+			return err
+		}
+		qry := `UPDATE vaults JOIN referral_rewards ON referral_rewards.vault_id = vaults.id AND referral_rewards.job_id = ?
+			SET vaults.total_points = vaults.total_points + referral_rewards.points`
+		return tx.Exec(qry, job.ID).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply referral rewards: %w", err)
+	}
+	return len(rewards), nil
+}
+
+// buildReferralRewards applies the referral scheme of the season to the shares, referrers without reward are skipped
+func buildReferralRewards(shares []referralShares, jobID uint, season config.AirdropSeason) []models.ReferralReward {
+	rewards := make([]models.ReferralReward, 0, len(shares))
+	for _, share := range shares {
+		points := season.Referral.Reward(share.DirectPoints, share.SecondLevelPoints)
+		if points <= 0 {
+			continue
+		}
+		rewards = append(rewards, models.ReferralReward{
+			VaultID:           share.ReferrerID,
+			JobID:             jobID,
+			SeasonID:          season.ID,
+			DirectPoints:      share.DirectPoints,
+			SecondLevelPoints: share.SecondLevelPoints,
+			Points:            points,
+		})
+	}
+	return rewards
+}
+
+// GetReferralLinks returns all referrals with the validity of their referee
+func (s *Storage) GetReferralLinks() ([]models.ReferralLink, error) {
+	rows, err := s.db.Table("vault_referrals").
+		Select("vault_referrals.referrer_id, vault_referrals.referee_id, vault_referrals.created_at, "+
+			"vaults.balance + vaults.lp_value + vaults.nft_value >= ?", MinBalanceForValidReferral).
+		Joins("JOIN vaults ON vaults.id = vault_referrals.referee_id AND vaults.deleted_at IS NULL").
+		Where("vault_referrals.deleted_at IS NULL").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral links: %w", err)
+	}
+	defer rows.Close()
+	links := make([]models.ReferralLink, 0)
+	for rows.Next() {
+		var link models.ReferralLink
+		var referredAt time.Time
+		if err := rows.Scan(&link.ReferrerID, &link.RefereeID, &referredAt, &link.Valid); err != nil {
+			return nil, fmt.Errorf("failed to scan referral link: %w", err)
+		}
+		link.ReferredAt = referredAt.Unix()
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetReferralAnalytics returns the referral conversion, the top referrers and the referrers with a suspicious fan-out
+func (s *Storage) GetReferralAnalytics(top int) (*models.ReferralAnalytics, error) {
+	links, err := s.GetReferralLinks()
+	if err != nil {
+		return nil, err
+	}
+	analytics := buildReferralAnalytics(links, top)
+	ids := make([]uint, 0, len(analytics.TopReferrers)+len(analytics.Suspicious))
+	for _, stats := range analytics.TopReferrers {
+		ids = append(ids, stats.VaultID)
+	}
+	for _, stats := range analytics.Suspicious {
+		ids = append(ids, stats.VaultID)
+	}
+	uids, err := s.getVaultUIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range analytics.TopReferrers {
+		analytics.TopReferrers[i].Uid = uids[analytics.TopReferrers[i].VaultID]
+	}
+	for i := range analytics.Suspicious {
+		analytics.Suspicious[i].Uid = uids[analytics.Suspicious[i].VaultID]
+	}
+	return &analytics, nil
+}
+
+func (s *Storage) getVaultUIDs(ids []uint) (map[uint]string, error) {
+	uids := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return uids, nil
+	}
+	var vaults []models.Vault
+	if err := s.db.Select("id", "uid").Where("id IN ?", ids).Find(&vaults).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vault uids: %w", err)
+	}
+	for _, vault := range vaults {
+		uids[vault.ID] = vault.Uid
+	}
+	return uids, nil
+}
+
+// buildReferralAnalytics aggregates the referral links per referrer
+func buildReferralAnalytics(links []models.ReferralLink, top int) models.ReferralAnalytics {
+	statsByReferrer := make(map[uint]*models.ReferrerStats)
+	referralTimes := make(map[uint][]int64)
+	referrerOf := make(map[uint]uint, len(links))
+	analytics := models.ReferralAnalytics{
+		TopReferrers: make([]models.ReferrerStats, 0),
+		Suspicious:   make([]models.ReferrerStats, 0),
+	}
+	for _, link := range links {
+		stats, ok := statsByReferrer[link.ReferrerID]
+		if !ok {
+			stats = &models.ReferrerStats{VaultID: link.ReferrerID}
+			statsByReferrer[link.ReferrerID] = stats
+		}
+		stats.Referrals++
+		analytics.Referrals++
+		if link.Valid {
+			stats.ValidReferrals++
+			analytics.ValidReferrals++
+		}
+		referralTimes[link.ReferrerID] = append(referralTimes[link.ReferrerID], link.ReferredAt)
+		referrerOf[link.RefereeID] = link.ReferrerID
+	}
+	for refereeID, stats := range statsByReferrer {
+		if referrerID, ok := referrerOf[refereeID]; ok {
+			statsByReferrer[referrerID].SecondLevel += stats.Referrals
+		}
+	}
+	all := make([]models.ReferrerStats, 0, len(statsByReferrer))
+	for referrerID, stats := range statsByReferrer {
+		stats.Conversion = float64(stats.ValidReferrals) / float64(stats.Referrals)
+		stats.MaxReferralsHour = maxWithinWindow(referralTimes[referrerID], referralBurstWindowSeconds)
+		if stats.Referrals >= referralFanoutThreshold && stats.Conversion < referralLowConversion {
+			stats.Flags = append(stats.Flags, models.ReferralFlagLowConversion)
+		}
+		if stats.MaxReferralsHour >= referralBurstThreshold {
+			stats.Flags = append(stats.Flags, models.ReferralFlagBurst)
+		}
+		all = append(all, *stats)
+	}
+	analytics.Referrers = int64(len(all))
+	if analytics.Referrals > 0 {
+		analytics.Conversion = float64(analytics.ValidReferrals) / float64(analytics.Referrals)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].ValidReferrals != all[j].ValidReferrals {
+			return all[i].ValidReferrals > all[j].ValidReferrals
+		}
+		if all[i].Referrals != all[j].Referrals {
+			return all[i].Referrals > all[j].Referrals
+		}
+		return all[i].VaultID < all[j].VaultID
+	})
+	for _, stats := range all {
+		if len(stats.Flags) > 0 {
+			analytics.Suspicious = append(analytics.Suspicious, stats)
+		}
+	}
+	if top > len(all) {
+		top = len(all)
+	}
+	analytics.TopReferrers = append(analytics.TopReferrers, all[:top]...)
+	return analytics
+}
+
+// maxWithinWindow returns the largest number of times within a window of the given seconds
+func maxWithinWindow(times []int64, window int64) int64 {
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var best int64
+	start := 0
+	for end := range times {
+		for times[end]-times[start] >= window {
+			start++
+		}
+		if n := int64(end - start + 1); n > best {
+			best = n
+		}
+	}
+	return best
+}
+
+// GetReferralTree returns the vault with the vaults it referred, down to depth levels
+func (s *Storage) GetReferralTree(vault *models.Vault, depth int) (*models.ReferralTreeNode, error) {
+	root := &models.ReferralTreeNode{
+		VaultID:  vault.ID,
+		Uid:      vault.Uid,
+		Valid:    vault.Balance+vault.LPValue+vault.NFTValue >= MinBalanceForValidReferral,
+		Children: make([]models.ReferralTreeNode, 0),
+	}
+	level := []*models.ReferralTreeNode{root}
+	nodes := 1
+	for d := 0; d < depth && len(level) > 0 && nodes < maxReferralTreeNodes; d++ {
+		byID := make(map[uint]*models.ReferralTreeNode, len(level))
+		ids := make([]uint, 0, len(level))
+		for _, node := range level {
+			byID[node.VaultID] = node
+			ids = append(ids, node.VaultID)
+		}
+		rows, err := s.db.Table("vault_referrals").
+			Select("vault_referrals.referrer_id, vaults.id, vaults.uid, vault_referrals.created_at, "+
+				"vaults.balance + vaults.lp_value + vaults.nft_value >= ?", MinBalanceForValidReferral).
+			Joins("JOIN vaults ON vaults.id = vault_referrals.referee_id AND vaults.deleted_at IS NULL").
+			Where("vault_referrals.deleted_at IS NULL AND vault_referrals.referrer_id IN ?", ids).
+			Order("vault_referrals.created_at asc").
+			Limit(maxReferralTreeNodes - nodes).
+			Rows()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get referral tree: %w", err)
+		}
+		var parents []uint
+		var children []models.ReferralTreeNode
+		for rows.Next() {
+			var parentID uint
+			var referredAt time.Time
+			child := models.ReferralTreeNode{Children: make([]models.ReferralTreeNode, 0)}
+			if err := rows.Scan(&parentID, &child.VaultID, &child.Uid, &referredAt, &child.Valid); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to scan referral tree: %w", err)
+			}
+			child.ReferredAt = referredAt.Unix()
+			parents = append(parents, parentID)
+			children = append(children, child)
+		}
+		if err := rows.Close(); err != nil {
+			return nil, fmt.Errorf("failed to get referral tree: %w", err)
+		}
+		for i, child := range children {
+			parent := byID[parents[i]]
+			parent.Children = append(parent.Children, child)
+		}
+		nodes += len(children)
+		// the children slices are complete, the next level points into them
+		next := make([]*models.ReferralTreeNode, 0, len(children))
+		for _, node := range level {
+			for i := range node.Children {
+				next = append(next, &node.Children[i])
+			}
+		}
+		level = next
+	}
+	return root, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestBuildReferralRewards(t *testing.T) {
+	season := config.AirdropSeason{
+		ID:       2,
+		Referral: config.ReferralScheme{DirectShare: 0.1, SecondLevelShare: 0.05, MaxPointsPerJob: 50},
+	}
+	shares := []referralShares{
+		{ReferrerID: 1, DirectPoints: 100, SecondLevelPoints: 200},
+		{ReferrerID: 2, DirectPoints: 1000},
+		{ReferrerID: 3},
+	}
+	rewards := buildReferralRewards(shares, 7, season)
+	assert.Len(t, rewards, 2)
+	assert.Equal(t, uint(1), rewards[0].VaultID)
+	assert.Equal(t, uint(7), rewards[0].JobID)
+	assert.Equal(t, uint(2), rewards[0].SeasonID)
+	assert.InDelta(t, 20, rewards[0].Points, 1e-9)
+	assert.InDelta(t, 50, rewards[1].Points, 1e-9)
+}
+
+func TestBuildReferralAnalytics(t *testing.T) {
+	links := []models.ReferralLink{
+		{ReferrerID: 1, RefereeID: 2, ReferredAt: 0, Valid: true},
+		{ReferrerID: 1, RefereeID: 3, ReferredAt: 7200, Valid: false},
+		{ReferrerID: 2, RefereeID: 4, ReferredAt: 100, Valid: true},
+	}
+	for i := uint(0); i < referralFanoutThreshold; i++ {
+		links = append(links, models.ReferralLink{ReferrerID: 5, RefereeID: 100 + i, ReferredAt: int64(i) * 60})
+	}
+	analytics := buildReferralAnalytics(links, 2)
+	assert.Equal(t, int64(3), analytics.Referrers)
+	assert.Equal(t, int64(23), analytics.Referrals)
+	assert.Equal(t, int64(2), analytics.ValidReferrals)
+	assert.Len(t, analytics.TopReferrers, 2)
+	assert.Equal(t, uint(1), analytics.TopReferrers[0].VaultID)
+	assert.Equal(t, int64(1), analytics.TopReferrers[0].SecondLevel)
+	assert.InDelta(t, 0.5, analytics.TopReferrers[0].Conversion, 1e-9)
+	assert.Equal(t, int64(1), analytics.TopReferrers[0].MaxReferralsHour)
+	assert.Len(t, analytics.Suspicious, 1)
+	assert.Equal(t, uint(5), analytics.Suspicious[0].VaultID)
+	assert.Equal(t, []string{models.ReferralFlagLowConversion, models.ReferralFlagBurst}, analytics.Suspicious[0].Flags)
+
+	empty := buildReferralAnalytics(nil, 10)
+	assert.Empty(t, empty.TopReferrers)
+	assert.Zero(t, empty.Conversion)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// seasonTotals is the number of vaults and their points of a season
+type seasonTotals struct {
+	Count  int64
+	Points float64
+}
+
+// GetSeasonClosure returns the closure of a season, gorm.ErrRecordNotFound if the season close command never ran for it
+func (s *Storage) GetSeasonClosure(seasonID uint) (*models.SeasonClosure, error) {
+	var closure models.SeasonClosure
+	if err := s.db.Where("season_id = ?", seasonID).First(&closure).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get season closure: %w", err)
+	}
+	return &closure, nil
+}
+
+// GetClosedSeasonIDs returns the seasons the season close command ran for, including closures in progress
+func (s *Storage) GetClosedSeasonIDs() (map[uint]bool, error) {
+	var ids []uint
+	if err := s.db.Model(&models.SeasonClosure{}).Pluck("season_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get season closures: %w", err)
+	}
+	res := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// GetVaultCountBeforeSeason returns the number of vaults still in a season before the given one
+func (s *Storage) GetVaultCountBeforeSeason(seasonID uint) (int64, error) {
+	var count int64
+	if err := s.db.Model(&models.Vault{}).Where("current_season_id < ?", seasonID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count vaults before season %d: %w", seasonID, err)
+	}
+	return count, nil
+}
+
+// GetVaultCountAfterSeason returns the number of vaults already moved to a season after the given one
+func (s *Storage) GetVaultCountAfterSeason(seasonID uint) (int64, error) {
+	var count int64
+	if err := s.db.Model(&models.Vault{}).Where("current_season_id > ?", seasonID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count vaults after season %d: %w", seasonID, err)
+	}
+	return count, nil
+}
+
+// CloseSeason freezes the final ranks of a season and commits the full stats of its vaults to vault_season_stats
+// in batches, each batch moves its vaults to the next season in one transaction. The closure row makes the command
+// resumable: the ranks are only frozen on the first run. The committed stats are verified against the vaults before
+// the season is marked closed.
+func (s *Storage) CloseSeason(seasonID, nextSeasonID uint, batchSize int) (*models.SeasonClosure, error) {
+	if nextSeasonID <= seasonID {
+		return nil, fmt.Errorf("next season %d must be after season %d", nextSeasonID, seasonID)
+	}
+	previous, err := s.GetVaultCountBeforeSeason(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if previous > 0 {
+		return nil, fmt.Errorf("%d vaults are in a season before %d, close it first", previous, seasonID)
+	}
+	closure, err := s.GetSeasonClosure(seasonID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.UpdateVaultRanks(); err != nil {
+			return nil, fmt.Errorf("failed to freeze vault ranks: %w", err)
+		}
+		if err := s.UpdateVaultSwapRanks(); err != nil {
+			return nil, fmt.Errorf("failed to freeze vault swap ranks: %w", err)
+		}
+		closure = &models.SeasonClosure{
+			SeasonID:     seasonID,
+			NextSeasonID: nextSeasonID,
+			Status:       models.SeasonClosureInProgress,
+		}
+		if err := s.db.Create(closure).Error; err != nil {
+			return nil, fmt.Errorf("failed to create season closure: %w", err)
+		}
+	case err != nil:
+		return nil, err
+	case closure.NextSeasonID != nextSeasonID:
+		return nil, fmt.Errorf("season %d is being closed into season %d", seasonID, closure.NextSeasonID)
+	case closure.Status == models.SeasonClosureClosed:
+		// vaults can still join a season closed before its end, running the command again moves them
+		var leftover int64
+		if err := s.db.Model(&models.Vault{}).Where("current_season_id = ?", seasonID).Count(&leftover).Error; err != nil {
+			return nil, fmt.Errorf("failed to count season %d vaults: %w", seasonID, err)
+		}
+		if leftover == 0 {
+			return nil, fmt.Errorf("season %d is already closed", seasonID)
+		}
+	}
+
+	expected, err := s.getExpectedSeasonTotals(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	lastID := uint(0)
+	for {
+		ids, err := s.getSeasonVaultIDs(seasonID, lastID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		if err := s.commitSeasonBatch(seasonID, nextSeasonID, lastID, ids[len(ids)-1]); err != nil {
+			return nil, err
+		}
+		lastID = ids[len(ids)-1]
+	}
+
+	committed, err := s.verifySeasonClosure(seasonID, expected)
+	if err != nil {
+		return nil, err
+	}
+	closure.Status = models.SeasonClosureClosed
+	closure.VaultCount = committed.Count
+	closure.TotalPoints = committed.Points
+	if closure.ClosedAt == 0 {
+		closure.ClosedAt = time.Now().UTC().Unix()
+	}
+	if err := s.db.Save(closure).Error; err != nil {
+		return nil, fmt.Errorf("failed to save season closure: %w", err)
+	}
+	return closure, nil
+}
+
+// getExpectedSeasonTotals returns the vaults and points the season stats must hold once the season is closed:
+// the vaults still in the season and the stats already committed for the other vaults
+func (s *Storage) getExpectedSeasonTotals(seasonID uint) (seasonTotals, error) {
+	var pending, committed seasonTotals
+	if err := s.db.Model(&models.Vault{}).Where("current_season_id = ?", seasonID).
+		Select("COUNT(*), COALESCE(SUM(total_points), 0)").Row().Scan(&pending.Count, &pending.Points); err != nil {
+		return seasonTotals{}, fmt.Errorf("failed to get season %d vault totals: %w", seasonID, err)
+	}
+	if err := s.db.Model(&models.VaultSeasonStats{}).
+		Where("season_id = ? AND vault_id NOT IN (SELECT id FROM vaults WHERE current_season_id = ?)", seasonID, seasonID).
+		Select("COUNT(*), COALESCE(SUM(points), 0)").Row().Scan(&committed.Count, &committed.Points); err != nil {
+		return seasonTotals{}, fmt.Errorf("failed to get season %d committed totals: %w", seasonID, err)
+	}
+	return seasonTotals{Count: pending.Count + committed.Count, Points: pending.Points + committed.Points}, nil
+}
+
+func (s *Storage) getSeasonVaultIDs(seasonID, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	if err := s.db.Model(&models.Vault{}).Where("current_season_id = ? AND id > ?", seasonID, afterID).
+		Order("id asc").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get season %d vaults: %w", seasonID, err)
+	}
+	return ids, nil
+}
+
+// commitSeasonBatch commits the stats of the season vaults with fromID < id <= toID and resets them for the next season
+func (s *Storage) commitSeasonBatch(seasonID, nextSeasonID, fromID, toID uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		qry := "INSERT INTO vault_season_stats (created_at, updated_at, vault_id, season_id, `rank`, points, balance, lp_value, swap_volume, swap_volume_rank, nft_value, referral_count, milestone_id) " +
+			"SELECT NOW(), NOW(), id, current_season_id, `rank`, total_points, balance, lp_value, swap_volume, swap_volume_rank, nft_value, referral_count, next_milestone_id " +
+			"FROM vaults WHERE current_season_id = ? AND id > ? AND id <= ? " +
+			"ON DUPLICATE KEY UPDATE updated_at = NOW(), `rank` = VALUES(`rank`), points = VALUES(points), balance = VALUES(balance), lp_value = VALUES(lp_value), " +
+			"swap_volume = VALUES(swap_volume), swap_volume_rank = VALUES(swap_volume_rank), nft_value = VALUES(nft_value), referral_count = VALUES(referral_count), milestone_id = VALUES(milestone_id)"
+		if err := tx.Exec(qry, seasonID, fromID, toID).Error; err != nil {
+			return fmt.Errorf("failed to commit season stats: %w", err)
+		}
+		qry = "UPDATE vaults SET current_season_id = ?, `rank` = 0, total_points = 0, total_vault_value = 0, balance = 0, lp_value = 0, nft_value = 0, " +
+			"swap_volume = 0, swap_volume_rank = 0, referral_count = 0, next_milestone_id = 0 WHERE current_season_id = ? AND id > ? AND id <= ?"
+		if err := tx.Exec(qry, nextSeasonID, seasonID, fromID, toID).Error; err != nil {
+			return fmt.Errorf("failed to reset vaults: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close season %d for vaults %d-%d: %w", seasonID, fromID, toID, err)
+	}
+	return nil
+}
+
+// verifySeasonClosure checks no vault is left in the season and the season stats match the expected totals
+func (s *Storage) verifySeasonClosure(seasonID uint, expected seasonTotals) (seasonTotals, error) {
+	var remaining int64
+	if err := s.db.Model(&models.Vault{}).Where("current_season_id = ?", seasonID).Count(&remaining).Error; err != nil {
+		return seasonTotals{}, fmt.Errorf("failed to count season %d vaults: %w", seasonID, err)
+	}
+	if remaining > 0 {
+		return seasonTotals{}, fmt.Errorf("%d vaults are still in season %d", remaining, seasonID)
+	}
+	var committed seasonTotals
+	if err := s.db.Model(&models.VaultSeasonStats{}).Where("season_id = ?", seasonID).
+		Select("COUNT(*), COALESCE(SUM(points), 0)").Row().Scan(&committed.Count, &committed.Points); err != nil {
+		return seasonTotals{}, fmt.Errorf("failed to get season %d stats totals: %w", seasonID, err)
+	}
+	if err := checkSeasonTotals(expected, committed); err != nil {
+		return seasonTotals{}, fmt.Errorf("season %d verification failed: %w", seasonID, err)
+	}
+	return committed, nil
+}
+
+// checkSeasonTotals compares the committed season totals with the expected ones, points are compared with a relative tolerance
+// as they are summed from decimal and float columns
+func checkSeasonTotals(expected, committed seasonTotals) error {
+	if expected.Count != committed.Count {
+		return fmt.Errorf("expected %d vault stats, got %d", expected.Count, committed.Count)
+	}
+	if math.Abs(expected.Points-committed.Points) > 1e-6*math.Max(1, math.Abs(expected.Points)) {
+		return fmt.Errorf("expected %f points, got %f", expected.Points, committed.Points)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSeasonTotals(t *testing.T) {
+	expected := seasonTotals{Count: 3, Points: 1234567.891}
+	assert.NoError(t, checkSeasonTotals(expected, seasonTotals{Count: 3, Points: 1234567.8910001}))
+	assert.Error(t, checkSeasonTotals(expected, seasonTotals{Count: 2, Points: 1234567.891}))
+	assert.Error(t, checkSeasonTotals(expected, seasonTotals{Count: 3, Points: 1234560}))
+	assert.NoError(t, checkSeasonTotals(seasonTotals{}, seasonTotals{}))
+}
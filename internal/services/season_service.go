@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/seasons"
+)
+
+func preloadSeason(db *gorm.DB) *gorm.DB {
+	return db.Preload("Milestones", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc")
+	}).Preload("NFTs").Preload("Tokens")
+}
+
+// GetSeasons returns all seasons with their milestones, nfts and tokens
+func (s *Storage) GetSeasons() ([]models.Season, error) {
+	var seasonList []models.Season
+	if err := preloadSeason(s.db).Order("start asc").Find(&seasonList).Error; err != nil {
+		return nil, fmt.Errorf("failed to get seasons: %w", err)
+	}
+	return seasonList, nil
+}
+
+// GetSeason returns the season with the given season id, gorm.ErrRecordNotFound if none is stored
+func (s *Storage) GetSeason(seasonID uint) (*models.Season, error) {
+	var season models.Season
+	if err := preloadSeason(s.db).Where("season_id = ?", seasonID).First(&season).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get season: %w", err)
+	}
+	return &season, nil
+}
+
+// CreateSeason creates a season with its milestones, nfts and tokens
+func (s *Storage) CreateSeason(season *models.Season) error {
+	if err := s.db.Create(season).Error; err != nil {
+		return fmt.Errorf("failed to create season: %w", err)
+	}
+	return nil
+}
+
+// UpdateSeason saves all fields of season and replaces its milestones, nfts and tokens in one transaction
+func (s *Storage) UpdateSeason(season *models.Season) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteSeasonChildren(tx, season.SeasonID); err != nil {
+			return err
+		}
+		for i := range season.Milestones {
+			season.Milestones[i].Model = gorm.Model{}
+		}
+		for i := range season.NFTs {
+			season.NFTs[i].Model = gorm.Model{}
+		}
+		for i := range season.Tokens {
+			season.Tokens[i].Model = gorm.Model{}
+		}
+		return tx.Save(season).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update season: %w", err)
+	}
+	return nil
+}
+
+// DeleteSeason permanently deletes a season with its milestones, nfts and tokens
+func (s *Storage) DeleteSeason(seasonID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteSeasonChildren(tx, seasonID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("season_id = ?", seasonID).Delete(&models.Season{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete season: %w", err)
+	}
+	return nil
+}
+
+func deleteSeasonChildren(tx *gorm.DB, seasonID uint) error {
+	for _, child := range []interface{}{&models.SeasonMilestone{}, &models.SeasonNFT{}, &models.SeasonToken{}} {
+		if err := tx.Unscoped().Where("season_id = ?", seasonID).Delete(child).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSeasonsVersion returns a value which changes whenever a season is created, updated or deleted
+func (s *Storage) GetSeasonsVersion() (string, error) {
+	var count int64
+	var updatedAt *time.Time
+	if err := s.db.Model(&models.Season{}).Select("COUNT(*), MAX(updated_at)").Row().Scan(&count, &updatedAt); err != nil {
+		return "", fmt.Errorf("failed to get seasons version: %w", err)
+	}
+	if updatedAt == nil {
+		return fmt.Sprintf("%d", count), nil
+	}
+	return fmt.Sprintf("%d-%d", count, updatedAt.UnixNano()), nil
+}
+
+// LoadSeasonRegistry loads the seasons table into registry, an empty table is seeded with the seasons of the config file first
+func (s *Storage) LoadSeasonRegistry(registry *seasons.Registry, seed []config.AirdropSeason) error {
+	var count int64
+	if err := s.db.Model(&models.Season{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count seasons: %w", err)
+	}
+	if count == 0 && len(seed) > 0 {
+		seasonList := make([]models.Season, 0, len(seed))
+		for _, season := range seed {
+			seasonList = append(seasonList, seasons.ToModel(season))
+		}
+		if err := s.db.Create(&seasonList).Error; err != nil {
+			return fmt.Errorf("failed to seed seasons: %w", err)
+		}
+	}
+	return s.reloadSeasonRegistry(registry)
+}
+
+// RefreshSeasonRegistry reloads registry if the seasons table changed since it was loaded
+func (s *Storage) RefreshSeasonRegistry(registry *seasons.Registry) (bool, error) {
+	version, err := s.GetSeasonsVersion()
+	if err != nil {
+		return false, err
+	}
+	if version == registry.Version() {
+		return false, nil
+	}
+	return true, s.reloadSeasonRegistry(registry)
+}
+
+func (s *Storage) reloadSeasonRegistry(registry *seasons.Registry) error {
+	// the version is read first, a change made while loading is picked up by the next refresh
+	version, err := s.GetSeasonsVersion()
+	if err != nil {
+		return err
+	}
+	seasonList, err := s.GetSeasons()
+	if err != nil {
+		return err
+	}
+	airdropSeasons := make([]config.AirdropSeason, 0, len(seasonList))
+	for _, season := range seasonList {
+		airdropSeasons = append(airdropSeasons, seasons.FromModel(season))
+	}
+	registry.Load(airdropSeasons, version)
+	return nil
+}
+
+// WatchSeasonRegistry refreshes registry every interval until stop is closed, so running processes pick up the
+// season changes made through the admin endpoints without a restart
+func (s *Storage) WatchSeasonRegistry(registry *seasons.Registry, interval time.Duration, stop <-chan struct{}) {
+	logger := logrus.WithField("module", "season_registry").Logger
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+			reloaded, err := s.RefreshSeasonRegistry(registry)
+			if err != nil {
+				logger.Errorf("failed to refresh season registry: %v", err)
+				continue
+			}
+			if reloaded {
+				logger.Infof("season registry reloaded, version %s", registry.Version())
+			}
+		}
+	}
+}
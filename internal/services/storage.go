@@ -32,10 +32,16 @@ func NewStorage(cfg *config.Config) (*Storage, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	err = database.AutoMigrate(&models.Vault{}, &models.CoinDBModel{}, &models.Job{}, &models.VaultShareAppearance{}, &models.VaultSeasonStats{})
+	err = database.AutoMigrate(&models.Vault{}, &models.CoinDBModel{}, &models.Job{}, &models.VaultShareAppearance{}, &models.VaultSeasonStats{}, &models.PriceHistory{}, &models.Swap{}, &models.VolumeCheckpoint{}, &models.Token{}, &models.LeaderboardSnapshot{}, &models.VaultRankHistory{}, &models.SeasonClosure{}, &models.Season{}, &models.SeasonMilestone{}, &models.SeasonNFT{}, &models.SeasonToken{}, &models.VaultMilestone{}, &models.ReferralCode{}, &models.VaultReferral{}, &models.ReferralReward{}, &models.Quest{}, &models.QuestCompletion{}, &models.Badge{}, &models.VaultBadge{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
+	// tokens used to be unique per chain and contract, which left room for one coin without contract per chain
+	if database.Migrator().HasIndex(&models.Token{}, "token_chain_contract_idx") {
+		if err := database.Migrator().DropIndex(&models.Token{}, "token_chain_contract_idx"); err != nil {
+			return nil, fmt.Errorf("failed to drop token_chain_contract_idx: %w", err)
+		}
+	}
 
 	log.Println("connected to mysql database")
 	return &Storage{db: database}, nil
@@ -108,6 +114,20 @@ SET vaults.rank = ranked_vaults.vaultrank ;
 	return s.db.Exec(sql).Error
 }
 
+// UpdateVaultSwapRanks ranks the vaults with join_airdrop = 1 by their season swap volume,
+// vaults without swap volume get rank 0
+func (s *Storage) UpdateVaultSwapRanks() error {
+	sql := `
+UPDATE vaults
+    LEFT JOIN (
+        SELECT id, ROW_NUMBER() OVER (ORDER BY swap_volume DESC, id ASC) as swaprank
+        FROM vaults WHERE vaults.join_airdrop = 1 AND vaults.swap_volume > 0
+    ) ranked_vaults ON vaults.id = ranked_vaults.id
+SET vaults.swap_volume_rank = COALESCE(ranked_vaults.swaprank, 0);
+`
+	return s.db.Exec(sql).Error
+}
+
 func (s *Storage) UpdateVaultBalance() error {
 	sql := `UPDATE vaults
 		JOIN (
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// SaveSwaps stores the given swaps, swaps which are already recorded (same tx hash) are ignored
+func (s *Storage) SaveSwaps(swaps []models.Swap) error {
+	if len(swaps) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(swaps, 500).Error; err != nil {
+		return fmt.Errorf("failed to save swaps: %w", err)
+	}
+	return nil
+}
+
+// HasSwaps returns true if at least one swap is recorded
+func (s *Storage) HasSwaps() (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Swap{}).Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count swaps: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetUnpricedSwaps returns the swaps since the given time recorded with a received token but without volume
+func (s *Storage) GetUnpricedSwaps(since time.Time) ([]models.Swap, error) {
+	var swaps []models.Swap
+	if err := s.db.Where("volume_usd = 0 AND raw_amount <> '' AND swapped_at >= ?", since).Find(&swaps).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unpriced swaps: %w", err)
+	}
+	return swaps, nil
+}
+
+// UpdateSwapVolume sets the volume of a swap
+func (s *Storage) UpdateSwapVolume(id uint, volumeUSD float64) error {
+	if err := s.db.Model(&models.Swap{}).Where("id = ?", id).UpdateColumn("volume_usd", volumeUSD).Error; err != nil {
+		return fmt.Errorf("failed to update volume of swap %d: %w", id, err)
+	}
+	return nil
+}
+
+// SeedLegacySwaps records the swap volume vaults accumulated in the given season before the swap ledger existed, as one
+// swap per vault at the start of the season. It returns the number of seeded vaults.
+func (s *Storage) SeedLegacySwaps(season config.AirdropSeason) (int64, error) {
+	qry := `INSERT IGNORE INTO swaps (created_at, updated_at, tx_hash, from_address, volume_usd, swapped_at, source, asset, vault_id)
+		SELECT NOW(), NOW(), CONCAT('legacy:', id, ':', current_season_id), '', swap_volume, ?, ?, '', id FROM vaults
+		WHERE swap_volume > 0 AND current_season_id = ? AND deleted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	result := s.db.WithContext(ctx).Exec(qry, season.Start, models.SwapSourceLegacy, season.ID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to seed legacy swaps: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// AssignSwapsToVault links the unassigned swaps of the given addresses to the vault
+func (s *Storage) AssignSwapsToVault(vaultID uint, addresses []string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	normalized := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		normalized = append(normalized, models.NormalizeSwapAddress(address))
+	}
+	qry := `UPDATE swaps SET vault_id = ? WHERE vault_id = 0 AND from_address IN ?`
+	if err := s.db.Exec(qry, vaultID, normalized).Error; err != nil {
+		return fmt.Errorf("failed to assign swaps to vault %d: %w", vaultID, err)
+	}
+	return nil
+}
+
+// GetSwapVolumeByAsset returns the volume of vault swaps since the given time grouped by pool, highest volume first
+func (s *Storage) GetSwapVolumeByAsset(since time.Time) ([]models.AssetVolume, error) {
+	var volumes []models.AssetVolume
+	qry := `SELECT asset, SUM(volume_usd) AS volume_usd, COUNT(*) AS swap_count FROM swaps
+		WHERE vault_id > 0 AND asset <> '' AND swapped_at >= ? AND deleted_at IS NULL
+		GROUP BY asset ORDER BY volume_usd DESC`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Raw(qry, since).Scan(&volumes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get swap volume by asset: %w", err)
+	}
+	return volumes, nil
+}
+
+// GetWindowSwapLeaders returns the vaults with join_airdrop = 1 ordered by their swap volume since the given time
+func (s *Storage) GetWindowSwapLeaders(since time.Time, offset int64, limit int) ([]models.VaultSwapVolume, error) {
+	var volumes []models.VaultSwapVolume
+	qry := `SELECT swaps.vault_id AS vault_id, SUM(swaps.volume_usd) AS volume_usd FROM swaps
+		JOIN vaults ON vaults.id = swaps.vault_id
+		WHERE vaults.join_airdrop = 1 AND vaults.deleted_at IS NULL AND swaps.swapped_at >= ? AND swaps.deleted_at IS NULL
+		GROUP BY swaps.vault_id ORDER BY volume_usd DESC, swaps.vault_id ASC LIMIT ? OFFSET ?`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Raw(qry, since, limit, offset).Scan(&volumes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get swap leaders: %w", err)
+	}
+	return volumes, nil
+}
+
+// GetWindowSwapTotals returns the number of vaults with join_airdrop = 1 which swapped since the given time and their total volume
+func (s *Storage) GetWindowSwapTotals(since time.Time) (int64, float64, error) {
+	var totals struct {
+		VaultCount int64
+		VolumeUSD  float64
+	}
+	qry := `SELECT COUNT(DISTINCT swaps.vault_id) AS vault_count, COALESCE(SUM(swaps.volume_usd), 0) AS volume_usd FROM swaps
+		JOIN vaults ON vaults.id = swaps.vault_id
+		WHERE vaults.join_airdrop = 1 AND vaults.deleted_at IS NULL AND swaps.swapped_at >= ? AND swaps.deleted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Raw(qry, since).Scan(&totals).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get swap totals: %w", err)
+	}
+	return totals.VaultCount, totals.VolumeUSD, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/tokens"
+)
+
+// GetTokens returns all tokens of the token registry
+func (s *Storage) GetTokens() ([]models.Token, error) {
+	var tokenList []models.Token
+	if err := s.db.Order("chain, contract_address").Find(&tokenList).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tokens: %w", err)
+	}
+	return tokenList, nil
+}
+
+// GetToken returns the registry token of the given id, gorm.ErrRecordNotFound if none is stored
+func (s *Storage) GetToken(id uint) (*models.Token, error) {
+	var token models.Token
+	if err := s.db.First(&token, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return &token, nil
+}
+
+func (s *Storage) CreateToken(token *models.Token) error {
+	if err := s.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+	return nil
+}
+
+// UpdateToken saves all fields of token, including zero values such as a disabled scoring
+func (s *Storage) UpdateToken(token *models.Token) error {
+	if err := s.db.Save(token).Error; err != nil {
+		return fmt.Errorf("failed to update token: %w", err)
+	}
+	return nil
+}
+
+// DeleteToken permanently deletes a registry token, so the same chain and contract can be registered again
+func (s *Storage) DeleteToken(id uint) error {
+	if err := s.db.Unscoped().Delete(&models.Token{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+// LoadTokenRegistry loads the token registry table into registry, an empty table is seeded with the default tokens first
+func (s *Storage) LoadTokenRegistry(registry *tokens.Registry) error {
+	var count int64
+	if err := s.db.Model(&models.Token{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if count == 0 {
+		defaults, err := tokens.DefaultRegistryTokens()
+		if err != nil {
+			return fmt.Errorf("failed to get default tokens: %w", err)
+		}
+		if err := s.db.CreateInBatches(defaults, 100).Error; err != nil {
+			return fmt.Errorf("failed to seed tokens: %w", err)
+		}
+	}
+	tokenList, err := s.GetTokens()
+	if err != nil {
+		return err
+	}
+	registry.Load(tokenList)
+	return nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// applyValuationGuard caps the USD value of a holding by the liquidity of its token.
+// Tokens below the configured volume / market cap thresholds are excluded, and the
+// remaining holdings are credited at most MaxVolumeShare of the token's 24h volume.
+func applyValuationGuard(value float64, quote models.TokenQuote, guard config.Valuation) (float64, string) {
+	if guard.MinVolume24h > 0 && quote.Volume24h < guard.MinVolume24h {
+		return 0, models.ValuationStatusExcluded
+	}
+	if guard.MinMarketCap > 0 && quote.MarketCap < guard.MinMarketCap {
+		return 0, models.ValuationStatusExcluded
+	}
+	if guard.MaxVolumeShare > 0 {
+		maxValue := quote.Volume24h * guard.MaxVolumeShare
+		if value > maxValue {
+			return maxValue, models.ValuationStatusCapped
+		}
+	}
+	return value, models.ValuationStatusOK
+}
+
+// guardCoinValue applies the liquidity guard to a holding of a CMC priced token. While the guard is enabled
+// a token without a CMC quote can't be checked and is excluded.
+func guardCoinValue(coin models.CoinDBModel, value float64, quotes map[int]models.TokenQuote, guard config.Valuation) (float64, string) {
+	if !guard.Enabled || coin.IsNative || coin.ContractAddress == "" || coin.CMCId == 0 {
+		return value, models.ValuationStatusOK
+	}
+	quote, ok := quotes[coin.CMCId]
+	if !ok {
+		return 0, models.ValuationStatusNoQuote
+	}
+	return applyValuationGuard(value, quote, guard)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestApplyValuationGuard(t *testing.T) {
+	guard := config.Valuation{
+		MinVolume24h:   10000,
+		MinMarketCap:   100000,
+		MaxVolumeShare: 0.1,
+	}
+	tests := []struct {
+		name           string
+		value          float64
+		quote          models.TokenQuote
+		guard          config.Valuation
+		expectedValue  float64
+		expectedStatus string
+	}{
+		{
+			name:           "liquid token",
+			value:          500,
+			quote:          models.TokenQuote{Volume24h: 1000000, MarketCap: 50000000},
+			guard:          guard,
+			expectedValue:  500,
+			expectedStatus: models.ValuationStatusOK,
+		},
+		{
+			name:           "holding larger than volume share",
+			value:          50000,
+			quote:          models.TokenQuote{Volume24h: 20000, MarketCap: 50000000},
+			guard:          guard,
+			expectedValue:  2000,
+			expectedStatus: models.ValuationStatusCapped,
+		},
+		{
+			name:           "volume below threshold",
+			value:          500,
+			quote:          models.TokenQuote{Volume24h: 9999, MarketCap: 50000000},
+			guard:          guard,
+			expectedValue:  0,
+			expectedStatus: models.ValuationStatusExcluded,
+		},
+		{
+			name:           "market cap below threshold",
+			value:          500,
+			quote:          models.TokenQuote{Volume24h: 1000000, MarketCap: 99999},
+			guard:          guard,
+			expectedValue:  0,
+			expectedStatus: models.ValuationStatusExcluded,
+		},
+		{
+			name:           "guard disabled",
+			value:          500,
+			quote:          models.TokenQuote{},
+			guard:          config.Valuation{},
+			expectedValue:  500,
+			expectedStatus: models.ValuationStatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, status := applyValuationGuard(tt.value, tt.quote, tt.guard)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
+
+func TestGuardCoinValue(t *testing.T) {
+	guard := config.Valuation{
+		Enabled:        true,
+		MinVolume24h:   10000,
+		MaxVolumeShare: 0.1,
+	}
+	token := models.CoinDBModel{CoinBase: models.CoinBase{ContractAddress: "0xtoken", CMCId: 1}}
+	quotes := map[int]models.TokenQuote{
+		1: {CMCId: 1, Volume24h: 1000},
+	}
+	tests := []struct {
+		name           string
+		coin           models.CoinDBModel
+		quotes         map[int]models.TokenQuote
+		guard          config.Valuation
+		expectedValue  float64
+		expectedStatus string
+	}{
+		{
+			name:           "guard disabled",
+			coin:           token,
+			quotes:         quotes,
+			guard:          config.Valuation{MinVolume24h: 10000},
+			expectedValue:  500,
+			expectedStatus: models.ValuationStatusOK,
+		},
+		{
+			name:           "native coin",
+			coin:           models.CoinDBModel{CoinBase: models.CoinBase{IsNative: true, CMCId: 1}},
+			quotes:         quotes,
+			guard:          guard,
+			expectedValue:  500,
+			expectedStatus: models.ValuationStatusOK,
+		},
+		{
+			name:           "token without cmc id",
+			coin:           models.CoinDBModel{CoinBase: models.CoinBase{ContractAddress: "0xtoken"}},
+			quotes:         quotes,
+			guard:          guard,
+			expectedValue:  500,
+			expectedStatus: models.ValuationStatusOK,
+		},
+		{
+			name:           "token below thresholds",
+			coin:           token,
+			quotes:         quotes,
+			guard:          guard,
+			expectedValue:  0,
+			expectedStatus: models.ValuationStatusExcluded,
+		},
+		{
+			name:           "missing quote fails closed",
+			coin:           token,
+			quotes:         map[int]models.TokenQuote{},
+			guard:          guard,
+			expectedValue:  0,
+			expectedStatus: models.ValuationStatusNoQuote,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, status := guardCoinValue(tt.coin, 500, tt.quotes, tt.guard)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
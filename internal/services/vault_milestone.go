@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// UnlockVaultMilestone adds the prize of the milestone at index to the vault points and records the unlock in one
+// transaction. Milestones are unlocked in order, a milestone which isn't the next one of the vault is skipped.
+func (s *Storage) UnlockVaultMilestone(vaultID, seasonID uint, job *models.Job, index int, prize int64) (bool, error) {
+	unlocked := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		qry := `UPDATE vaults SET next_milestone_id = ?, total_points = total_points + ? WHERE id = ? AND next_milestone_id = ?`
+		result := tx.Exec(qry, index+1, prize, vaultID, index)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		unlocked = true
+		return tx.Create(&models.VaultMilestone{
+			VaultID:        vaultID,
+			SeasonID:       seasonID,
+			MilestoneIndex: index,
+			JobID:          job.ID,
+			UnlockedAt:     time.Now().UTC().Unix(),
+			Prize:          prize,
+		}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to unlock milestone %d of vault %d: %w", index, vaultID, err)
+	}
+	return unlocked, nil
+}
+
+// GetVaultMilestones returns the milestones a vault unlocked during a season, ordered by milestone index
+func (s *Storage) GetVaultMilestones(vaultID, seasonID uint) ([]models.VaultMilestone, error) {
+	var milestones []models.VaultMilestone
+	if err := s.db.Where("vault_id = ? AND season_id = ?", vaultID, seasonID).Order("milestone_index asc").Find(&milestones).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vault milestones: %w", err)
+	}
+	return milestones, nil
+}
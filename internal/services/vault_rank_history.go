@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// WriteVaultRankHistory records the rank, points and swap rank of every ranked vault for the job,
+// a retried job overwrites its previous records
+func (s *Storage) WriteVaultRankHistory(job *models.Job, seasonID uint) error {
+	qry := "INSERT INTO vault_rank_history (created_at, updated_at, vault_id, job_id, season_id, job_date, `rank`, points, swap_volume_rank) " +
+		"SELECT NOW(), NOW(), id, ?, ?, ?, `rank`, total_points, swap_volume_rank FROM vaults WHERE join_airdrop = 1 AND `rank` > 0 " +
+		"ON DUPLICATE KEY UPDATE updated_at = NOW(), `rank` = VALUES(`rank`), points = VALUES(points), swap_volume_rank = VALUES(swap_volume_rank)"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err := s.db.WithContext(ctx).Exec(qry, job.ID, seasonID, models.GetDate(job.JobDate)).Error; err != nil {
+		return fmt.Errorf("failed to write vault rank history: %w", err)
+	}
+	return nil
+}
+
+// GetVaultRankHistory returns the rank history of a vault during a season, ordered by job date
+func (s *Storage) GetVaultRankHistory(vaultID, seasonID uint) ([]models.VaultRankHistory, error) {
+	var history []models.VaultRankHistory
+	if err := s.db.Where("vault_id = ? AND season_id = ?", vaultID, seasonID).Order("job_date asc").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vault rank history: %w", err)
+	}
+	return history, nil
+}
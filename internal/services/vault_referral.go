@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+const (
+	referralCodeLength   = 8
+	referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O and 1/I, codes are typed by users
+	referralCodeAttempts = 5
+)
+
+// newReferralCode returns a random referral code
+func newReferralCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(referralCodeAlphabet)))
+	for i := 0; i < referralCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+		sb.WriteByte(referralCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// GetOrCreateReferralCode returns the referral code of a vault, generating it on first use
+func (s *Storage) GetOrCreateReferralCode(vaultID uint) (string, error) {
+	for i := 0; i < referralCodeAttempts; i++ {
+		var existing models.ReferralCode
+		err := s.db.Where("vault_id = ?", vaultID).First(&existing).Error
+		if err == nil {
+			return existing.Code, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("failed to get referral code: %w", err)
+		}
+		code, err := newReferralCode()
+		if err != nil {
+			return "", err
+		}
+		// a failed insert is a code collision or a concurrent request for the same vault, both resolve on retry
+		if err := s.db.Create(&models.ReferralCode{VaultID: vaultID, Code: code}).Error; err == nil {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("failed to create referral code for vault %d", vaultID)
+}
+
+// GetVaultByReferralCode returns the vault owning a referral code, gorm.ErrRecordNotFound if the code is unknown
+func (s *Storage) GetVaultByReferralCode(code string) (*models.Vault, error) {
+	var vault models.Vault
+	err := s.db.Joins("JOIN referral_codes ON referral_codes.vault_id = vaults.id AND referral_codes.deleted_at IS NULL").
+		Where("referral_codes.code = ?", strings.ToUpper(strings.TrimSpace(code))).
+		First(&vault).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get vault by referral code: %w", err)
+	}
+	return &vault, nil
+}
+
+// CreateVaultReferral links the referee to its referrer, it returns false if the referee was already referred
+func (s *Storage) CreateVaultReferral(referrerID, refereeID uint, source string) (bool, error) {
+	if referrerID == refereeID {
+		return false, fmt.Errorf("vault %d can't refer itself", referrerID)
+	}
+	cycle, err := s.isReferredBy(referrerID, refereeID)
+	if err != nil {
+		return false, err
+	}
+	if cycle {
+		return false, fmt.Errorf("vault %d descends from vault %d: %w", referrerID, refereeID, models.ErrReferralCycle)
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.VaultReferral{
+		ReferrerID: referrerID,
+		RefereeID:  refereeID,
+		Source:     source,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create vault referral: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// isReferredBy reports whether the vault descends from ancestorID in the referral tree, walking up its referrers
+func (s *Storage) isReferredBy(vaultID, ancestorID uint) (bool, error) {
+	seen := map[uint]bool{vaultID: true}
+	for id := vaultID; ; {
+		var referrers []uint
+		if err := s.db.Model(&models.VaultReferral{}).Where("referee_id = ?", id).Pluck("referrer_id", &referrers).Error; err != nil {
+			return false, fmt.Errorf("failed to get referrer of vault %d: %w", id, err)
+		}
+		if len(referrers) == 0 {
+			return false, nil
+		}
+		id = referrers[0]
+		if id == ancestorID {
+			return true, nil
+		}
+		// a cycle recorded before the check existed
+		if seen[id] {
+			return false, nil
+		}
+		seen[id] = true
+	}
+}
+
+// GetVaultReferrer returns the vault which referred the referee, gorm.ErrRecordNotFound if it wasn't referred
+func (s *Storage) GetVaultReferrer(refereeID uint) (*models.Vault, error) {
+	var vault models.Vault
+	err := s.db.Joins("JOIN vault_referrals ON vault_referrals.referrer_id = vaults.id AND vault_referrals.deleted_at IS NULL").
+		Where("vault_referrals.referee_id = ?", refereeID).
+		First(&vault).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get vault referrer: %w", err)
+	}
+	return &vault, nil
+}
+
+// GetVaultReferrals returns the vaults referred by a vault, most recent first. A referral is valid once the referee
+// holds at least MinBalanceForValidReferral.
+func (s *Storage) GetVaultReferrals(referrerID uint) ([]models.ReferralEntry, error) {
+	rows, err := s.db.Table("vault_referrals").
+		Select("vaults.alias, vaults.uid, vaults.show_name_in_leaderboard, vaults.created_at, vault_referrals.created_at, "+
+			"vaults.balance + vaults.lp_value + vaults.nft_value >= ?, vault_referrals.source", MinBalanceForValidReferral).
+		Joins("JOIN vaults ON vaults.id = vault_referrals.referee_id AND vaults.deleted_at IS NULL").
+		Where("vault_referrals.referrer_id = ? AND vault_referrals.deleted_at IS NULL", referrerID).
+		Order("vault_referrals.created_at desc").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault referrals: %w", err)
+	}
+	defer rows.Close()
+	entries := make([]models.ReferralEntry, 0)
+	for rows.Next() {
+		var (
+			alias, uid            string
+			showName              bool
+			registeredAt, created time.Time
+			entry                 models.ReferralEntry
+		)
+		if err := rows.Scan(&alias, &uid, &showName, &registeredAt, &created, &entry.Valid, &entry.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan vault referral: %w", err)
+		}
+		entry.Name = models.LeaderboardName(alias, uid, showName)
+		entry.RegisteredAt = registeredAt.Unix()
+		entry.ReferredAt = created.Unix()
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// UpdateReferralCounts sets the referral count of every vault to the number of its valid referrals
+func (s *Storage) UpdateReferralCounts() error {
+	qry := `UPDATE vaults
+		LEFT JOIN (
+			SELECT vault_referrals.referrer_id, COUNT(*) AS cnt
+			FROM vault_referrals
+			JOIN vaults AS referees ON referees.id = vault_referrals.referee_id AND referees.deleted_at IS NULL
+			WHERE vault_referrals.deleted_at IS NULL AND referees.balance + referees.lp_value + referees.nft_value >= ?
+			GROUP BY vault_referrals.referrer_id
+		) AS valid_referrals ON vaults.id = valid_referrals.referrer_id
+		SET vaults.referral_count = COALESCE(valid_referrals.cnt, 0)`
+	if err := s.db.Exec(qry, MinBalanceForValidReferral).Error; err != nil {
+		return fmt.Errorf("failed to update referral counts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReferralCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newReferralCode()
+		assert.NoError(t, err)
+		assert.Len(t, code, referralCodeLength)
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(referralCodeAlphabet, r), code)
+		}
+		assert.False(t, seen[code], code)
+		seen[code] = true
+	}
+}
@@ -61,43 +61,6 @@ func (s *Storage) IncreaseVaultTotalValue(id uint, newValue float64) error {
 	return nil
 }
 
-func (s *Storage) CommitSeasonPoints(v models.Vault, newSeasonId uint) error {
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to start tx: %w", tx.Error)
-	}
-	// insert into vault_season_stats
-	qry := "INSERT INTO vault_season_stats (vault_id, season_id, `rank`, points, balance, lp_value, swap_volume, referral_count)" +
-		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)" +
-		"ON DUPLICATE KEY UPDATE  `rank`=?, points = ?, balance = ?, lp_value = ?, swap_volume = ?, referral_count = ?"
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-	if err := tx.WithContext(ctx).Exec(qry, v.ID,
-		v.CurrentSeasonID,
-		v.Rank,
-		v.TotalPoints,
-		v.Balance,
-		v.LPValue,
-		v.SwapVolume,
-		v.ReferralCount,
-		v.Rank,
-		v.TotalPoints,
-		v.Balance,
-		v.LPValue,
-		v.SwapVolume,
-		v.ReferralCount).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to commit season points: %w", err)
-	}
-	// reset current season points
-	qry = `UPDATE vaults SET current_season_id = ?, "rank" = 0, total_points = 0, total_vault_value = 0, balance = 0, lp_value = 0, swap_volume = 0, referral_count = 0, next_milestone_id=0 WHERE id = ?`
-	if err := tx.WithContext(ctx).Exec(qry, newSeasonId, v.ID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to reset vault current season points: %w", err)
-	}
-	return tx.Commit().Error
-}
-
 func (s *Storage) UpdateLPValue(id uint, lpValue int64) error {
 	qry := `UPDATE vaults SET lp_value = ?  WHERE id = ?`
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -192,16 +155,36 @@ func (s *Storage) GetLeaderVaultTotalPointsBySeason(seasonId uint) (float64, err
 	return totalPoints, nil
 }
 
-// TODO: rename the function to GetRankLeaderVaults
+// GetSwapLeaderVaults returns the vaults ordered by their season swap volume rank
 func (s *Storage) GetSwapLeaderVaults(fromRank int64, limit int) ([]models.Vault, error) {
 	var vaults []models.Vault
-	// where rank is not null and rank > fromRank
-	if err := s.db.Where("`rank` is not null and `rank` > ?  and join_airdrop = 1", fromRank).Order("`swap_volume` desc").Limit(limit).Find(&vaults).Error; err != nil {
+	if err := s.db.Where("swap_volume_rank > ? and join_airdrop = 1", fromRank).Order("swap_volume_rank asc").Limit(limit).Find(&vaults).Error; err != nil {
 		return nil, fmt.Errorf("failed to get leader vaults: %w", err)
 	}
 	return vaults, nil
 }
 
+// GetSwapLeaderVaultCount returns the number of vaults ranked by season swap volume
+func (s *Storage) GetSwapLeaderVaultCount() (int64, error) {
+	var count int64
+	if err := s.db.Model(&models.Vault{}).Where("swap_volume_rank > 0 and join_airdrop = 1").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to get swap leader vault count: %w", err)
+	}
+	return count, nil
+}
+
+// GetVaultsByIDs returns the vaults with the given ids
+func (s *Storage) GetVaultsByIDs(ids []uint) ([]models.Vault, error) {
+	var vaults []models.Vault
+	if len(ids) == 0 {
+		return vaults, nil
+	}
+	if err := s.db.Where("id IN ?", ids).Find(&vaults).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vaults: %w", err)
+	}
+	return vaults, nil
+}
+
 func (s *Storage) GetLeaderVaultCount() (int64, error) {
 	var count int64
 	if err := s.db.Model(&models.Vault{}).Where("`rank` is not null and `rank` > 0  and join_airdrop = 1").Count(&count).Error; err != nil {
@@ -246,12 +229,13 @@ func (s *Storage) GetLeaderVaultTotalBalanceBySeason(seasonId uint) (int64, erro
 	return totalBalance, nil
 }
 
+// GetLeaderVaultTotalVolume returns the season swap volume of the vaults ranked by season swap volume,
+// the same vaults GetSwapLeaderVaultCount counts
 func (s *Storage) GetLeaderVaultTotalVolume() (float64, error) {
-	// return sum of volume of all leader vaults
 	var totalVolume float64
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := s.db.WithContext(ctx).Model(&models.Vault{}).Select("sum(swap_volume)").Row().Scan(&totalVolume); err != nil {
+	if err := s.db.WithContext(ctx).Model(&models.Vault{}).Where("swap_volume_rank > 0 and join_airdrop = 1").Select("COALESCE(SUM(swap_volume), 0)").Row().Scan(&totalVolume); err != nil {
 		return 0, fmt.Errorf("failed to get leader vault total volume: %w", err)
 	}
 	return totalVolume, nil
@@ -301,17 +285,10 @@ func (s *Storage) UpdateVaultAvatar(vault *models.Vault) error {
 	return nil
 }
 
-func (s *Storage) UpdateReferralCount(vault *models.Vault) error {
-	qry := `UPDATE vaults SET referral_count = ? WHERE id = ?`
-	if err := s.db.Exec(qry, vault.ReferralCount, vault.ID).Error; err != nil {
-		return fmt.Errorf("failed to update vault refferal: %w", err)
-	}
-	return nil
-}
-
-func (s *Storage) UpdateVolume(vaultId uint, volume float64) error {
-	qry := `UPDATE vaults SET swap_volume = swap_volume + ? WHERE id = ?`
-	if err := s.db.Exec(qry, volume, vaultId).Error; err != nil {
+// UpdateVolume sets the vault swap volume to the sum of its recorded swaps since the given time
+func (s *Storage) UpdateVolume(vaultId uint, since time.Time) error {
+	qry := `UPDATE vaults SET swap_volume = (SELECT COALESCE(SUM(volume_usd), 0) FROM swaps WHERE vault_id = ? AND swapped_at >= ? AND deleted_at IS NULL) WHERE id = ?`
+	if err := s.db.Exec(qry, vaultId, since, vaultId).Error; err != nil {
 		return fmt.Errorf("failed to update vault swap_volume: %w", err)
 	}
 	return nil
@@ -324,11 +301,3 @@ func (s *Storage) GetSeasonStats(vaultId uint, seasonId uint) (models.VaultSeaso
 	}
 	return vaultStats, nil
 }
-
-func (s *Storage) UpdateVaultMilestone(vaultId uint, milestoneId int, prize float64) error {
-	qry := `UPDATE vaults SET next_milestone_id = ? , total_points = total_points + ? WHERE id = ?`
-	if err := s.db.Exec(qry, milestoneId, prize, vaultId).Error; err != nil {
-		return fmt.Errorf("failed to update vault next milestone: %w", err)
-	}
-	return nil
-}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// GetVolumeCheckpoint returns the checkpoint of the given tracker and affiliate, gorm.ErrRecordNotFound if none is stored
+func (s *Storage) GetVolumeCheckpoint(tracker, affiliate string) (*models.VolumeCheckpoint, error) {
+	var checkpoint models.VolumeCheckpoint
+	if err := s.db.Where("tracker = ? AND affiliate = ?", tracker, affiliate).First(&checkpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get volume checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// SaveVolumeCheckpoint creates or updates the checkpoint of a (tracker, affiliate) pair
+func (s *Storage) SaveVolumeCheckpoint(checkpoint *models.VolumeCheckpoint) error {
+	err := s.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"last_fetched", "cursor", "cursor_to", "last_attempt", "last_error", "updated_at"}),
+	}).Create(checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("failed to save volume checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GetVolumeCheckpoints returns the checkpoints of all volume sources
+func (s *Storage) GetVolumeCheckpoints() ([]models.VolumeCheckpoint, error) {
+	var checkpoints []models.VolumeCheckpoint
+	if err := s.db.Order("tracker, affiliate").Find(&checkpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to get volume checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
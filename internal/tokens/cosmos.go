@@ -0,0 +1,189 @@
+package tokens
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// cosmosChain is the REST endpoint and the native denom (not discovered) of a cosmos sdk chain
+type cosmosChain struct {
+	restBaseAddress string
+	nativeDenom     string
+}
+
+var cosmosChains = map[common.Chain]cosmosChain{
+	common.GaiaChain: {restBaseAddress: "https://cosmos-rest.publicnode.com", nativeDenom: "uatom"},
+	common.Osmosis:   {restBaseAddress: "https://osmosis-rest.publicnode.com", nativeDenom: "uosmo"},
+	common.Kujira:    {restBaseAddress: "https://kujira-rest.publicnode.com", nativeDenom: "ukuji"},
+	common.Dydx:      {restBaseAddress: "https://dydx-rest.publicnode.com", nativeDenom: "adydx"},
+	common.Terra:     {restBaseAddress: "https://terra-lcd.publicnode.com", nativeDenom: "uluna"},
+	common.Noble:     {restBaseAddress: "https://noble-api.polkachu.com", nativeDenom: "uusdc"},
+	common.Akash:     {restBaseAddress: "https://akash-rest.publicnode.com", nativeDenom: "uakt"},
+}
+
+type cosmosDiscoveryService struct {
+	logger          *logrus.Logger
+	chain           common.Chain
+	restBaseAddress string
+	nativeDenom     string
+	cmcService      *CMCService
+}
+
+func NewCosmosDiscoveryService(chain common.Chain, cmcService *CMCService) (AutoDiscoveryService, error) {
+	cosmos, ok := cosmosChains[chain]
+	if !ok {
+		return nil, fmt.Errorf("chain: %s is not supported", chain)
+	}
+	return &cosmosDiscoveryService{
+		logger:          logrus.WithField("module", "cosmos_discovery_service").Logger,
+		chain:           chain,
+		restBaseAddress: cosmos.restBaseAddress,
+		nativeDenom:     cosmos.nativeDenom,
+		cmcService:      cmcService,
+	}, nil
+}
+
+// Discover returns the non native denoms (IBC and token factory) held by address, the contract address is the denom
+func (c *cosmosDiscoveryService) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	if address == "" {
+		return nil, fmt.Errorf("empty address provided")
+	}
+	if chain != c.chain {
+		return nil, fmt.Errorf("chain does not support")
+	}
+	var balances cosmosBalancesResponse
+	if err := c.get(fmt.Sprintf("cosmos/bank/v1beta1/balances/%s", address), nil, &balances); err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	coins := make([]models.CoinBase, 0)
+	for _, balance := range balances.Balances {
+		if strings.EqualFold(balance.Denom, c.nativeDenom) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(balance.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		coin, err := c.Search(models.CoinBase{
+			Address:         address,
+			Balance:         balance.Amount,
+			Chain:           c.chain,
+			ContractAddress: balance.Denom,
+		})
+		if err != nil {
+			c.logger.WithError(err).WithField("denom", balance.Denom).Debug("failed to search denom")
+			continue
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+// Search resolves the ticker and decimals of a denom from its bank metadata, IBC denoms without
+// metadata fall back to the base denom of their trace
+func (c *cosmosDiscoveryService) Search(coin models.CoinBase) (models.CoinBase, error) {
+	cmcId, err := c.cmcService.GetCMCIDByContract(cmcChainMap[c.chain], coin.ContractAddress)
+	if err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to fetch cmc id: %w", err)
+	}
+	ticker, decimals, err := c.getDenomMetadata(coin.ContractAddress)
+	if err != nil {
+		return models.CoinBase{}, err
+	}
+	coin.CMCId = cmcId
+	coin.Ticker = ticker
+	coin.Decimals = decimals
+	return coin, nil
+}
+
+func (c *cosmosDiscoveryService) getDenomMetadata(denom string) (string, int, error) {
+	var metadataResp cosmosDenomMetadataResponse
+	err := c.get("cosmos/bank/v1beta1/denoms_metadata_by_query_string", url.Values{"denom": {denom}}, &metadataResp)
+	if err == nil {
+		metadata := metadataResp.Metadata
+		for _, unit := range metadata.DenomUnits {
+			if unit.Denom == metadata.Display && unit.Exponent > 0 {
+				ticker := metadata.Symbol
+				if ticker == "" {
+					ticker = strings.ToUpper(metadata.Display)
+				}
+				return ticker, unit.Exponent, nil
+			}
+		}
+	}
+	hash, isIBC := strings.CutPrefix(denom, "ibc/")
+	if !isIBC {
+		return "", 0, fmt.Errorf("metadata of denom %s not found", denom)
+	}
+	var traceResp cosmosDenomTraceResponse
+	if err := c.get(fmt.Sprintf("ibc/apps/transfer/v1/denom_traces/%s", hash), nil, &traceResp); err != nil {
+		return "", 0, fmt.Errorf("failed to get denom trace of %s: %w", denom, err)
+	}
+	return baseDenomMetadata(traceResp.DenomTrace.BaseDenom)
+}
+
+// baseDenomMetadata derives the ticker and decimals of a base denom from its micro (u) or atto (a) prefix
+func baseDenomMetadata(baseDenom string) (string, int, error) {
+	switch {
+	case len(baseDenom) > 1 && baseDenom[0] == 'u':
+		return strings.ToUpper(baseDenom[1:]), 6, nil
+	case len(baseDenom) > 1 && baseDenom[0] == 'a':
+		return strings.ToUpper(baseDenom[1:]), 18, nil
+	default:
+		return "", 0, fmt.Errorf("unknown decimals of base denom %s", baseDenom)
+	}
+}
+
+func (c *cosmosDiscoveryService) get(path string, params url.Values, result any) error {
+	reqUrl := fmt.Sprintf("%s/%s", c.restBaseAddress, path)
+	if len(params) > 0 {
+		reqUrl = fmt.Sprintf("%s?%s", reqUrl, params.Encode())
+	}
+	resp, err := http.Get(reqUrl)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+type (
+	cosmosBalancesResponse struct {
+		Balances []struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balances"`
+	}
+	cosmosDenomMetadataResponse struct {
+		Metadata struct {
+			DenomUnits []struct {
+				Denom    string `json:"denom"`
+				Exponent int    `json:"exponent"`
+			} `json:"denom_units"`
+			Base    string `json:"base"`
+			Display string `json:"display"`
+			Symbol  string `json:"symbol"`
+		} `json:"metadata"`
+	}
+	cosmosDenomTraceResponse struct {
+		DenomTrace struct {
+			Path      string `json:"path"`
+			BaseDenom string `json:"base_denom"`
+		} `json:"denom_trace"`
+	}
+)
@@ -0,0 +1,71 @@
+package tokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+const (
+	osmosisAtomDenom = "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	osmosisTiaDenom  = "ibc/D79E7D83AB399BFFF93433E54FAA480C191248FC556924A2A8351AE2638B3877"
+)
+
+func TestCosmosDiscoveryService_Discover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/balances/osmo1address":
+			w.Write([]byte(`{"balances":[
+				{"denom":"uosmo","amount":"5000000"},
+				{"denom":"` + osmosisAtomDenom + `","amount":"2000000"},
+				{"denom":"` + osmosisTiaDenom + `","amount":"3000000"}
+			],"pagination":{"next_key":null,"total":"3"}}`))
+		case "/cosmos/bank/v1beta1/denoms_metadata_by_query_string":
+			if r.URL.Query().Get("denom") == osmosisTiaDenom {
+				w.Write([]byte(`{"metadata":{"base":"` + osmosisTiaDenom + `","display":"tia","symbol":"TIA","denom_units":[
+					{"denom":"` + osmosisTiaDenom + `","exponent":0},{"denom":"tia","exponent":6}
+				]}}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		case "/ibc/apps/transfer/v1/denom_traces/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2":
+			w.Write([]byte(`{"denom_trace":{"path":"transfer/channel-0","base_denom":"uatom"}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	cmcService := &CMCService{
+		logger:     logrus.New(),
+		baseURL:    server.URL,
+		cachedData: cache.New(10*time.Hour, 1*time.Hour),
+	}
+	cmcService.cachedData.Set(cmcService.getCacheKey("Osmosis", osmosisAtomDenom), 3794, cache.DefaultExpiration)
+	cmcService.cachedData.Set(cmcService.getCacheKey("Osmosis", osmosisTiaDenom), 22861, cache.DefaultExpiration)
+	service := &cosmosDiscoveryService{
+		logger:          logrus.New(),
+		chain:           common.Osmosis,
+		restBaseAddress: server.URL,
+		nativeDenom:     "uosmo",
+		cmcService:      cmcService,
+	}
+	coins, err := service.Discover("osmo1address", common.Osmosis)
+	assert.NoError(t, err)
+	assert.Len(t, coins, 2)
+	assert.Equal(t, osmosisAtomDenom, coins[0].ContractAddress)
+	assert.Equal(t, "ATOM", coins[0].Ticker)
+	assert.Equal(t, 6, coins[0].Decimals)
+	assert.Equal(t, 3794, coins[0].CMCId)
+	assert.Equal(t, osmosisTiaDenom, coins[1].ContractAddress)
+	assert.Equal(t, "TIA", coins[1].Ticker)
+	assert.Equal(t, 6, coins[1].Decimals)
+	assert.Equal(t, 22861, coins[1].CMCId)
+}
@@ -115,6 +115,7 @@ func (e *ercDiscoveryService) Discover(address string, chain common.Chain) ([]mo
 		coins[i].Decimals = tokenDetails.Decimals
 		coins[i].Ticker = tokenDetails.Ticker
 		coins[i].ContractAddress = tokenDetails.ContractAddress
+		coins[i].Logo = tokenDetails.Logo
 		coins[i].CMCId = cmcId
 	}
 	return coins, nil
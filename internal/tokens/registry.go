@@ -0,0 +1,163 @@
+package tokens
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+//go:embed predefined_tokens.json
+var predefinedTokens string
+
+// legacyScoredTokens are the SPL and TRC-20 tokens scored before the token registry existed,
+// other Solana and Tron tokens are seeded with scoring disabled
+var legacyScoredTokens = map[common.Chain]map[string]string{
+	common.Solana: {
+		"DEf93bSt8dx58gDFCcz4CwbjYZzjwaRBYAciJYLfdCA9": "KWEEN",
+		"rndrizKT3MK1iimdxRdWabcF7Zg7AR5T4nud4EkHBof":  "RENDER",
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": "USDC",
+		"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": "USDT",
+		"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN":  "JUP",
+		"FgWto1nfArQTpg3o74sYkti753caPfHNXHG8CkedDpMg": "DORITO",
+	},
+	common.Tron: {
+		"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t": "USDT",
+	},
+}
+
+// DefaultRegistryTokens returns the tokens the registry is seeded with: the predefined tokens and the whitelisted NFT collections
+func DefaultRegistryTokens() ([]models.Token, error) {
+	var predefined []models.CoinBase
+	if err := json.Unmarshal([]byte(predefinedTokens), &predefined); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal predefined tokens: %w", err)
+	}
+	res := make([]models.Token, 0, len(predefined)+1)
+	seen := make(map[string]int)
+	for _, coin := range predefined {
+		if coin.Chain == common.Undefined {
+			return nil, fmt.Errorf("predefined token %s has an unknown chain", coin.Ticker)
+		}
+		// the registry holds one token per chain, contract and ticker, a duplicate predefined entry only replaces
+		// the seeded one when it carries the CMC id the seeded one lacks
+		key := fmt.Sprintf("%s:%s:%s", coin.Chain, strings.ToLower(coin.ContractAddress), strings.ToLower(coin.Ticker))
+		idx, dup := seen[key]
+		if dup && (res[idx].CMCId != 0 || coin.CMCId == 0) {
+			continue
+		}
+		token := models.Token{
+			Chain:           coin.Chain,
+			ContractAddress: coin.ContractAddress,
+			Ticker:          coin.Ticker,
+			Decimals:        coin.Decimals,
+			CMCId:           coin.CMCId,
+			Type:            models.TokenTypeFungible,
+			ScoringEnabled:  true,
+			Multiplier:      1,
+		}
+		if scored, ok := legacyScoredTokens[coin.Chain]; ok && coin.ContractAddress != "" {
+			ticker, ok := scored[coin.ContractAddress]
+			token.ScoringEnabled = ok
+			if token.Ticker == "" {
+				token.Ticker = ticker
+			}
+		}
+		if dup {
+			res[idx] = token
+			continue
+		}
+		seen[key] = len(res)
+		res = append(res, token)
+	}
+	res = append(res, models.Token{
+		Chain:           common.Ethereum,
+		ContractAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291",
+		Ticker:          "THORGUARDS",
+		Type:            models.TokenTypeNFT,
+		CollectionSlug:  "thorguards",
+		ScoringEnabled:  true,
+		Multiplier:      1,
+	})
+	return res, nil
+}
+
+// Registry is the in memory copy of the token registry table, shared by discovery, balance resolution and scoring
+type Registry struct {
+	mu     sync.RWMutex
+	tokens []models.Token
+}
+
+func NewRegistry(tokens []models.Token) *Registry {
+	return &Registry{tokens: tokens}
+}
+
+// Load replaces the registry tokens
+func (r *Registry) Load(tokens []models.Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = tokens
+}
+
+// Tokens returns all tokens of the registry
+func (r *Registry) Tokens() []models.Token {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.Token(nil), r.tokens...)
+}
+
+// Find returns the registry token of the given chain and contract. Coins without contract are told apart by
+// ticker, a registry token without ticker is the native coin of its chain and only matches when no other coin
+// without contract of that chain is registered, so an unregistered THORChain token never resolves to RUNE
+func (r *Registry) Find(chain common.Chain, contractAddress, ticker string) (models.Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var native *models.Token
+	ticked := false
+	for i, token := range r.tokens {
+		if !token.Matches(chain, contractAddress) {
+			continue
+		}
+		if contractAddress != "" || strings.EqualFold(token.Ticker, ticker) {
+			return token, true
+		}
+		if token.Ticker == "" {
+			native = &r.tokens[i]
+		} else {
+			ticked = true
+		}
+	}
+	if native != nil && !ticked {
+		return *native, true
+	}
+	return models.Token{}, false
+}
+
+// NFTCollections returns the NFT collections scoring is enabled for
+func (r *Registry) NFTCollections() []models.Token {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]models.Token, 0)
+	for _, token := range r.tokens {
+		if token.IsNFT() && token.ScoringEnabled {
+			res = append(res, token)
+		}
+	}
+	return res
+}
+
+// Search returns the metadata of a fungible registry token
+func (r *Registry) Search(coin models.CoinBase) (models.CoinBase, error) {
+	token, ok := r.Find(coin.Chain, coin.ContractAddress, coin.Ticker)
+	if !ok || token.IsNFT() {
+		return models.CoinBase{}, fmt.Errorf("token not found: chain=%s, ticker=%s, address=%s", coin.Chain, coin.Ticker, coin.ContractAddress)
+	}
+	return token.CoinBase(), nil
+}
+
+func (r *Registry) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	return nil, fmt.Errorf("Discover method not implemented for Registry")
+}
@@ -0,0 +1,119 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+func TestDefaultRegistryTokens(t *testing.T) {
+	defaults, err := DefaultRegistryTokens()
+	assert.NoError(t, err)
+	registry := NewRegistry(defaults)
+
+	// legacy whitelisted SPL and TRC-20 tokens keep scoring
+	token, ok := registry.Find(common.Solana, "DEf93bSt8dx58gDFCcz4CwbjYZzjwaRBYAciJYLfdCA9", "")
+	assert.True(t, ok)
+	assert.True(t, token.ScoringEnabled)
+	token, ok = registry.Find(common.Tron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "")
+	assert.True(t, ok)
+	assert.True(t, token.ScoringEnabled)
+	assert.Equal(t, 6, token.Decimals)
+
+	// evm contract addresses are case-insensitive
+	token, ok = registry.Find(common.Ethereum, "0xA98B29A8F5A247802149C268ECF860B8308B7291", "")
+	assert.True(t, ok)
+	assert.True(t, token.IsNFT())
+
+	seen := make(map[string]bool)
+	for _, token := range registry.Tokens() {
+		key := token.Chain.String() + ":" + strings.ToLower(token.ContractAddress) + ":" + strings.ToLower(token.Ticker)
+		assert.NotEqual(t, common.Undefined, token.Chain)
+		assert.False(t, seen[key], "duplicate token %s", key)
+		seen[key] = true
+		if token.Chain == common.Solana && token.ContractAddress != "" {
+			_, legacy := legacyScoredTokens[common.Solana][token.ContractAddress]
+			assert.Equal(t, legacy, token.ScoringEnabled, token.ContractAddress)
+		}
+	}
+}
+
+func TestDefaultRegistryTokensCompleteEntries(t *testing.T) {
+	defaults, err := DefaultRegistryTokens()
+	assert.NoError(t, err)
+	registry := NewRegistry(defaults)
+
+	// the duplicate noble entry carries the CMC id
+	coin, err := registry.Search(models.CoinBase{Chain: common.Noble, Ticker: "USDC"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3408, coin.CMCId)
+	assert.Equal(t, 6, coin.Decimals)
+
+	coin, err = registry.Search(models.CoinBase{Chain: common.MayaChain, Ticker: "MAYA"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, coin.Decimals)
+	coin, err = registry.Search(models.CoinBase{Chain: common.MayaChain, Ticker: "CACAO"})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, coin.Decimals)
+
+	coin, err = registry.Search(models.CoinBase{Chain: common.THORChain, Ticker: "TCY"})
+	assert.NoError(t, err)
+	assert.Equal(t, "TCY", coin.Ticker)
+	assert.Equal(t, 33770, coin.CMCId)
+	assert.Equal(t, 8, coin.Decimals)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry([]models.Token{
+		{Chain: common.Ethereum, ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Ticker: "USDT", Decimals: 6, CMCId: 825, Type: models.TokenTypeFungible, ScoringEnabled: true},
+		{Chain: common.Ethereum, ContractAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291", Ticker: "THORGUARDS", Type: models.TokenTypeNFT, CollectionSlug: "thorguards", ScoringEnabled: true},
+		{Chain: common.Base, ContractAddress: "0x0000000000000000000000000000000000000001", Type: models.TokenTypeNFT, CollectionSlug: "disabled"},
+	})
+
+	coin, err := registry.Search(models.CoinBase{Chain: common.Ethereum, ContractAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7"})
+	assert.NoError(t, err)
+	assert.Equal(t, "USDT", coin.Ticker)
+	assert.Equal(t, 825, coin.CMCId)
+	assert.False(t, coin.IsNative)
+
+	// nft collections are not coins
+	_, err = registry.Search(models.CoinBase{Chain: common.Ethereum, ContractAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291"})
+	assert.Error(t, err)
+	_, err = registry.Search(models.CoinBase{Chain: common.Arbitrum, ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7"})
+	assert.Error(t, err)
+
+	// coins without contract are told apart by ticker
+	registry.Load([]models.Token{
+		{Chain: common.THORChain, Ticker: "RUNE", Decimals: 8, CMCId: 4157, Type: models.TokenTypeFungible},
+		{Chain: common.THORChain, Ticker: "TCY", Decimals: 8, CMCId: 33770, Type: models.TokenTypeFungible},
+		{Chain: common.Bitcoin, Decimals: 8, CMCId: 1, Type: models.TokenTypeFungible},
+	})
+	coin, err = registry.Search(models.CoinBase{Chain: common.THORChain, Ticker: "rune"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4157, coin.CMCId)
+	coin, err = registry.Search(models.CoinBase{Chain: common.THORChain, Ticker: "TCY"})
+	assert.NoError(t, err)
+	assert.Equal(t, 33770, coin.CMCId)
+	_, err = registry.Search(models.CoinBase{Chain: common.THORChain, Ticker: "RUJI"})
+	assert.Error(t, err)
+	// a native coin registered without ticker matches the chain
+	coin, err = registry.Search(models.CoinBase{Chain: common.Bitcoin, Ticker: "BTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, coin.CMCId)
+
+	registry.Load([]models.Token{
+		{Chain: common.Ethereum, ContractAddress: "0xa98b29a8f5a247802149c268ecf860b8308b7291", Ticker: "THORGUARDS", Type: models.TokenTypeNFT, CollectionSlug: "thorguards", ScoringEnabled: true},
+		{Chain: common.Base, ContractAddress: "0x0000000000000000000000000000000000000001", Type: models.TokenTypeNFT, CollectionSlug: "disabled"},
+	})
+	collections := registry.NFTCollections()
+	assert.Len(t, collections, 1)
+	assert.Equal(t, "thorguards", collections[0].CollectionSlug)
+
+	registry.Load(nil)
+	assert.Empty(t, registry.Tokens())
+	assert.Empty(t, registry.NFTCollections())
+}
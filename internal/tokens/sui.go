@@ -0,0 +1,122 @@
+package tokens
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+	"github.com/vultisig/airdrop-registry/internal/utils"
+)
+
+// suiCoinType is the coin type of the native SUI coin
+const suiCoinType = "0x2::sui::SUI"
+
+type suiDiscoveryService struct {
+	logger      *logrus.Logger
+	baseAddress string
+	cmcService  *CMCService
+}
+
+func NewSuiDiscoveryService(cmcService *CMCService) AutoDiscoveryService {
+	return &suiDiscoveryService{
+		logger:      logrus.WithField("module", "sui_discovery_service").Logger,
+		baseAddress: "https://sui-rpc.publicnode.com",
+		cmcService:  cmcService,
+	}
+}
+
+// Discover returns the non SUI coins held by address, the contract address is the coin type
+func (s *suiDiscoveryService) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	if address == "" {
+		return nil, fmt.Errorf("empty address provided")
+	}
+	if chain != common.Sui {
+		return nil, fmt.Errorf("chain does not support")
+	}
+	var balances []struct {
+		CoinType     string `json:"coinType"`
+		TotalBalance string `json:"totalBalance"`
+	}
+	if err := s.call("suix_getAllBalances", []any{address}, &balances); err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	coins := make([]models.CoinBase, 0)
+	for _, balance := range balances {
+		if balance.CoinType == suiCoinType {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(balance.TotalBalance, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		coin, err := s.Search(models.CoinBase{
+			Address:         address,
+			Balance:         balance.TotalBalance,
+			Chain:           common.Sui,
+			ContractAddress: balance.CoinType,
+		})
+		if err != nil {
+			s.logger.WithError(err).WithField("contract", balance.CoinType).Debug("failed to search coin")
+			continue
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+func (s *suiDiscoveryService) Search(coin models.CoinBase) (models.CoinBase, error) {
+	cmcId, err := s.cmcService.GetCMCIDByContract(cmcChainMap[common.Sui], coin.ContractAddress)
+	if err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to fetch cmc id: %w", err)
+	}
+	var metadata *struct {
+		Decimals int    `json:"decimals"`
+		Symbol   string `json:"symbol"`
+		IconUrl  string `json:"iconUrl"`
+	}
+	if err := s.call("suix_getCoinMetadata", []any{coin.ContractAddress}, &metadata); err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to get coin metadata: %w", err)
+	}
+	if metadata == nil {
+		return models.CoinBase{}, fmt.Errorf("coin metadata of %s not found", coin.ContractAddress)
+	}
+	coin.CMCId = cmcId
+	coin.Ticker = metadata.Symbol
+	coin.Decimals = metadata.Decimals
+	coin.Logo = metadata.IconUrl
+	return coin, nil
+}
+
+func (s *suiDiscoveryService) call(method string, params []any, result any) error {
+	buf, err := json.Marshal(utils.NewJsonRPCRequest(method, params, 1))
+	if err != nil {
+		return fmt.Errorf("error marshalling RPC request: %w", err)
+	}
+	resp, err := http.Post(s.baseAddress, "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+	rpcResp := struct {
+		Result any `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{Result: result}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("rpc error: %s", rpcResp.Error.Message)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package tokens
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+const suiUSDCCoinType = "0xdba34672e30cb065b1f93e3ab55318768fd6fef66c15942c9f7cb846e2f900e7::usdc::USDC"
+
+func TestSuiDiscoveryService_Discover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			// CMC doesn't list the token
+			w.Write([]byte(`{"data":{}}`))
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusOK)
+		switch req.Method {
+		case "suix_getAllBalances":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[
+				{"coinType":"0x2::sui::SUI","coinObjectCount":1,"totalBalance":"1000000000"},
+				{"coinType":"` + suiUSDCCoinType + `","coinObjectCount":1,"totalBalance":"2500000"},
+				{"coinType":"0xabc::scam::SCAM","coinObjectCount":1,"totalBalance":"100"}
+			]}`))
+		case "suix_getCoinMetadata":
+			assert.Equal(t, suiUSDCCoinType, req.Params[0])
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"decimals":6,"name":"USDC","symbol":"USDC","iconUrl":"https://circle.com/usdc-icon"}}`))
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+	}))
+	defer server.Close()
+	cmcService := &CMCService{
+		logger:     logrus.New(),
+		baseURL:    server.URL,
+		cachedData: cache.New(10*time.Hour, 1*time.Hour),
+	}
+	cmcService.cachedData.Set(cmcService.getCacheKey("Sui", suiUSDCCoinType), 3408, cache.DefaultExpiration)
+	service := &suiDiscoveryService{
+		logger:      logrus.New(),
+		baseAddress: server.URL,
+		cmcService:  cmcService,
+	}
+	coins, err := service.Discover("0x61953ea72709eed72f4441dd944eec49a11b4acabfc8e04015e89c63be81b6ab", common.Sui)
+	assert.NoError(t, err)
+	assert.Len(t, coins, 1)
+	assert.Equal(t, suiUSDCCoinType, coins[0].ContractAddress)
+	assert.Equal(t, "USDC", coins[0].Ticker)
+	assert.Equal(t, 6, coins[0].Decimals)
+	assert.Equal(t, 3408, coins[0].CMCId)
+	assert.Equal(t, "https://circle.com/usdc-icon", coins[0].Logo)
+	assert.Equal(t, "2500000", coins[0].Balance)
+}
@@ -0,0 +1,170 @@
+package tokens
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// defaultJettonDecimals is used when the jetton metadata doesn't define decimals (TEP-64 default)
+const defaultJettonDecimals = 9
+
+type tonDiscoveryService struct {
+	logger      *logrus.Logger
+	baseAddress string
+	cmcService  *CMCService
+}
+
+func NewTonDiscoveryService(cmcService *CMCService) AutoDiscoveryService {
+	return &tonDiscoveryService{
+		logger:      logrus.WithField("module", "ton_discovery_service").Logger,
+		baseAddress: "https://api.vultisig.com/ton/v3",
+		cmcService:  cmcService,
+	}
+}
+
+// Discover returns the jettons held by address, the contract address is the user friendly jetton master address
+func (t *tonDiscoveryService) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	if address == "" {
+		return nil, fmt.Errorf("empty address provided")
+	}
+	if chain != common.Ton {
+		return nil, fmt.Errorf("chain does not support")
+	}
+	params := url.Values{}
+	params.Set("owner_address", address)
+	params.Set("limit", "100")
+	var walletsResp tonJettonWalletsResponse
+	if err := t.get("jetton/wallets", params, &walletsResp); err != nil {
+		return nil, fmt.Errorf("failed to get jetton wallets: %w", err)
+	}
+	coins := make([]models.CoinBase, 0)
+	for _, wallet := range walletsResp.JettonWallets {
+		balance, ok := new(big.Int).SetString(wallet.Balance, 10)
+		if !ok || balance.Sign() <= 0 {
+			continue
+		}
+		master := walletsResp.AddressBook[wallet.Jetton].UserFriendly
+		if master == "" {
+			t.logger.WithField("jetton", wallet.Jetton).Warn("jetton master not found in address book")
+			continue
+		}
+		cmcId, err := t.cmcService.GetCMCIDByContract(cmcChainMap[common.Ton], master)
+		if err != nil {
+			t.logger.WithError(err).WithField("contract", master).Debug("failed to get CMCID for contract")
+			continue
+		}
+		coin := models.CoinBase{
+			Address:         address,
+			Balance:         wallet.Balance,
+			Chain:           common.Ton,
+			ContractAddress: master,
+			CMCId:           cmcId,
+			Decimals:        defaultJettonDecimals,
+		}
+		if metadata, ok := walletsResp.Metadata[wallet.Jetton]; ok {
+			metadata.apply(&coin)
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+func (t *tonDiscoveryService) Search(coin models.CoinBase) (models.CoinBase, error) {
+	cmcId, err := t.cmcService.GetCMCIDByContract(cmcChainMap[common.Ton], coin.ContractAddress)
+	if err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to fetch cmc id: %w", err)
+	}
+	params := url.Values{}
+	params.Set("address", coin.ContractAddress)
+	params.Set("limit", "1")
+	var mastersResp tonJettonMastersResponse
+	if err := t.get("jetton/masters", params, &mastersResp); err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to get jetton master: %w", err)
+	}
+	if len(mastersResp.JettonMasters) == 0 {
+		return models.CoinBase{}, fmt.Errorf("jetton master %s not found", coin.ContractAddress)
+	}
+	content := mastersResp.JettonMasters[0].JettonContent
+	coin.CMCId = cmcId
+	coin.Ticker = content.Symbol
+	coin.Logo = content.Image
+	coin.Decimals = defaultJettonDecimals
+	if decimals, err := strconv.Atoi(content.Decimals); err == nil {
+		coin.Decimals = decimals
+	}
+	return coin, nil
+}
+
+func (t *tonDiscoveryService) get(path string, params url.Values, result any) error {
+	resp, err := http.Get(fmt.Sprintf("%s/%s?%s", t.baseAddress, path, params.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+type (
+	tonJettonWalletsResponse struct {
+		JettonWallets []struct {
+			Address string `json:"address"`
+			Balance string `json:"balance"`
+			Owner   string `json:"owner"`
+			Jetton  string `json:"jetton"`
+		} `json:"jetton_wallets"`
+		AddressBook map[string]struct {
+			UserFriendly string `json:"user_friendly"`
+		} `json:"address_book"`
+		Metadata map[string]tonMetadata `json:"metadata"`
+	}
+	tonMetadata struct {
+		TokenInfo []struct {
+			Type   string `json:"type"`
+			Symbol string `json:"symbol"`
+			Image  string `json:"image"`
+			Extra  struct {
+				Decimals string `json:"decimals"`
+			} `json:"extra"`
+		} `json:"token_info"`
+	}
+	tonJettonMastersResponse struct {
+		JettonMasters []struct {
+			Address       string `json:"address"`
+			JettonContent struct {
+				Symbol   string `json:"symbol"`
+				Image    string `json:"image"`
+				Decimals string `json:"decimals"`
+			} `json:"jetton_content"`
+		} `json:"jetton_masters"`
+	}
+)
+
+// apply sets the jetton master metadata on coin
+func (m tonMetadata) apply(coin *models.CoinBase) {
+	for _, info := range m.TokenInfo {
+		if info.Type != "jetton_masters" {
+			continue
+		}
+		coin.Ticker = info.Symbol
+		coin.Logo = info.Image
+		if decimals, err := strconv.Atoi(info.Extra.Decimals); err == nil {
+			coin.Decimals = decimals
+		}
+		return
+	}
+}
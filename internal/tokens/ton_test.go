@@ -0,0 +1,70 @@
+package tokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+const tonJettonWalletsMock = `{
+  "jetton_wallets": [
+    {"address": "0:AAA", "balance": "1500000", "owner": "0:BBB", "jetton": "0:B113A994B5024A16719F69139328EB759596C38A25F59028B146FECDC3621DFE"},
+    {"address": "0:CCC", "balance": "0", "owner": "0:BBB", "jetton": "0:DDD"}
+  ],
+  "address_book": {
+    "0:B113A994B5024A16719F69139328EB759596C38A25F59028B146FECDC3621DFE": {"user_friendly": "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"},
+    "0:DDD": {"user_friendly": "EQDDD"}
+  },
+  "metadata": {
+    "0:B113A994B5024A16719F69139328EB759596C38A25F59028B146FECDC3621DFE": {
+      "is_indexed": true,
+      "token_info": [{"type": "jetton_masters", "name": "Tether USD", "symbol": "USD₮", "image": "https://tether.to/images/logoCircle.png", "extra": {"decimals": "6"}}]
+    }
+  }
+}`
+
+func TestTonDiscoveryService_Discover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/jetton/wallets", r.URL.Path)
+		assert.Equal(t, "UQBM2SHV1AuhDNMB4E69SMtzqstKG2J_ZXwqpdgmAuulrUom", r.URL.Query().Get("owner_address"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(tonJettonWalletsMock))
+	}))
+	defer server.Close()
+	cmcService := &CMCService{
+		logger:     logrus.New(),
+		baseURL:    server.URL,
+		cachedData: cache.New(10*time.Hour, 1*time.Hour),
+	}
+	cmcService.cachedData.Set(cmcService.getCacheKey("Toncoin", "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"), 825, cache.DefaultExpiration)
+	service := &tonDiscoveryService{
+		logger:      logrus.New(),
+		baseAddress: server.URL,
+		cmcService:  cmcService,
+	}
+	coins, err := service.Discover("UQBM2SHV1AuhDNMB4E69SMtzqstKG2J_ZXwqpdgmAuulrUom", common.Ton)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CoinBase{
+		{
+			Chain:           common.Ton,
+			Ticker:          "USD₮",
+			Address:         "UQBM2SHV1AuhDNMB4E69SMtzqstKG2J_ZXwqpdgmAuulrUom",
+			ContractAddress: "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs",
+			Decimals:        6,
+			CMCId:           825,
+			Logo:            "https://tether.to/images/logoCircle.png",
+			Balance:         "1500000",
+		},
+	}, coins)
+
+	_, err = service.Discover("UQBM2SHV1AuhDNMB4E69SMtzqstKG2J_ZXwqpdgmAuulrUom", common.Sui)
+	assert.Error(t, err)
+}
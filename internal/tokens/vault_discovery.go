@@ -0,0 +1,219 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// NewDiscoveryServices returns the auto discovery service of every chain token discovery is supported on.
+// An EVM chain whose 1inch token list fails to load is logged and left without discovery.
+func NewDiscoveryServices(cmcService *CMCService, oneInchService *oneInchService) (map[common.Chain]AutoDiscoveryService, error) {
+	logger := logrus.WithField("module", "vault_discovery_service").Logger
+	discoveryServices := map[common.Chain]AutoDiscoveryService{
+		common.Tron:   NewTRC20DiscoveryService(common.Tron, cmcService),
+		common.Solana: NewSPLDiscoveryService(cmcService),
+		common.Ton:    NewTonDiscoveryService(cmcService),
+		common.Sui:    NewSuiDiscoveryService(cmcService),
+		common.XRP:    NewXRPDiscoveryService(cmcService),
+	}
+	for chain := range cosmosChains {
+		discoveryService, err := NewCosmosDiscoveryService(chain, cmcService)
+		if err != nil {
+			return nil, err
+		}
+		discoveryServices[chain] = discoveryService
+	}
+	for _, chain := range common.EVMChains {
+		if !oneInchService.IsChainSupported(chain) {
+			continue
+		}
+		if err := oneInchService.LoadOneInchTokens(chain); err != nil {
+			logger.WithError(err).WithField("chain", chain).Error("failed to load oneInch tokens, skipping token discovery")
+			continue
+		}
+		discoveryServices[chain] = NewERC20DiscoveryService(oneInchService, cmcService)
+	}
+	return discoveryServices, nil
+}
+
+// VaultDiscoveryService discovers the tokens held by a vault on all chains with a discovery service
+type VaultDiscoveryService struct {
+	logger            *logrus.Logger
+	discoveryServices map[common.Chain]AutoDiscoveryService
+	registry          *Registry
+	notReady          bool // the discovery services are still being built, only the registry verifies coins
+}
+
+func NewVaultDiscoveryService(discoveryServices map[common.Chain]AutoDiscoveryService, registry *Registry) *VaultDiscoveryService {
+	return &VaultDiscoveryService{
+		logger:            logrus.WithField("module", "vault_discovery_service").Logger,
+		discoveryServices: discoveryServices,
+		registry:          registry,
+	}
+}
+
+// NewRegistryVaultDiscoveryService returns a vault discovery service without discovery services, used while they are
+// being built. It verifies coins with the token registry and returns ErrDiscoveryNotReady for unknown tokens.
+func NewRegistryVaultDiscoveryService(registry *Registry) *VaultDiscoveryService {
+	v := NewVaultDiscoveryService(nil, registry)
+	v.notReady = true
+	return v
+}
+
+// Chains returns the chains tokens can be discovered on, sorted by name
+func (v *VaultDiscoveryService) Chains() []common.Chain {
+	chains := make([]common.Chain, 0, len(v.discoveryServices))
+	for chain := range v.discoveryServices {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].String() < chains[j].String()
+	})
+	return chains
+}
+
+// Discover runs the discovery service of every supported chain against the address getAddress derives for it.
+// A failing chain is logged and skipped, so one unavailable provider doesn't hide the tokens found on other chains.
+func (v *VaultDiscoveryService) Discover(getAddress func(chain common.Chain) (string, error)) []models.CoinBase {
+	res := make([]models.CoinBase, 0)
+	for _, chain := range v.Chains() {
+		address, err := getAddress(chain)
+		if err != nil {
+			v.logger.WithError(err).WithField("chain", chain).Warn("failed to get address")
+			continue
+		}
+		coins, err := v.DiscoverAddress(chain, address)
+		if err != nil {
+			v.logger.WithError(err).WithField("chain", chain).Warn("failed to discover tokens")
+			continue
+		}
+		res = append(res, coins...)
+	}
+	return res
+}
+
+// DiscoverAddress returns the tokens held by address on chain.
+// Known tokens take their CMC id and decimals from the token registry.
+func (v *VaultDiscoveryService) DiscoverAddress(chain common.Chain, address string) ([]models.CoinBase, error) {
+	discoveryService, ok := v.discoveryServices[chain]
+	if !ok {
+		return nil, fmt.Errorf("token discovery is not supported on %s", chain)
+	}
+	coins, err := discoveryService.Discover(address, chain)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]models.CoinBase, 0, len(coins))
+	for _, coin := range coins {
+		if coin.ContractAddress == "" {
+			continue
+		}
+		if v.registry != nil {
+			if known, err := v.registry.Search(coin); err == nil {
+				coin.CMCId = known.CMCId
+				coin.Decimals = known.Decimals
+			}
+		}
+		if coin.Ticker == "" || coin.Decimals == 0 {
+			// fill the missing metadata (e.g. SPL discovery only returns the mint)
+			found, err := discoveryService.Search(coin)
+			if err != nil {
+				v.logger.WithError(err).WithField("contract", coin.ContractAddress).Warn("failed to search token")
+			} else {
+				coin = mergeCoinMetadata(coin, found)
+			}
+		}
+		coin.Chain = chain
+		coin.Address = address
+		res = append(res, coin)
+	}
+	return res, nil
+}
+
+// ErrUnknownToken is returned by VerifyCoin for tokens neither the token registry nor the discovery service of their chain knows
+var ErrUnknownToken = errors.New("unknown token")
+
+// ErrDiscoveryNotReady is returned by VerifyCoin for tokens missing from the token registry while the discovery
+// services are being built
+var ErrDiscoveryNotReady = errors.New("token discovery is not ready")
+
+// VerifyCoin overwrites the client supplied decimals, CMC id, logo and price provider of coin with the token registry,
+// falling back to the discovery service of its chain. Coins which can't be verified (native coins missing from the
+// registry, tokens identified by ticker e.g. THORChain TCY and tokens of chains without discovery service) are kept
+// without CMC id and price provider, so the client can't choose how they are priced.
+func (v *VaultDiscoveryService) VerifyCoin(coin models.CoinBase) (models.CoinBase, error) {
+	coin.IsNative = coin.ContractAddress == ""
+	if v.registry != nil {
+		if known, err := v.registry.Search(coin); err == nil {
+			return overwriteCoinMetadata(coin, known), nil
+		}
+	}
+	discoveryService, ok := v.discoveryServices[coin.Chain]
+	if !coin.IsNative && v.notReady {
+		return coin, fmt.Errorf("%w: %s on %s", ErrDiscoveryNotReady, coin.ContractAddress, coin.Chain)
+	}
+	if coin.IsNative || !ok {
+		coin.CMCId = 0
+		coin.PriceProviderID = ""
+		return coin, nil
+	}
+	known, err := discoveryService.Search(coin)
+	if err != nil {
+		return coin, fmt.Errorf("%w: %s on %s: %v", ErrUnknownToken, coin.ContractAddress, coin.Chain, err)
+	}
+	return overwriteCoinMetadata(coin, known), nil
+}
+
+// overwriteCoinMetadata replaces the metadata of coin with the one of known, empty ticker and logo are kept from coin
+func overwriteCoinMetadata(coin, known models.CoinBase) models.CoinBase {
+	if known.Ticker != "" {
+		coin.Ticker = known.Ticker
+	}
+	if known.Decimals > 0 {
+		coin.Decimals = known.Decimals
+	}
+	if known.Logo != "" {
+		coin.Logo = known.Logo
+	}
+	coin.CMCId = known.CMCId
+	coin.PriceProviderID = known.PriceProviderID
+	coin.IsNative = coin.ContractAddress == ""
+	return coin
+}
+
+// IsCompleteToken reports whether a discovered token has the metadata needed to price it
+func IsCompleteToken(coin models.CoinBase) bool {
+	return coin.Ticker != "" && coin.Decimals > 0 && coin.CMCId > 0
+}
+
+// mergeCoinMetadata fills the empty metadata fields of coin from found
+func mergeCoinMetadata(coin, found models.CoinBase) models.CoinBase {
+	if coin.Ticker == "" {
+		coin.Ticker = found.Ticker
+	}
+	if coin.Decimals == 0 {
+		coin.Decimals = found.Decimals
+	}
+	if coin.CMCId == 0 {
+		coin.CMCId = found.CMCId
+	}
+	if coin.Logo == "" {
+		coin.Logo = found.Logo
+	}
+	if coin.PriceProviderID == "" {
+		coin.PriceProviderID = found.PriceProviderID
+	}
+	return coin
+}
+
+// IsSameToken reports whether a and b are the same token, EVM contract addresses are case-insensitive
+func IsSameToken(a, b models.CoinBase) bool {
+	token := models.Token{Chain: a.Chain, ContractAddress: a.ContractAddress}
+	return token.Matches(b.Chain, b.ContractAddress)
+}
@@ -0,0 +1,172 @@
+package tokens
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+type mockDiscoveryService struct {
+	coins       []models.CoinBase
+	searched    models.CoinBase
+	discoverErr error
+	searchErr   error
+}
+
+func (m *mockDiscoveryService) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	if m.discoverErr != nil {
+		return nil, m.discoverErr
+	}
+	return m.coins, nil
+}
+
+func (m *mockDiscoveryService) Search(coin models.CoinBase) (models.CoinBase, error) {
+	if m.searchErr != nil {
+		return models.CoinBase{}, m.searchErr
+	}
+	m.searched.ContractAddress = coin.ContractAddress
+	return m.searched, nil
+}
+
+func TestVaultDiscoveryService_Discover(t *testing.T) {
+	services := map[common.Chain]AutoDiscoveryService{
+		common.Ethereum: &mockDiscoveryService{
+			coins: []models.CoinBase{
+				{ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Ticker: "USDT", Decimals: 6, CMCId: 825, Logo: "usdt.png"},
+			},
+		},
+		common.Solana: &mockDiscoveryService{
+			coins: []models.CoinBase{
+				{ContractAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", CMCId: 3408},
+			},
+			searched: models.CoinBase{Ticker: "USDC", Decimals: 6, CMCId: 3408},
+		},
+		common.Tron: &mockDiscoveryService{discoverErr: fmt.Errorf("tron unavailable")},
+	}
+	service := NewVaultDiscoveryService(services, nil)
+	coins := service.Discover(func(chain common.Chain) (string, error) {
+		return "addr_" + chain.String(), nil
+	})
+	assert.Len(t, coins, 2)
+	assert.Equal(t, common.Ethereum, coins[0].Chain)
+	assert.Equal(t, "addr_Ethereum", coins[0].Address)
+	assert.Equal(t, "usdt.png", coins[0].Logo)
+	assert.Equal(t, common.Solana, coins[1].Chain)
+	assert.Equal(t, "USDC", coins[1].Ticker)
+	assert.Equal(t, 6, coins[1].Decimals)
+	assert.Equal(t, "addr_Solana", coins[1].Address)
+
+	_, err := service.DiscoverAddress(common.Bitcoin, "addr_Bitcoin")
+	assert.Error(t, err)
+	assert.True(t, IsCompleteToken(coins[1]))
+	assert.False(t, IsCompleteToken(models.CoinBase{Ticker: "FOO", Decimals: 6}))
+}
+
+func TestIsSameToken(t *testing.T) {
+	a := models.CoinBase{Chain: common.Ethereum, ContractAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7"}
+	b := models.CoinBase{Chain: common.Ethereum, ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7"}
+	assert.True(t, IsSameToken(a, b))
+	b.Chain = common.Base
+	assert.False(t, IsSameToken(a, b))
+	c := models.CoinBase{Chain: common.Solana, ContractAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"}
+	d := models.CoinBase{Chain: common.Solana, ContractAddress: "epjfwdd5aufqssqem2qn1xzybapc8g4wegGkzwytdt1v"}
+	assert.False(t, IsSameToken(c, d))
+}
+
+func TestVaultDiscoveryService_VerifyCoin(t *testing.T) {
+	registry := NewRegistry([]models.Token{
+		{Chain: common.Ethereum, ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Ticker: "USDT", Decimals: 6, CMCId: 825, Type: models.TokenTypeFungible},
+		{Chain: common.Ethereum, Decimals: 18, CMCId: 1027, Type: models.TokenTypeFungible},
+	})
+	services := map[common.Chain]AutoDiscoveryService{
+		common.Ethereum: &mockDiscoveryService{searchErr: fmt.Errorf("token not found")},
+		common.Solana: &mockDiscoveryService{
+			searched: models.CoinBase{Ticker: "USDC", Decimals: 6, CMCId: 3408, Logo: "usdc.png"},
+		},
+	}
+	service := NewVaultDiscoveryService(services, registry)
+
+	// registry metadata wins over the client one
+	coin, err := service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "USDT", ContractAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Decimals: 0, CMCId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, coin.Decimals)
+	assert.Equal(t, 825, coin.CMCId)
+	assert.False(t, coin.IsNative)
+
+	coin, err = service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "ETH", IsNative: true, Decimals: 8, CMCId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH", coin.Ticker)
+	assert.Equal(t, 18, coin.Decimals)
+	assert.Equal(t, 1027, coin.CMCId)
+
+	// unregistered tokens are verified by the discovery service of their chain
+	coin, err = service.VerifyCoin(models.CoinBase{Chain: common.Solana, Ticker: "BTC", ContractAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Decimals: 8, CMCId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "USDC", coin.Ticker)
+	assert.Equal(t, 6, coin.Decimals)
+	assert.Equal(t, 3408, coin.CMCId)
+	assert.Equal(t, "usdc.png", coin.Logo)
+
+	_, err = service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "JUNK", ContractAddress: "0x0000000000000000000000000000000000000001", Decimals: 18, CMCId: 1})
+	assert.ErrorIs(t, err, ErrUnknownToken)
+
+	// coins which can't be verified are kept without the client CMC id and price provider
+	unverifiable := models.CoinBase{Chain: common.THORChain, Ticker: "TCY", ContractAddress: "tcy", Decimals: 8, CMCId: 33770, PriceProviderID: "tcy"}
+	coin, err = service.VerifyCoin(unverifiable)
+	assert.NoError(t, err)
+	assert.Equal(t, "TCY", coin.Ticker)
+	assert.Equal(t, 8, coin.Decimals)
+	assert.Equal(t, 0, coin.CMCId)
+	assert.Empty(t, coin.PriceProviderID)
+	assert.False(t, coin.IsNative)
+
+	coin, err = service.VerifyCoin(models.CoinBase{Chain: common.Solana, Ticker: "SOL", IsNative: true, Decimals: 9, CMCId: 5426})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, coin.CMCId)
+	assert.True(t, coin.IsNative)
+}
+
+func TestVaultDiscoveryService_VerifyCoinNativeBypass(t *testing.T) {
+	registry := NewRegistry([]models.Token{
+		{Chain: common.Ethereum, Decimals: 18, CMCId: 1027, Type: models.TokenTypeFungible},
+	})
+	service := NewVaultDiscoveryService(map[common.Chain]AutoDiscoveryService{}, registry)
+
+	// a coin without contract address is native even when the client says it isn't
+	coin, err := service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "ETH", IsNative: false, Decimals: 2, CMCId: 1})
+	assert.NoError(t, err)
+	assert.True(t, coin.IsNative)
+	assert.Equal(t, 18, coin.Decimals)
+	assert.Equal(t, 1027, coin.CMCId)
+
+	coin, err = service.VerifyCoin(models.CoinBase{Chain: common.Bitcoin, Ticker: "BTC", IsNative: false, Decimals: 2, CMCId: 1027})
+	assert.NoError(t, err)
+	assert.True(t, coin.IsNative)
+	assert.Equal(t, 0, coin.CMCId)
+}
+
+func TestRegistryVaultDiscoveryService_VerifyCoin(t *testing.T) {
+	registry := NewRegistry([]models.Token{
+		{Chain: common.Ethereum, ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Ticker: "USDT", Decimals: 6, CMCId: 825, Type: models.TokenTypeFungible},
+	})
+	service := NewRegistryVaultDiscoveryService(registry)
+
+	// registry tokens and native coins are verified while the discovery services are being built
+	coin, err := service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "USDT", ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", CMCId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 825, coin.CMCId)
+
+	coin, err = service.VerifyCoin(models.CoinBase{Chain: common.Bitcoin, Ticker: "BTC", CMCId: 1, PriceProviderID: "bitcoin"})
+	assert.NoError(t, err)
+	assert.True(t, coin.IsNative)
+	assert.Zero(t, coin.CMCId)
+	assert.Empty(t, coin.PriceProviderID)
+
+	// other tokens wait for the discovery services
+	_, err = service.VerifyCoin(models.CoinBase{Chain: common.Ethereum, Ticker: "PEPE", ContractAddress: "0x6982508145454ce325ddbe47a25d4ec3d2311933"})
+	assert.ErrorIs(t, err, ErrDiscoveryNotReady)
+}
@@ -0,0 +1,158 @@
+package tokens
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+// xrplTokenDecimals is the precision issued currencies are stored with, XRPL amounts have 15 significant digits
+const xrplTokenDecimals = 15
+
+type xrpDiscoveryService struct {
+	logger      *logrus.Logger
+	baseAddress string
+	cmcService  *CMCService
+}
+
+func NewXRPDiscoveryService(cmcService *CMCService) AutoDiscoveryService {
+	return &xrpDiscoveryService{
+		logger:      logrus.WithField("module", "xrp_discovery_service").Logger,
+		baseAddress: "https://xrplcluster.com",
+		cmcService:  cmcService,
+	}
+}
+
+// Discover returns the issued currencies address holds through its trust lines,
+// the contract address is the currency code and the issuer joined by a dot (e.g. 534F4C4F00000000000000000000000000000000.rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz)
+func (x *xrpDiscoveryService) Discover(address string, chain common.Chain) ([]models.CoinBase, error) {
+	if address == "" {
+		return nil, fmt.Errorf("empty address provided")
+	}
+	if chain != common.XRP {
+		return nil, fmt.Errorf("chain does not support")
+	}
+	lines, err := x.getAccountLines(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account lines: %w", err)
+	}
+	coins := make([]models.CoinBase, 0)
+	for _, line := range lines {
+		balance, err := strconv.ParseFloat(line.Balance, 64)
+		if err != nil || balance <= 0 {
+			continue
+		}
+		coin, err := x.Search(models.CoinBase{
+			Address:         address,
+			Balance:         line.Balance,
+			Chain:           common.XRP,
+			ContractAddress: fmt.Sprintf("%s.%s", line.Currency, line.Account),
+		})
+		if err != nil {
+			x.logger.WithError(err).WithField("contract", line.Currency).Debug("failed to search token")
+			continue
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+func (x *xrpDiscoveryService) Search(coin models.CoinBase) (models.CoinBase, error) {
+	currency, _, ok := strings.Cut(coin.ContractAddress, ".")
+	if !ok {
+		return models.CoinBase{}, fmt.Errorf("invalid contract address: %s", coin.ContractAddress)
+	}
+	cmcId, err := x.cmcService.GetCMCIDByContract(cmcChainMap[common.XRP], coin.ContractAddress)
+	if err != nil {
+		return models.CoinBase{}, fmt.Errorf("failed to fetch cmc id: %w", err)
+	}
+	coin.CMCId = cmcId
+	coin.Ticker = xrplCurrencyTicker(currency)
+	coin.Decimals = xrplTokenDecimals
+	return coin, nil
+}
+
+func (x *xrpDiscoveryService) getAccountLines(address string) ([]xrpAccountLine, error) {
+	lines := make([]xrpAccountLine, 0)
+	var marker any
+	for {
+		params := map[string]any{
+			"account":      address,
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		reqBody, err := json.Marshal(map[string]any{
+			"method": "account_lines",
+			"params": []any{params},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling RPC request: %w", err)
+		}
+		resp, err := http.Post(x.baseAddress, "application/json", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		var result xrpAccountLinesResponse
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+			}
+			return json.NewDecoder(resp.Body).Decode(&result)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if result.Result.Status == "error" {
+			// unfunded accounts have no trust lines
+			if result.Result.Error == "actNotFound" {
+				return lines, nil
+			}
+			return nil, fmt.Errorf("account_lines error: %s", result.Result.Error)
+		}
+		lines = append(lines, result.Result.Lines...)
+		if result.Result.Marker == nil {
+			return lines, nil
+		}
+		marker = result.Result.Marker
+	}
+}
+
+// xrplCurrencyTicker returns the ticker of an XRPL currency code, 40 characters hex codes are decoded to ASCII
+func xrplCurrencyTicker(currency string) string {
+	if len(currency) != 40 {
+		return currency
+	}
+	decoded, err := hex.DecodeString(currency)
+	if err != nil {
+		return currency
+	}
+	return string(bytes.Trim(decoded, "\x00"))
+}
+
+type (
+	xrpAccountLine struct {
+		Account  string `json:"account"`
+		Balance  string `json:"balance"`
+		Currency string `json:"currency"`
+	}
+	xrpAccountLinesResponse struct {
+		Result struct {
+			Lines  []xrpAccountLine `json:"lines"`
+			Marker any              `json:"marker"`
+			Status string           `json:"status"`
+			Error  string           `json:"error"`
+		} `json:"result"`
+	}
+)
@@ -0,0 +1,64 @@
+package tokens
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+)
+
+func TestXRPDiscoveryService_Discover(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string           `json:"method"`
+			Params []map[string]any `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "account_lines", req.Method)
+		requests++
+		w.WriteHeader(http.StatusOK)
+		if req.Params[0]["marker"] == nil {
+			w.Write([]byte(`{"result":{"account":"rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX","lines":[
+				{"account":"rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz","balance":"120.5","currency":"534F4C4F00000000000000000000000000000000","limit":"1000000000"}
+			],"marker":"page2","status":"success"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":{"account":"rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX","lines":[
+			{"account":"rMxCKbEDwqr76QuheSUMdEGf4B9xJ8m5De","balance":"0","currency":"524C555344000000000000000000000000000000","limit":"1000000000"}
+		],"status":"success"}}`))
+	}))
+	defer server.Close()
+	cmcService := &CMCService{
+		logger:     logrus.New(),
+		baseURL:    server.URL,
+		cachedData: cache.New(10*time.Hour, 1*time.Hour),
+	}
+	cmcService.cachedData.Set(cmcService.getCacheKey("XRP", "534F4C4F00000000000000000000000000000000.rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz"), 5279, cache.DefaultExpiration)
+	service := &xrpDiscoveryService{
+		logger:      logrus.New(),
+		baseAddress: server.URL,
+		cmcService:  cmcService,
+	}
+	coins, err := service.Discover("rhmezeHcxx9sv3A69eafEcAeX3EWBmwFGX", common.XRP)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, coins, 1)
+	assert.Equal(t, "534F4C4F00000000000000000000000000000000.rsoLo2S1kiGeCcn6hCUXVrCpGMWLrRrLZz", coins[0].ContractAddress)
+	assert.Equal(t, "SOLO", coins[0].Ticker)
+	assert.Equal(t, 5279, coins[0].CMCId)
+	assert.Equal(t, "120.5", coins[0].Balance)
+}
+
+func TestXrplCurrencyTicker(t *testing.T) {
+	assert.Equal(t, "USD", xrplCurrencyTicker("USD"))
+	assert.Equal(t, "SOLO", xrplCurrencyTicker("534F4C4F00000000000000000000000000000000"))
+	assert.Equal(t, "RLUSD", xrplCurrencyTicker("524C555344000000000000000000000000000000"))
+}
@@ -0,0 +1,312 @@
+package volume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+const (
+	// wrappedSolMint is priced as native SOL
+	wrappedSolMint = "So11111111111111111111111111111111111111112"
+	// tokenProgramID owns the referral token accounts the fees are paid to
+	tokenProgramID = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	// signaturesPageSize is the max number of signatures getSignaturesForAddress returns
+	signaturesPageSize = 1000
+)
+
+// jupiterVolumeTracker scans the fee transactions of a Jupiter referral account and credits
+// the USD size of each swap to the transaction signer
+type jupiterVolumeTracker struct {
+	rpcUrl       string
+	priceLookup  PriceLookup
+	requestDelay time.Duration
+	logger       *logrus.Logger
+}
+
+func NewJupiterVolumeTracker(rpcUrl string, priceLookup PriceLookup) IVolumeTracker {
+	return &jupiterVolumeTracker{
+		rpcUrl:       rpcUrl,
+		priceLookup:  priceLookup,
+		requestDelay: 100 * time.Millisecond,
+		logger:       logrus.WithField("module", "jupiter_volume_tracker").Logger,
+	}
+}
+
+func (j *jupiterVolumeTracker) Name() string {
+	return "jupiter"
+}
+
+func (j *jupiterVolumeTracker) SafeClose(closer io.Closer) {
+	if err := closer.Close(); err != nil {
+		j.logger.Error(err)
+	}
+}
+
+// FetchVolume returns the swaps which paid a referral fee between from and to. Fees are paid to the referral token
+// account of the fee mint, so the transactions of every token account of the referral account are scanned.
+func (j *jupiterVolumeTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	res := make([]models.Swap, 0)
+	//ignore invalid affiliate
+	if !j.isValidAffiliate(affiliate) {
+		return res, nil
+	}
+	accounts, err := j.getTokenAccounts(affiliate)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, account := range append([]string{affiliate}, accounts...) {
+		swaps, err := j.fetchAccountVolume(from, to, account, seen)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, swaps...)
+	}
+	return res, nil
+}
+
+// fetchAccountVolume returns the swaps of the transactions of an account between from and to, skipping the
+// transactions already seen on another account
+func (j *jupiterVolumeTracker) fetchAccountVolume(from, to int64, account string, seen map[string]bool) ([]models.Swap, error) {
+	res := make([]models.Swap, 0)
+	before := ""
+	for {
+		signatures, err := j.getSignatures(account, before)
+		if err != nil {
+			return nil, err
+		}
+		for _, sig := range signatures {
+			// signatures are returned newest first
+			if sig.BlockTime == nil || *sig.BlockTime >= to {
+				continue
+			}
+			if *sig.BlockTime < from {
+				return res, nil
+			}
+			if sig.Err != nil || seen[sig.Signature] {
+				continue
+			}
+			seen[sig.Signature] = true
+			swap, err := j.getSwap(sig.Signature, *sig.BlockTime)
+			if err != nil {
+				return nil, fmt.Errorf("error processing tx %s: %w", sig.Signature, err)
+			}
+			if swap != nil {
+				res = append(res, *swap)
+			}
+		}
+		if len(signatures) < signaturesPageSize {
+			return res, nil
+		}
+		before = signatures[len(signatures)-1].Signature
+	}
+}
+
+// getTokenAccounts returns the token accounts owned by the referral account, one per fee mint
+func (j *jupiterVolumeTracker) getTokenAccounts(owner string) ([]string, error) {
+	var result struct {
+		Value []struct {
+			Pubkey string `json:"pubkey"`
+		} `json:"value"`
+	}
+	if err := j.call("getTokenAccountsByOwner", []any{owner, map[string]any{"programId": tokenProgramID}, map[string]any{"encoding": "jsonParsed"}}, &result); err != nil {
+		return nil, err
+	}
+	accounts := make([]string, 0, len(result.Value))
+	for _, account := range result.Value {
+		accounts = append(accounts, account.Pubkey)
+	}
+	return accounts, nil
+}
+
+func (j *jupiterVolumeTracker) getSignatures(address, before string) ([]solanaSignature, error) {
+	opts := map[string]any{"limit": signaturesPageSize}
+	if before != "" {
+		opts["before"] = before
+	}
+	var signatures []solanaSignature
+	if err := j.call("getSignaturesForAddress", []any{address, opts}, &signatures); err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
+
+// getSwap returns the swap of a referral fee transaction, the swap size is the largest USD value
+// the signer sent or received in the transaction, so a missing price on one side doesn't drop the swap.
+// A swap without any price is recorded with the token the signer received and priced by a later job.
+func (j *jupiterVolumeTracker) getSwap(signature string, blockTime int64) (*models.Swap, error) {
+	var tx solanaTransaction
+	if err := j.call("getTransaction", []any{signature, map[string]any{
+		"encoding":                       "jsonParsed",
+		"maxSupportedTransactionVersion": 0,
+	}}, &tx); err != nil {
+		return nil, err
+	}
+	if tx.Meta == nil {
+		return nil, nil
+	}
+	signerIdx := -1
+	for i, key := range tx.Transaction.Message.AccountKeys {
+		if key.Signer {
+			signerIdx = i
+			break
+		}
+	}
+	if signerIdx < 0 {
+		return nil, nil
+	}
+	signer := tx.Transaction.Message.AccountKeys[signerIdx].Pubkey
+	swap := &models.Swap{
+		TxHash:      models.NormalizeTxHash(signature),
+		FromAddress: models.NormalizeSwapAddress(signer),
+		SwappedAt:   time.Unix(blockTime, 0).UTC(),
+		Source:      j.Name(),
+	}
+
+	// balance changes of the signer in base units by token, the native token has an empty address
+	changes := make(map[string]*big.Int)
+	change := func(mint string, delta *big.Int) {
+		if mint == wrappedSolMint {
+			mint = ""
+		}
+		if _, ok := changes[mint]; !ok {
+			changes[mint] = new(big.Int)
+		}
+		changes[mint].Add(changes[mint], delta)
+	}
+	for _, balance := range tx.Meta.PreTokenBalances {
+		if balance.Owner == signer {
+			change(balance.Mint, new(big.Int).Neg(balance.rawAmount()))
+		}
+	}
+	for _, balance := range tx.Meta.PostTokenBalances {
+		if balance.Owner == signer {
+			change(balance.Mint, balance.rawAmount())
+		}
+	}
+	if signerIdx < len(tx.Meta.PreBalances) && signerIdx < len(tx.Meta.PostBalances) {
+		// fee is paid by the signer, it's not part of the swap
+		lamports := tx.Meta.PostBalances[signerIdx] - tx.Meta.PreBalances[signerIdx] + tx.Meta.Fee
+		change("", big.NewInt(lamports))
+	}
+
+	mints := make([]string, 0, len(changes))
+	for mint := range changes {
+		mints = append(mints, mint)
+	}
+	sort.Strings(mints)
+	for _, mint := range mints {
+		amount := changes[mint]
+		if amount.Sign() == 0 {
+			continue
+		}
+		// the received token is kept to price the swap later, tokens are preferred over the native token
+		// whose balance also changes by rent and fees
+		if amount.Sign() > 0 && (swap.RawAmount == "" || swap.Token == "") {
+			swap.TokenChain = common.Solana.String()
+			swap.Token = mint
+			swap.RawAmount = amount.String()
+		}
+		price, decimals, err := j.priceLookup.GetTokenPrice(common.Solana, mint, swap.SwappedAt)
+		if err != nil {
+			j.logger.Warnf("failed to get price of %s for tx %s: %v", mint, signature, err)
+			continue
+		}
+		leg := models.Swap{RawAmount: new(big.Int).Abs(amount).String()}
+		swap.VolumeUSD = math.Max(swap.VolumeUSD, leg.TokenAmount(decimals)*price)
+	}
+	return swap, nil
+}
+
+func (j *jupiterVolumeTracker) call(method string, params []any, result any) error {
+	time.Sleep(j.requestDelay)
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := http.Post(j.rpcUrl, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("error making %s request: %w", method, err)
+	}
+	defer j.SafeClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error response from solana rpc: %s", resp.Status)
+	}
+	rpcResp := solanaRpcResponse{Result: result}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("error decoding %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("solana rpc %s error: %s", method, rpcResp.Error.Message)
+	}
+	return nil
+}
+
+// Affiliate should be a solana address (base58 encoded 32 bytes public key)
+func (j *jupiterVolumeTracker) isValidAffiliate(affiliate string) bool {
+	decoded, err := base58.Decode(affiliate)
+	return err == nil && len(decoded) == 32
+}
+
+type solanaRpcResponse struct {
+	Result any `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+type solanaSignature struct {
+	Signature string `json:"signature"`
+	BlockTime *int64 `json:"blockTime"`
+	Err       any    `json:"err"`
+}
+type solanaTokenBalance struct {
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount string `json:"amount"` // base units
+	} `json:"uiTokenAmount"`
+}
+
+func (b solanaTokenBalance) rawAmount() *big.Int {
+	amount, ok := new(big.Int).SetString(b.UITokenAmount.Amount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return amount
+}
+
+type solanaTransaction struct {
+	Meta *struct {
+		Fee               int64                `json:"fee"`
+		PreBalances       []int64              `json:"preBalances"`
+		PostBalances      []int64              `json:"postBalances"`
+		PreTokenBalances  []solanaTokenBalance `json:"preTokenBalances"`
+		PostTokenBalances []solanaTokenBalance `json:"postTokenBalances"`
+	} `json:"meta"`
+	Transaction struct {
+		Message struct {
+			AccountKeys []struct {
+				Pubkey string `json:"pubkey"`
+				Signer bool   `json:"signer"`
+			} `json:"accountKeys"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
@@ -0,0 +1,116 @@
+package volume
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	jupiterReferralAccount      = "45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp"
+	jupiterReferralTokenAccount = "9eWQnEUi7RsN1DpBhE6yHXRjXmM2oQGRTWeP3R1Ga9nL"
+)
+
+const jupiterTokenAccountsResponse = `{"jsonrpc":"2.0","id":1,"result":{"context":{"slot":1},"value":[
+	{"pubkey":"9eWQnEUi7RsN1DpBhE6yHXRjXmM2oQGRTWeP3R1Ga9nL","account":{}}
+]}}`
+
+const jupiterSignaturesResponse = `{"jsonrpc":"2.0","id":1,"result":[
+	{"signature":"newer","blockTime":1735200000,"err":null},
+	{"signature":"5sw4pTxHash","blockTime":1735120000,"err":null},
+	{"signature":"failed","blockTime":1735110000,"err":{"InstructionError":[2,{"Custom":1}]}},
+	{"signature":"older","blockTime":1735000000,"err":null}
+]}`
+
+const jupiterTransactionResponse = `{"jsonrpc":"2.0","id":1,"result":{
+	"meta":{
+		"fee":5000,
+		"preBalances":[2000005000,1000],
+		"postBalances":[1000000000,1000],
+		"preTokenBalances":[
+			{"mint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","owner":"CbSjseduYqKiavFxvdeRVH6DBv9Fz4rd59BLAFJz8J9Q","uiTokenAmount":{"amount":"10000000","decimals":6,"uiAmountString":"10"}},
+			{"mint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","owner":"45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp","uiTokenAmount":{"amount":"0","decimals":6,"uiAmountString":"0"}}
+		],
+		"postTokenBalances":[
+			{"mint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","owner":"CbSjseduYqKiavFxvdeRVH6DBv9Fz4rd59BLAFJz8J9Q","uiTokenAmount":{"amount":"209500000","decimals":6,"uiAmountString":"209.5"}},
+			{"mint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","owner":"45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp","uiTokenAmount":{"amount":"500000","decimals":6,"uiAmountString":"0.5"}}
+		]
+	},
+	"transaction":{"message":{"accountKeys":[
+		{"pubkey":"CbSjseduYqKiavFxvdeRVH6DBv9Fz4rd59BLAFJz8J9Q","signer":true},
+		{"pubkey":"45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp","signer":false}
+	]}}
+}}`
+
+func TestJupiterVolume(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusOK)
+		switch req.Method {
+		case "getTokenAccountsByOwner":
+			assert.Equal(t, jupiterReferralAccount, req.Params[0])
+			w.Write([]byte(jupiterTokenAccountsResponse))
+		case "getSignaturesForAddress":
+			// the fee is paid to the referral token account, the referral account sees the same tx only once
+			switch req.Params[0] {
+			case jupiterReferralTokenAccount:
+				w.Write([]byte(jupiterSignaturesResponse))
+			case jupiterReferralAccount:
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[{"signature":"5sw4pTxHash","blockTime":1735120000,"err":null}]}`))
+			default:
+				t.Errorf("unexpected account: %v", req.Params[0])
+			}
+		case "getTransaction":
+			assert.Equal(t, "5sw4pTxHash", req.Params[0])
+			w.Write([]byte(jupiterTransactionResponse))
+		default:
+			t.Errorf("unexpected method: %s", req.Method)
+		}
+	}))
+	defer mockServer.Close()
+	jupiter := jupiterVolumeTracker{
+		logger: logrus.WithField("module", "vol_jupiter").Logger,
+		rpcUrl: mockServer.URL,
+		priceLookup: mockPriceLookup{
+			prices: map[string]float64{
+				"": 100,
+				"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": 1,
+			},
+			decimals: map[string]int{
+				"": 9,
+				"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": 6,
+			},
+		},
+	}
+	res, err := jupiter.FetchVolume(1735100000, 1735150000, jupiterReferralAccount)
+	assert.NoErrorf(t, err, "Failed to get: %v", err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "5sw4pTxHash", res[0].TxHash)
+	assert.Equal(t, "CbSjseduYqKiavFxvdeRVH6DBv9Fz4rd59BLAFJz8J9Q", res[0].FromAddress)
+	// 1 SOL sold at 100 USD, 199.5 USDC received
+	assert.Equal(t, 199.5, res[0].VolumeUSD)
+	assert.Equal(t, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", res[0].Token)
+	assert.Equal(t, "199500000", res[0].RawAmount)
+
+	// without prices the swap keeps the received token so a later job prices it
+	jupiter.priceLookup = mockPriceLookup{prices: map[string]float64{}}
+	res, err = jupiter.FetchVolume(1735100000, 1735150000, jupiterReferralAccount)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, res[0].IsUnpriced())
+	assert.Equal(t, "Solana", res[0].TokenChain)
+	assert.Equal(t, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", res[0].Token)
+
+	// evm affiliates are ignored
+	res, err = jupiter.FetchVolume(1735100000, 1735150000, "0xa4a4f610e89488eb4ecc6c63069f241a54485269")
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
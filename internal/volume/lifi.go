@@ -6,8 +6,11 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
 )
 
 type lifiVolumeTracker struct {
@@ -22,13 +25,17 @@ func NewLifiVolumeTracker() IVolumeTracker {
 	}
 }
 
+func (l *lifiVolumeTracker) Name() string {
+	return "lifi"
+}
+
 func (l *lifiVolumeTracker) SafeClose(closer io.Closer) {
 	if err := closer.Close(); err != nil {
 		l.logger.Error(err)
 	}
 }
-func (l *lifiVolumeTracker) FetchVolume(from, to int64, affiliate string) (map[string]float64, error) {
-	res := make(map[string]float64)
+func (l *lifiVolumeTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	res := make([]models.Swap, 0)
 	if !l.isValidAffiliate(affiliate) {
 		return res, nil
 	}
@@ -53,7 +60,17 @@ func (l *lifiVolumeTracker) FetchVolume(from, to int64, affiliate string) (map[s
 			l.logger.WithField("status", transfer.Status).Info("transfer not done")
 			continue
 		}
-		res[transfer.ToAddress] += transfer.Receiving.AmountUSD
+		if transfer.Sending.TxHash == "" {
+			l.logger.WithField("from_address", transfer.FromAddress).Info("transfer without tx hash")
+			continue
+		}
+		res = append(res, models.Swap{
+			TxHash:      models.NormalizeTxHash(transfer.Sending.TxHash),
+			FromAddress: models.NormalizeSwapAddress(transfer.FromAddress),
+			VolumeUSD:   transfer.Receiving.AmountUSD,
+			SwappedAt:   time.Unix(transfer.Sending.Timestamp, 0).UTC(),
+			Source:      l.Name(),
+		})
 	}
 	return res, nil
 }
@@ -66,12 +83,16 @@ func (l *lifiVolumeTracker) isValidAffiliate(affiliate string) bool {
 }
 
 type lifiTransaction struct {
+	TxHash    string  `json:"txHash"`
 	AmountUSD float64 `json:"amountUSD,string"`
+	Timestamp int64   `json:"timestamp"`
 }
 type lifiTransfer struct {
-	Receiving lifiTransaction `json:"receiving"`
-	ToAddress string          `json:"toAddress"`
-	Status    string          `json:"status"`
+	Sending     lifiTransaction `json:"sending"`
+	Receiving   lifiTransaction `json:"receiving"`
+	FromAddress string          `json:"fromAddress"`
+	ToAddress   string          `json:"toAddress"`
+	Status      string          `json:"status"`
 }
 type lifiVolumeModel struct {
 	Transfers []lifiTransfer `json:"transfers"`
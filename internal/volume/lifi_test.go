@@ -23,10 +23,33 @@ func TestLifiVolume(t *testing.T) {
 		logger:  logrus.WithField("module", "vol_service").Logger,
 		baseUrl: mockServer.URL,
 	}
-	expect := map[string]float64{
-		"0x0b1a6fdd08b8e63d6b9476b971f03354823448ce": 173.7686,
-	}
 	res, err := li.FetchVolume(1730468849, 1735134449, "t")
 	assert.NoErrorf(t, err, "Failed to get: %v", err)
-	assert.Equal(t, expect["0x0b1a6fdd08b8e63d6b9476b971f03354823448ce"], res["0x0b1a6fdd08b8e63d6b9476b971f03354823448ce"])
+	volume := make(map[string]float64)
+	for _, swap := range res {
+		assert.Equal(t, "lifi", swap.Source)
+		volume[swap.FromAddress] += swap.VolumeUSD
+	}
+	assert.Len(t, res, 5)
+	assert.InDelta(t, 173.7686, volume["0x0b1a6fdd08b8e63d6b9476b971f03354823448ce"], 1e-9)
+	assert.Equal(t, "22d229f07f74b11a68e2fdc4dc32a3dc19aff7bea0dfe377bd2b718d83e49f45", res[0].TxHash)
+}
+
+func TestLifiVolumeSkipsTransfersWithoutTxHash(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"transfers":[
+			{"status":"DONE","fromAddress":"0xaa","sending":{"txHash":"","timestamp":1730468850},"receiving":{"amountUSD":"10"}},
+			{"status":"DONE","fromAddress":"0xbb","sending":{"txHash":"0x01","timestamp":1730468850},"receiving":{"amountUSD":"20"}}
+		]}`))
+	}))
+	defer mockServer.Close()
+	li := lifiVolumeTracker{
+		logger:  logrus.WithField("module", "vol_service").Logger,
+		baseUrl: mockServer.URL,
+	}
+	res, err := li.FetchVolume(1730468849, 1735134449, "t")
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "0xbb", res[0].FromAddress)
 }
@@ -6,93 +6,199 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/models"
 )
 
+// maxRateLimitRetries is the number of times a rate limited page is retried before the fetch fails
+const maxRateLimitRetries = 5
+
 type midgardTracker struct {
+	name           string
 	baseUrl        string
 	chainDecimal   int
 	clientIdHeader string
+	pageDelay      time.Duration // delay between pages, to avoid hitting rate limits
+	rateLimitDelay time.Duration // delay before retrying a rate limited page
 	logger         *logrus.Logger
 }
 
-func NewMidgardVolumeTracker(baseAddress string, chainDecimal int, clientIdHeader string) IVolumeTracker {
+func NewMidgardVolumeTracker(name, baseAddress string, chainDecimal int, clientIdHeader string) IVolumeTracker {
 	return &midgardTracker{
+		name:           name,
 		baseUrl:        fmt.Sprintf("%s/v2/actions", baseAddress),
 		chainDecimal:   chainDecimal,
 		clientIdHeader: clientIdHeader,
+		pageDelay:      time.Second,
+		rateLimitDelay: 30 * time.Second,
 		logger:         logrus.WithField("module", "midgard_tracker").Logger,
 	}
 }
 
+func (v *midgardTracker) Name() string {
+	return v.name
+}
+
 func (v *midgardTracker) SafeClose(closer io.Closer) {
 	if err := closer.Close(); err != nil {
 		v.logger.Error(err)
 	}
 }
 
-func (v *midgardTracker) FetchVolume(from, to int64, affiliate string) (map[string]float64, error) {
-	return v.processVolumeWithToken(from, to, affiliate, "")
+// FetchVolume walks all pages between from and to, pending swaps are skipped
+func (v *midgardTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	res := make([]models.Swap, 0)
+	cursor := ""
+	for {
+		page, err := v.FetchVolumePage(from, to, affiliate, cursor)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, page.Swaps...)
+		if page.NextCursor == "" {
+			return res, nil
+		}
+		cursor = page.NextCursor
+	}
 }
 
-func (v *midgardTracker) processVolumeWithToken(from, to int64, affiliate, nextPageToken string) (map[string]float64, error) {
-	time.Sleep(1 * time.Second) // to avoid hitting rate limits
-	url := fmt.Sprintf("%s?affiliate=%s&type=swap&timestamp=%d", v.baseUrl, affiliate, to)
-	if nextPageToken != "" {
-		url = fmt.Sprintf("%s?affiliate=%s&type=swap&nextPageToken=%s", v.baseUrl, affiliate, nextPageToken)
-	}
-	req, err := http.NewRequest("GET", url, nil)
+// FetchVolumePage fetches a single page of swaps, newest first. The returned cursor is empty
+// once the page reaches actions older than from or there are no more pages.
+func (v *midgardTracker) FetchVolumePage(from, to int64, affiliate, cursor string) (VolumePage, error) {
+	volRes, err := v.getActions(from, to, affiliate, cursor)
 	if err != nil {
-		return nil, fmt.Errorf("error making GET request: %w", err)
+		return VolumePage{}, err
 	}
-	if v.clientIdHeader != "" {
-		req.Header.Set("X-Client-ID", v.clientIdHeader)
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making GET request: %w", err)
+	page := VolumePage{Swaps: make([]models.Swap, 0, len(volRes.Actions))}
+	for _, action := range volRes.Actions {
+		// convert nanoseconds to seconds
+		date := action.Date / 1e9
+		if date < from {
+			return page, nil
+		}
+		if date >= to {
+			continue
+		}
+		switch action.Status {
+		case "success":
+		case "pending":
+			// streaming swap still in progress, it has to be fetched again once it's done
+			if page.PendingSince == 0 || date < page.PendingSince {
+				page.PendingSince = date
+			}
+			continue
+		default:
+			continue
+		}
+		swap, ok := v.toSwap(action, date)
+		if ok {
+			page.Swaps = append(page.Swaps, swap)
+		}
 	}
-	defer v.SafeClose(resp.Body)
+	page.NextCursor = volRes.Meta.NextPageToken
+	return page, nil
+}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		time.Sleep(30 * time.Second)
-		return v.processVolumeWithToken(from, to, affiliate, nextPageToken)
+// toSwap converts a successful swap action, the volume is the USD value of the swapped out coins.
+// Coins paid to the affiliate and coins refunded in the inbound asset (refunds and the unfilled
+// part of streaming swaps) are not part of the volume.
+func (v *midgardTracker) toSwap(action tcActions, date int64) (models.Swap, bool) {
+	if len(action.In) == 0 || action.In[0].TxID == "" {
+		return models.Swap{}, false
 	}
-	var volRes tcVolumeModel
-	if err := json.NewDecoder(resp.Body).Decode(&volRes); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	in := action.In[0]
+	inAsset := ""
+	if len(in.Coins) > 0 {
+		inAsset = in.Coins[0].Asset
 	}
-	res := make(map[string]float64)
-	for _, action := range volRes.Actions {
-		if action.Status != "success" {
+	var volumeUSD float64
+	outAsset := ""
+	for _, out := range action.Out {
+		if out.Affiliate != nil && *out.Affiliate {
 			continue
 		}
-		// convert nanoseconds to seconds
-		date := action.Date / 1e9
-		if date < from {
-			return res, nil
-		}
-		for _, out := range action.Out {
-			if out.Affiliate != nil && *out.Affiliate {
+		for _, outCoin := range out.OutCoins {
+			if inAsset != "" && strings.EqualFold(outCoin.Asset, inAsset) {
 				continue
 			}
-			for _, outCoin := range out.OutCoins {
-				res[out.Address] += float64(outCoin.Amount) * math.Pow10(-v.chainDecimal) * action.Metadata.Swap.OutPriceUSD
-			}
+			outAsset = outCoin.Asset
+			volumeUSD += float64(outCoin.Amount) * math.Pow10(-v.chainDecimal) * action.Metadata.Swap.OutPriceUSD
 		}
 	}
-	if volRes.Meta.NextPageToken != "" {
-		nextRes, err := v.processVolumeWithToken(from, to, affiliate, volRes.Meta.NextPageToken)
+	if volumeUSD == 0 {
+		// fully refunded
+		return models.Swap{}, false
+	}
+	return models.Swap{
+		TxHash:      models.NormalizeTxHash(in.TxID),
+		FromAddress: models.NormalizeSwapAddress(in.Address),
+		VolumeUSD:   volumeUSD,
+		SwappedAt:   time.Unix(date, 0).UTC(),
+		Source:      v.name,
+		Asset:       poolAsset(inAsset, outAsset),
+	}, true
+}
+
+// poolAsset returns the pool a swap went through, the non native (RUNE / CACAO) side of the swap
+func poolAsset(inAsset, outAsset string) string {
+	isNative := func(asset string) bool {
+		asset = strings.ToUpper(asset)
+		return asset == "THOR.RUNE" || asset == "MAYA.CACAO"
+	}
+	if outAsset != "" && !isNative(outAsset) {
+		return outAsset
+	}
+	return inAsset
+}
+
+func (v *midgardTracker) getActions(from, to int64, affiliate, nextPageToken string) (*tcVolumeModel, error) {
+	url := fmt.Sprintf("%s?affiliate=%s&type=swap&fromTimestamp=%d&timestamp=%d", v.baseUrl, affiliate, from, to)
+	if nextPageToken != "" {
+		url = fmt.Sprintf("%s?affiliate=%s&type=swap&nextPageToken=%s", v.baseUrl, affiliate, nextPageToken)
+	}
+	for attempt := 0; ; attempt++ {
+		time.Sleep(v.pageDelay)
+		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("error making GET request: %w", err)
 		}
-		for k, v := range nextRes {
-			res[k] += v
+		if v.clientIdHeader != "" {
+			req.Header.Set("X-Client-ID", v.clientIdHeader)
 		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making GET request: %w", err)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			v.SafeClose(resp.Body)
+			if attempt >= maxRateLimitRetries {
+				return nil, fmt.Errorf("rate limited by %s after %d retries", v.name, maxRateLimitRetries)
+			}
+			time.Sleep(v.rateLimitDelay)
+			continue
+		}
+		volRes, err := v.decodeActions(resp)
+		if err != nil {
+			return nil, err
+		}
+		return volRes, nil
+	}
+}
+
+func (v *midgardTracker) decodeActions(resp *http.Response) (*tcVolumeModel, error) {
+	defer v.SafeClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response from %s: %s", v.name, resp.Status)
+	}
+	var volRes tcVolumeModel
+	if err := json.NewDecoder(resp.Body).Decode(&volRes); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
-	return res, nil
+	return &volRes, nil
 }
 
 type tcVolumeModel struct {
@@ -114,8 +220,14 @@ type tcOut struct {
 	Affiliate *bool        `json:"affiliate"`
 	OutCoins  []tcOutCoins `json:"coins"`
 }
+type tcIn struct {
+	Address string       `json:"address"`
+	TxID    string       `json:"txID"`
+	Coins   []tcOutCoins `json:"coins"`
+}
 type tcActions struct {
 	Date     int64      `json:"date,string"`
+	In       []tcIn     `json:"in"`
 	Metadata tcMetadata `json:"metadata"`
 	Out      []tcOut    `json:"out"`
 	Status   string     `json:"status"`
@@ -36,10 +36,86 @@ func TestTCVolume(t *testing.T) {
 		logger:  logrus.WithField("module", "tc_vol_service").Logger,
 		baseUrl: mockServer.URL,
 	}
-	expect := map[string]float64{
-		"0x060c27cd6719477f233e403d74da9513886f0a1a": 324578205539.9229,
-	}
 	res, err := vr.FetchVolume(1739510000, 1739519656, "t")
 	assert.NoErrorf(t, err, "Failed to get: %v", err)
-	assert.Equal(t, expect, res)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "6a31b1adc6211047175a1a465985b7b2e6c28945c587f91305b73946491226cf", res[0].TxHash)
+	assert.Equal(t, "0x060c27cd6719477f233e403d74da9513886f0a1a", res[0].FromAddress)
+	// the unfilled part of the streaming swap is refunded in USDC and is not part of the volume
+	assert.InDelta(t, 4747206907745*0.06582207333142955, res[0].VolumeUSD, 1e-3)
+	assert.Equal(t, int64(1739518746), res[0].SwappedAt.Unix())
+	assert.Equal(t, "ETH.THOR-0XA5F2211B9B8170F694421F2046281775E8468044", res[0].Asset)
+}
+
+const tcRefundAndPendingResponse = `{
+	"actions": [
+		{
+			"date": "1739518900000000000",
+			"status": "pending",
+			"in": [{"address": "bc1qpending", "txID": "AA01", "coins": [{"amount": "100000000", "asset": "BTC.BTC"}]}],
+			"out": [],
+			"metadata": {"swap": {"outPriceUSD": "1"}}
+		},
+		{
+			"date": "1739518800000000000",
+			"status": "success",
+			"in": [{"address": "bc1qpartial", "txID": "BB020000000000000000000000000000000000000000000000000000000000CD", "coins": [{"amount": "100000000", "asset": "BTC.BTC"}]}],
+			"out": [
+				{"address": "bc1qpartial", "coins": [{"amount": "40000000", "asset": "BTC.BTC"}]},
+				{"address": "thor1partial", "coins": [{"amount": "600000000", "asset": "THOR.RUNE"}]}
+			],
+			"metadata": {"swap": {"outPriceUSD": "2"}}
+		},
+		{
+			"date": "1739518700000000000",
+			"status": "success",
+			"in": [{"address": "bc1qrefund", "txID": "CC03", "coins": [{"amount": "100000000", "asset": "BTC.BTC"}]}],
+			"out": [{"address": "bc1qrefund", "coins": [{"amount": "99000000", "asset": "BTC.BTC"}]}],
+			"metadata": {"swap": {"outPriceUSD": "90000"}}
+		}
+	],
+	"meta": {"nextPageToken": "next", "prevPageToken": ""}
+}`
+
+func TestTCVolumePage(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1739510000", r.URL.Query().Get("fromTimestamp"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(tcRefundAndPendingResponse))
+	}))
+	defer mockServer.Close()
+	vr := midgardTracker{
+		name:         "thorchain_midgard",
+		logger:       logrus.WithField("module", "tc_vol_service").Logger,
+		baseUrl:      mockServer.URL,
+		chainDecimal: 8,
+	}
+	page, err := vr.FetchVolumePage(1739510000, 1739519656, "t", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "next", page.NextCursor)
+	assert.Equal(t, int64(1739518900), page.PendingSince)
+	// the refunded part of the partial swap and the fully refunded swap are not counted
+	assert.Len(t, page.Swaps, 1)
+	assert.Equal(t, "bb020000000000000000000000000000000000000000000000000000000000cd", page.Swaps[0].TxHash)
+	// the swap is credited to the address which sent it and paid the affiliate fee, not to the recipient
+	assert.Equal(t, "bc1qpartial", page.Swaps[0].FromAddress)
+	assert.Equal(t, 12.0, page.Swaps[0].VolumeUSD)
+	assert.Equal(t, "BTC.BTC", page.Swaps[0].Asset)
+}
+
+func TestTCVolumeRateLimit(t *testing.T) {
+	calls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer mockServer.Close()
+	vr := midgardTracker{
+		name:    "thorchain_midgard",
+		logger:  logrus.WithField("module", "tc_vol_service").Logger,
+		baseUrl: mockServer.URL,
+	}
+	_, err := vr.FetchVolume(1739510000, 1739519656, "t")
+	assert.Error(t, err)
+	assert.Equal(t, maxRateLimitRetries+1, calls)
 }
@@ -2,108 +2,279 @@ package volume
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"math/big"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
 	"github.com/vultisig/airdrop-registry/internal/utils"
 )
 
+// erc20TransferTopic is keccak256("Transfer(address,address,uint256)")
+const erc20TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// etherscanPageSize is the max number of records etherscan returns for a single request
+const etherscanPageSize = 1000
+
+// PriceLookup resolves the USD price of a token at a given time from our own price records.
+// An empty contract address means the native token of the chain.
+type PriceLookup interface {
+	GetTokenPrice(chain common.Chain, contractAddress string, at time.Time) (price float64, decimals int, err error)
+}
+
 type oneInchVolumeTracker struct {
 	etherscanbaseUrl string
 	etherscanApiKey  string
-	ethplorerBaseUrl string
-	ethplorerApiKey  string
+	chains           []common.Chain
+	priceLookup      PriceLookup
+	requestDelay     time.Duration // etherscan free tier allows 5 requests per second
 	logger           *logrus.Logger
 }
 
-func NewOneInchVolumeTracker(etherscanApiKey, ethplorerApiKey string) IVolumeTracker {
+func NewOneInchVolumeTracker(etherscanApiKey string, priceLookup PriceLookup) IVolumeTracker {
 	return &oneInchVolumeTracker{
 		etherscanbaseUrl: "https://api.etherscan.io",
-		ethplorerBaseUrl: "https://api.ethplorer.io",
 		etherscanApiKey:  etherscanApiKey,
-		ethplorerApiKey:  ethplorerApiKey,
+		chains:           common.EVMChains,
+		priceLookup:      priceLookup,
+		requestDelay:     250 * time.Millisecond,
 		logger:           logrus.WithField("module", "oneInch_volume_tracker").Logger,
 	}
 }
+
+func (o *oneInchVolumeTracker) Name() string {
+	return "1inch"
+}
+
 func (o *oneInchVolumeTracker) SafeClose(closer io.Closer) {
 	if err := closer.Close(); err != nil {
 		o.logger.Error(err)
 	}
 }
 
-// TODO: add from/to filter to etherscan request
-func (o *oneInchVolumeTracker) FetchVolume(from, to int64, affiliate string) (map[string]float64, error) {
-	res := make(map[string]float64)
+// Chains returns the EVM chains the affiliate fees are tracked on
+func (o *oneInchVolumeTracker) Chains() []common.Chain {
+	chains := make([]common.Chain, 0, len(o.chains))
+	for _, chain := range o.chains {
+		if _, ok := common.GetEVMChainID(chain); ok {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// FetchVolume returns the swaps which paid a fee to the affiliate on every EVM chain between from and to
+func (o *oneInchVolumeTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	res := make([]models.Swap, 0)
+	for _, chain := range o.Chains() {
+		swaps, err := o.FetchChainVolume(chain, from, to, affiliate)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, swaps...)
+	}
+	return res, nil
+}
+
+// FetchChainVolume returns the swaps which paid a fee to the affiliate on a single EVM chain between from and to
+func (o *oneInchVolumeTracker) FetchChainVolume(chain common.Chain, from, to int64, affiliate string) ([]models.Swap, error) {
 	//ignore invalid affiliate
 	if !o.isValidAffiliate(affiliate) {
-		return res, nil
+		return make([]models.Swap, 0), nil
 	}
-	// #TODO check api for from & to parameters
-	url := fmt.Sprintf("%s/v2/api?chainid=1&module=account&action=txlistinternal&address=%s&apikey=%s", o.etherscanbaseUrl, affiliate, o.etherscanApiKey)
-	resp, err := http.Get(url)
+	chainID, ok := common.GetEVMChainID(chain)
+	if !ok {
+		return nil, fmt.Errorf("chain %s is not an EVM chain", chain)
+	}
+	swaps, err := o.fetchChainVolume(chain, chainID, from, to, affiliate)
 	if err != nil {
-		return nil, fmt.Errorf("error making GET request: %w", err)
+		return nil, fmt.Errorf("error fetching %s volume: %w", chain, err)
 	}
-	defer o.SafeClose(resp.Body)
-	var etherScanResponse, etherScanFilteredResponse etherScanResponse
-	if err := json.NewDecoder(resp.Body).Decode(&etherScanResponse); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	return swaps, nil
+}
+
+func (o *oneInchVolumeTracker) fetchChainVolume(chain common.Chain, chainID, from, to int64, affiliate string) ([]models.Swap, error) {
+	startBlock, err := o.getBlockByTime(chainID, from, "after")
+	if err != nil {
+		return nil, err
+	}
+	endBlock, err := o.getBlockByTime(chainID, to, "before")
+	if err != nil {
+		return nil, err
 	}
-	if etherScanResponse.Status != "1" {
-		return nil, fmt.Errorf("error in etherscan response: %s", etherScanResponse.Message)
+	if endBlock < startBlock {
+		return nil, nil
 	}
-	for _, item := range etherScanResponse.Result {
-		if from <= item.TimeStamp && item.TimeStamp < to {
-			etherScanFilteredResponse.Result = append(etherScanFilteredResponse.Result, item)
+	// fees are paid to the affiliate either in native token (internal tx) or in erc20 (token transfer)
+	feeTxs := make(map[string]int64)
+	for _, action := range []string{"txlistinternal", "tokentx"} {
+		if err := o.collectFeeTxs(chainID, action, affiliate, startBlock, endBlock, from, to, feeTxs); err != nil {
+			return nil, err
 		}
 	}
-	txHashes := make([]string, 0)
-	for _, item := range etherScanFilteredResponse.Result {
-		if item.IsError != 0 {
-			continue
+	res := make([]models.Swap, 0, len(feeTxs))
+	for hash, timestamp := range feeTxs {
+		swap, err := o.getSwap(chain, chainID, hash, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("error processing tx %s: %w", hash, err)
 		}
-		txHashes = append(txHashes, item.Hash)
+		res = append(res, swap)
 	}
+	return res, nil
+}
 
-	for _, tx := range txHashes {
-		url = fmt.Sprintf("%s/getTxInfo/%s?apiKey=%s", o.ethplorerBaseUrl, tx, o.ethplorerApiKey)
-		resp, err := http.Get(url)
-		if err != nil {
-			return nil, fmt.Errorf("error making GET request: %w", err)
+// collectFeeTxs pages through the affiliate transactions by block range, etherscan caps the result window
+// so the next page starts from the last returned block and duplicated hashes are skipped
+func (o *oneInchVolumeTracker) collectFeeTxs(chainID int64, action, affiliate string, startBlock, endBlock, from, to int64, feeTxs map[string]int64) error {
+	for startBlock <= endBlock {
+		url := fmt.Sprintf("%s/v2/api?chainid=%d&module=account&action=%s&address=%s&startblock=%d&endblock=%d&page=1&offset=%d&sort=asc&apikey=%s",
+			o.etherscanbaseUrl, chainID, action, affiliate, startBlock, endBlock, etherscanPageSize, o.etherscanApiKey)
+		var resp etherScanResponse
+		if err := o.get(url, &resp); err != nil {
+			return err
 		}
-		defer o.SafeClose(resp.Body)
-		var ethplorerTx ethplorerVolumeModel
-		if err := json.NewDecoder(resp.Body).Decode(&ethplorerTx); err != nil {
-			return nil, fmt.Errorf("error decoding response: %w", err)
+		if resp.Status != "1" {
+			if resp.Message == "No transactions found" {
+				return nil
+			}
+			return fmt.Errorf("error in etherscan response: %s", resp.Message)
+		}
+		var lastBlock int64
+		for _, item := range resp.Result {
+			lastBlock = item.BlockNumber
+			if item.IsError != 0 || !strings.EqualFold(item.To, affiliate) {
+				continue
+			}
+			if item.Hash == "" {
+				o.logger.Infof("fee tx without hash in block %d on chain %d", item.BlockNumber, chainID)
+				continue
+			}
+			if from <= item.TimeStamp && item.TimeStamp < to {
+				feeTxs[strings.ToLower(item.Hash)] = item.TimeStamp
+			}
 		}
-		if len(ethplorerTx.Operations) == 0 {
+		if len(resp.Result) < etherscanPageSize || lastBlock <= startBlock {
+			return nil
+		}
+		startBlock = lastBlock
+	}
+	return nil
+}
+
+// getSwap builds the swap of a fee paying transaction, the swap is credited to the tx sender
+// and its volume is the USD value of the token the sender received
+func (o *oneInchVolumeTracker) getSwap(chain common.Chain, chainID int64, hash string, timestamp int64) (models.Swap, error) {
+	var tx etherscanProxyResponse[etherscanTx]
+	if err := o.get(fmt.Sprintf("%s/v2/api?chainid=%d&module=proxy&action=eth_getTransactionByHash&txhash=%s&apikey=%s", o.etherscanbaseUrl, chainID, hash, o.etherscanApiKey), &tx); err != nil {
+		return models.Swap{}, err
+	}
+	if tx.Result.From == "" {
+		return models.Swap{}, errEmptyProxyResult
+	}
+	swapper := strings.ToLower(tx.Result.From)
+	swap := models.Swap{
+		TxHash:      models.NormalizeTxHash(hash),
+		FromAddress: models.NormalizeSwapAddress(swapper),
+		SwappedAt:   time.Unix(timestamp, 0).UTC(),
+		Source:      o.Name(),
+	}
+	contractAddress, amount, err := o.getReceivedToken(chainID, hash, swapper)
+	if err != nil {
+		return models.Swap{}, err
+	}
+	if amount == nil {
+		o.logger.Infof("no received token found for tx %s on %s", hash, chain)
+		return swap, nil
+	}
+	swap.TokenChain = chain.String()
+	swap.Token = contractAddress
+	swap.RawAmount = amount.String()
+	price, decimals, err := o.priceLookup.GetTokenPrice(chain, contractAddress, swap.SwappedAt)
+	if err != nil {
+		// the swap is recorded with its received token and priced by a later job
+		o.logger.Warnf("failed to get price of %s on %s for tx %s: %v", contractAddress, chain, hash, err)
+		return swap, nil
+	}
+	swap.VolumeUSD = swap.TokenAmount(decimals) * price
+	return swap, nil
+}
+
+// getReceivedToken returns the contract address (empty for native token) and the amount of the last
+// token transferred to the swapper, native token is looked up in the internal transactions of the tx.
+// The amount is nil if the swapper received nothing.
+func (o *oneInchVolumeTracker) getReceivedToken(chainID int64, hash, swapper string) (string, *big.Int, error) {
+	var receipt etherscanProxyResponse[etherscanReceipt]
+	if err := o.get(fmt.Sprintf("%s/v2/api?chainid=%d&module=proxy&action=eth_getTransactionReceipt&txhash=%s&apikey=%s", o.etherscanbaseUrl, chainID, hash, o.etherscanApiKey), &receipt); err != nil {
+		return "", nil, err
+	}
+	for i := len(receipt.Result.Logs) - 1; i >= 0; i-- {
+		log := receipt.Result.Logs[i]
+		if len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], erc20TransferTopic) {
 			continue
 		}
-		lastOperation := ethplorerTx.Operations[len(ethplorerTx.Operations)-1]
-		price, ok := lastOperation.TokenInfo.Price.(map[string]any)
-		if ok {
-			var priceData ethplorerPrice
-			err = mapToStruct(price, &priceData)
-			if err != nil {
-				return nil, fmt.Errorf("error converting map to struct: %w", err)
+		if topicToAddress(log.Topics[2]) == swapper && topicToAddress(log.Topics[1]) != swapper {
+			amount, ok := new(big.Int).SetString(strings.TrimPrefix(log.Data, "0x"), 16)
+			if !ok {
+				return "", nil, fmt.Errorf("invalid transfer amount: %s", log.Data)
 			}
-			bigIntValue, ok := new(big.Int).SetString(lastOperation.Value, 10)
+			return strings.ToLower(log.Address), amount, nil
+		}
+	}
+	var internalTxs etherScanResponse
+	if err := o.get(fmt.Sprintf("%s/v2/api?chainid=%d&module=account&action=txlistinternal&txhash=%s&apikey=%s", o.etherscanbaseUrl, chainID, hash, o.etherscanApiKey), &internalTxs); err != nil {
+		return "", nil, err
+	}
+	for _, item := range internalTxs.Result {
+		if item.IsError == 0 && strings.EqualFold(item.To, swapper) {
+			value, ok := new(big.Int).SetString(item.Value, 10)
 			if !ok {
-				return nil, fmt.Errorf("error converting string to big int: %s", lastOperation.Value)
+				return "", nil, fmt.Errorf("invalid internal tx value: %s", item.Value)
 			}
-			floatValue := new(big.Float).SetInt(bigIntValue)
-			scale := new(big.Float).SetFloat64(math.Pow10(lastOperation.TokenInfo.Decimals))
-			amount, _ := new(big.Float).Quo(floatValue, scale).Float64()
-			res[lastOperation.To] += amount * priceData.Rate
-		} else {
-			res[lastOperation.To] = 0
+			return "", value, nil
 		}
 	}
-	return res, nil
+	return "", nil, nil
+}
+
+// getBlockByTime returns the closest block ("before" or "after") to the given unix timestamp
+func (o *oneInchVolumeTracker) getBlockByTime(chainID, timestamp int64, closest string) (int64, error) {
+	url := fmt.Sprintf("%s/v2/api?chainid=%d&module=block&action=getblocknobytime&timestamp=%d&closest=%s&apikey=%s", o.etherscanbaseUrl, chainID, timestamp, closest, o.etherscanApiKey)
+	var resp etherscanBlockResponse
+	if err := o.get(url, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Status != "1" {
+		return 0, fmt.Errorf("error in etherscan block response: %s", resp.Message)
+	}
+	block, err := strconv.ParseInt(resp.Result, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing block number %s: %w", resp.Result, err)
+	}
+	return block, nil
+}
+
+func (o *oneInchVolumeTracker) get(url string, result any) error {
+	time.Sleep(o.requestDelay)
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("error making GET request: %w", err)
+	}
+	defer o.SafeClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error response from etherscan: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
 }
 
 // Affilaite should be eth address
@@ -111,36 +282,65 @@ func (o *oneInchVolumeTracker) isValidAffiliate(affiliate string) bool {
 	return utils.IsETHAddress(affiliate)
 }
 
-func mapToStruct(m map[string]any, result any) error {
-	jsonStr, err := json.Marshal(m)
-	if err != nil {
-		return err
+// topicToAddress converts a 32 bytes log topic to a lower case address
+func topicToAddress(topic string) string {
+	topic = strings.ToLower(strings.TrimPrefix(topic, "0x"))
+	if len(topic) < 40 {
+		return ""
 	}
-	return json.Unmarshal(jsonStr, result)
+	return "0x" + topic[len(topic)-40:]
 }
 
+var errEmptyProxyResult = errors.New("empty etherscan proxy result")
+
 type etherScanResponse struct {
 	Status  string            `json:"status"`
 	Message string            `json:"message"`
 	Result  []etherscanResult `json:"result"`
 }
+
+// UnmarshalJSON handles etherscan returning an error string as result instead of a list
+func (e *etherScanResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Status, e.Message = raw.Status, raw.Message
+	if len(raw.Result) > 0 && raw.Result[0] == '[' {
+		return json.Unmarshal(raw.Result, &e.Result)
+	}
+	return nil
+}
+
 type etherscanResult struct {
-	Hash      string `json:"hash"`
-	TimeStamp int64  `json:"timeStamp,string"`
-	IsError   int    `json:"isError,string"`
+	BlockNumber int64  `json:"blockNumber,string"`
+	Hash        string `json:"hash"`
+	TimeStamp   int64  `json:"timeStamp,string"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	IsError     int    `json:"isError,string"`
+}
+type etherscanBlockResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
 }
-type ethplorerPrice struct {
-	Rate float64 `json:"rate"`
+type etherscanProxyResponse[T any] struct {
+	Result T `json:"result"`
 }
-type ethplorerTokenInfo struct {
-	Decimals int `json:"decimals,string"`
-	Price    any `json:"price"`
+type etherscanTx struct {
+	From string `json:"from"`
 }
-type ethplorerOperation struct {
-	Value     string             `json:"value"`
-	To        string             `json:"to"`
-	TokenInfo ethplorerTokenInfo `json:"tokenInfo"`
+type etherscanLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
 }
-type ethplorerVolumeModel struct {
-	Operations []ethplorerOperation `json:"operations"`
+type etherscanReceipt struct {
+	Logs []etherscanLog `json:"logs"`
 }
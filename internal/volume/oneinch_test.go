@@ -2,40 +2,98 @@ package volume
 
 import (
 	_ "embed"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
 )
 
 //go:embed etherscan_test_response.json
 var etherscanResponse string
 
-//go:embed  etherplorer_test_response.json
-var ethplorerResponse string
+type mockPriceLookup struct {
+	prices   map[string]float64
+	decimals map[string]int // 18 if not set
+}
+
+func (m mockPriceLookup) GetTokenPrice(chain common.Chain, contractAddress string, at time.Time) (float64, int, error) {
+	price, ok := m.prices[contractAddress]
+	if !ok {
+		return 0, 0, fmt.Errorf("no price recorded for %s", contractAddress)
+	}
+	decimals, ok := m.decimals[contractAddress]
+	if !ok {
+		decimals = 18
+	}
+	return price, decimals, nil
+}
 
 func TestOneInchVolume(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Query().Get("address") == "0xa4a4f610e89488eb4ecc6c63069f241a54485269" {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(etherscanResponse))
-		} else {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(ethplorerResponse))
+		query := r.URL.Query()
+		assert.Equal(t, "8453", query.Get("chainid"))
+		w.WriteHeader(http.StatusOK)
+		switch query.Get("action") {
+		case "getblocknobytime":
+			if query.Get("closest") == "after" {
+				w.Write([]byte(`{"status":"1","message":"OK","result":"19883900"}`))
+			} else {
+				w.Write([]byte(`{"status":"1","message":"OK","result":"19883999"}`))
+			}
+		case "txlistinternal":
+			if query.Get("address") == "0xa4a4f610e89488eb4ecc6c63069f241a54485269" {
+				assert.Equal(t, "19883900", query.Get("startblock"))
+				assert.Equal(t, "19883999", query.Get("endblock"))
+				w.Write([]byte(etherscanResponse))
+			} else {
+				w.Write([]byte(`{"status":"0","message":"No transactions found","result":[]}`))
+			}
+		case "tokentx":
+			w.Write([]byte(`{"status":"0","message":"No transactions found","result":[]}`))
+		case "eth_getTransactionByHash":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"from":"0x121A38277E0BA795EDF8CB6BE7935A9773E1AC25"}}`))
+		case "eth_getTransactionReceipt":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"logs":[{
+				"address":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+				"topics":["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+					"0x000000000000000000000000111111125421ca6dc452d289314280a0f8842a65",
+					"0x000000000000000000000000121a38277e0ba795edf8cb6be7935a9773e1ac25"],
+				"data":"0x0000000000000000000000000000000000000000000000001bc16d674ec80000"}]}}`))
+		default:
+			t.Errorf("unexpected action: %s", query.Get("action"))
 		}
 	}))
 	defer mockServer.Close()
 	oneInch := oneInchVolumeTracker{
 		logger:           logrus.WithField("module", "vol_oneInch").Logger,
 		etherscanbaseUrl: mockServer.URL,
-		ethplorerBaseUrl: mockServer.URL,
-	}
-	expect := map[string]float64{
-		"0x121a38277e0ba795edf8cb6be7935a9773e1ac25": 11.696993778690723,
+		chains:           []common.Chain{common.Base},
+		priceLookup: mockPriceLookup{prices: map[string]float64{
+			"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": 1.5,
+		}},
 	}
 	res, err := oneInch.FetchVolume(1715879039, 1715889039, "0xa4a4f610e89488eb4ecc6c63069f241a54485269")
 	assert.NoErrorf(t, err, "Failed to get: %v", err)
-	assert.Equal(t, expect["0x121a38277e0ba795edf8cb6be7935a9773e1ac25"], res["0x121a38277e0ba795edf8cb6be7935a9773e1ac25"])
+	assert.Len(t, res, 1)
+	assert.Equal(t, "7a541e32d9ce12a7413d716b2870b5d2fe2ac31a91d67ee1f765d0b24e27f774", res[0].TxHash)
+	assert.Equal(t, "0x121a38277e0ba795edf8cb6be7935a9773e1ac25", res[0].FromAddress)
+	assert.Equal(t, 3.0, res[0].VolumeUSD)
+	assert.Equal(t, "Base", res[0].TokenChain)
+	assert.Equal(t, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", res[0].Token)
+	assert.Equal(t, "2000000000000000000", res[0].RawAmount)
+
+	// a swap without a price keeps its received token so a later job prices it
+	oneInch.priceLookup = mockPriceLookup{prices: map[string]float64{}}
+	res, err = oneInch.FetchVolume(1715879039, 1715889039, "0xa4a4f610e89488eb4ecc6c63069f241a54485269")
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Zero(t, res[0].VolumeUSD)
+	assert.True(t, res[0].IsUnpriced())
+	assert.Equal(t, "2000000000000000000", res[0].RawAmount)
 }
@@ -1,53 +1,134 @@
 package volume
 
 import (
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/vultisig/airdrop-registry/config"
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
 )
 
 type IVolumeTracker interface {
+	Name() string
 	SafeClose(closer io.Closer)
-	FetchVolume(from, to int64, affiliate string) (map[string]float64, error)
+	FetchVolume(from, to int64, affiliate string) ([]models.Swap, error)
+}
+
+// IPaginatedTracker is a tracker which can fetch a window page by page, so an interrupted
+// fetch can resume from the cursor of the last stored page
+type IPaginatedTracker interface {
+	IVolumeTracker
+	FetchVolumePage(from, to int64, affiliate, cursor string) (VolumePage, error)
+}
+
+// IMultiChainTracker is a tracker which fetches every chain separately, each chain is checkpointed on its own
+// so a failing chain doesn't hold back the others
+type IMultiChainTracker interface {
+	IVolumeTracker
+	Chains() []common.Chain
+	FetchChainVolume(chain common.Chain, from, to int64, affiliate string) ([]models.Swap, error)
+}
+
+// VolumePage is a page of swaps returned by a paginated tracker
+type VolumePage struct {
+	Swaps        []models.Swap
+	NextCursor   string // empty when the window is fully fetched
+	PendingSince int64  // time of the oldest swap still in progress, 0 if none
+}
+
+// maxFetchAttempts is the number of times a source is queried before it is reported as failed
+const maxFetchAttempts = 3
+
+// VolumeSource is a tracker queried for one affiliate, each source is checkpointed separately
+type VolumeSource struct {
+	Tracker   IVolumeTracker
+	Affiliate string
+	Chain     common.Chain // chain of a multi chain tracker, unused by other trackers
+}
+
+// Name returns the name the source is checkpointed under, the tracker name suffixed with the chain
+// for multi chain trackers
+func (s VolumeSource) Name() string {
+	if _, ok := s.Tracker.(IMultiChainTracker); ok {
+		return fmt.Sprintf("%s_%s", s.Tracker.Name(), s.Chain)
+	}
+	return s.Tracker.Name()
 }
 
 type VolumeResolver struct {
-	trackers  []IVolumeTracker
-	volume    map[string]float64
-	affiliate []string
+	trackers   []IVolumeTracker
+	affiliate  []string
+	retryDelay time.Duration
 }
 
-func NewVolumeResolver(cfg *config.Config) (*VolumeResolver, error) {
+func NewVolumeResolver(cfg *config.Config, priceLookup PriceLookup) (*VolumeResolver, error) {
 	pr := &VolumeResolver{
 		affiliate: cfg.VolumeTrackingAPI.AffiliateAddress,
 		trackers: []IVolumeTracker{
-			NewMidgardVolumeTracker(cfg.VolumeTrackingAPI.TCMidgardBaseURL, 8, cfg.VolumeTrackingAPI.TCMidgardXClientID),
-			NewMidgardVolumeTracker(cfg.VolumeTrackingAPI.MayaMidgardBaseURL, 10, ""),
+			NewMidgardVolumeTracker("thorchain_midgard", cfg.VolumeTrackingAPI.TCMidgardBaseURL, 8, cfg.VolumeTrackingAPI.TCMidgardXClientID),
+			NewMidgardVolumeTracker("maya_midgard", cfg.VolumeTrackingAPI.MayaMidgardBaseURL, 10, ""),
 			NewLifiVolumeTracker(),
-			NewOneInchVolumeTracker(cfg.VolumeTrackingAPI.EtherscanAPIKey, cfg.VolumeTrackingAPI.EthplorerAPIKey),
+			NewOneInchVolumeTracker(cfg.VolumeTrackingAPI.EtherscanAPIKey, priceLookup),
+			NewJupiterVolumeTracker(cfg.VolumeTrackingAPI.SolanaRPCURL, priceLookup),
 		},
-		volume: make(map[string]float64),
+		retryDelay: 10 * time.Second,
 	}
 	return pr, nil
 }
 
-func (v *VolumeResolver) LoadVolume(from, to int64) error {
-	res := make(map[string]float64)
+// Sources returns every (tracker, affiliate) pair volume is fetched for
+func (v *VolumeResolver) Sources() []VolumeSource {
+	sources := make([]VolumeSource, 0, len(v.trackers)*len(v.affiliate))
 	for _, aff := range v.affiliate {
 		for _, tracker := range v.trackers {
-			vol, err := tracker.FetchVolume(from, to, aff)
-			if err != nil {
-				return err
-			}
-			for k, v := range vol {
-				res[k] += v
+			if multiChain, ok := tracker.(IMultiChainTracker); ok {
+				for _, chain := range multiChain.Chains() {
+					sources = append(sources, VolumeSource{Tracker: tracker, Affiliate: aff, Chain: chain})
+				}
+				continue
 			}
+			sources = append(sources, VolumeSource{Tracker: tracker, Affiliate: aff})
+		}
+	}
+	return sources
+}
+
+// FetchSwapsPage fetches a single page of a paginated source, retrying failed attempts
+func (v *VolumeResolver) FetchSwapsPage(tracker IPaginatedTracker, affiliate string, from, to int64, cursor string) (VolumePage, error) {
+	var lastErr error
+	for attempt := 1; attempt <= maxFetchAttempts; attempt++ {
+		page, err := tracker.FetchVolumePage(from, to, affiliate, cursor)
+		if err == nil {
+			return page, nil
+		}
+		lastErr = err
+		if attempt < maxFetchAttempts {
+			time.Sleep(time.Duration(attempt) * v.retryDelay)
 		}
 	}
-	v.volume = res
-	return nil
+	return VolumePage{}, fmt.Errorf("failed to fetch %s volume page for %s after %d attempts: %w", tracker.Name(), affiliate, maxFetchAttempts, lastErr)
 }
 
-func (v *VolumeResolver) GetVolume(address string) float64 {
-	return v.volume[address]
+// FetchSwaps fetches the swaps of a single source between from and to, retrying failed attempts
+func (v *VolumeResolver) FetchSwaps(source VolumeSource, from, to int64) ([]models.Swap, error) {
+	var lastErr error
+	fetch := source.Tracker.FetchVolume
+	if multiChain, ok := source.Tracker.(IMultiChainTracker); ok {
+		fetch = func(from, to int64, affiliate string) ([]models.Swap, error) {
+			return multiChain.FetchChainVolume(source.Chain, from, to, affiliate)
+		}
+	}
+	for attempt := 1; attempt <= maxFetchAttempts; attempt++ {
+		swaps, err := fetch(from, to, source.Affiliate)
+		if err == nil {
+			return swaps, nil
+		}
+		lastErr = err
+		if attempt < maxFetchAttempts {
+			time.Sleep(time.Duration(attempt) * v.retryDelay)
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch %s volume for %s after %d attempts: %w", source.Name(), source.Affiliate, maxFetchAttempts, lastErr)
 }
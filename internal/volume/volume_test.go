@@ -0,0 +1,92 @@
+package volume
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vultisig/airdrop-registry/internal/common"
+	"github.com/vultisig/airdrop-registry/internal/models"
+)
+
+type flakyTracker struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyTracker) Name() string { return "flaky" }
+
+func (f *flakyTracker) SafeClose(closer io.Closer) {}
+
+func (f *flakyTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("source unavailable")
+	}
+	return []models.Swap{{TxHash: "abc", FromAddress: affiliate, VolumeUSD: 10}}, nil
+}
+
+func TestFetchSwapsRetry(t *testing.T) {
+	recovering := &flakyTracker{failures: maxFetchAttempts - 1}
+	failing := &flakyTracker{failures: maxFetchAttempts}
+	vr := &VolumeResolver{
+		trackers:  []IVolumeTracker{recovering, failing},
+		affiliate: []string{"va"},
+	}
+	sources := vr.Sources()
+	assert.Len(t, sources, 2)
+
+	swaps, err := vr.FetchSwaps(sources[0], 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, swaps, 1)
+	assert.Equal(t, maxFetchAttempts, recovering.calls)
+
+	_, err = vr.FetchSwaps(sources[1], 0, 1)
+	assert.Error(t, err)
+	assert.Equal(t, maxFetchAttempts, failing.calls)
+}
+
+type multiChainTracker struct {
+	failing common.Chain
+}
+
+func (m *multiChainTracker) Name() string { return "multi" }
+
+func (m *multiChainTracker) SafeClose(closer io.Closer) {}
+
+func (m *multiChainTracker) FetchVolume(from, to int64, affiliate string) ([]models.Swap, error) {
+	return nil, errors.New("multi chain trackers are fetched per chain")
+}
+
+func (m *multiChainTracker) Chains() []common.Chain {
+	return []common.Chain{common.Ethereum, common.Base}
+}
+
+func (m *multiChainTracker) FetchChainVolume(chain common.Chain, from, to int64, affiliate string) ([]models.Swap, error) {
+	if chain == m.failing {
+		return nil, errors.New("chain unavailable")
+	}
+	return []models.Swap{{TxHash: chain.String(), FromAddress: affiliate, VolumeUSD: 10}}, nil
+}
+
+func TestFetchSwapsPerChain(t *testing.T) {
+	vr := &VolumeResolver{
+		trackers:  []IVolumeTracker{&multiChainTracker{failing: common.Base}, &flakyTracker{}},
+		affiliate: []string{"va"},
+	}
+	sources := vr.Sources()
+	assert.Len(t, sources, 3)
+	assert.Equal(t, "multi_Ethereum", sources[0].Name())
+	assert.Equal(t, "multi_Base", sources[1].Name())
+	assert.Equal(t, "flaky", sources[2].Name())
+
+	swaps, err := vr.FetchSwaps(sources[0], 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, swaps, 1)
+	assert.Equal(t, "Ethereum", swaps[0].TxHash)
+
+	_, err = vr.FetchSwaps(sources[1], 0, 1)
+	assert.Error(t, err)
+}